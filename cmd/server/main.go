@@ -57,41 +57,82 @@ func main() {
 	userRepo := postgres.NewUserRepository(db)
 	adCopyRepo := postgres.NewAdCopyRepository(db)
 	replyLogRepo := postgres.NewReplyLogRepository(db)
+	searchQueryRepo := postgres.NewSearchQueryRepository(db)
+	userGroupRepo := postgres.NewUserGroupRepository(db)
+	campaignRepo := postgres.NewCampaignRepository(db)
+	trackedLinkRepo := postgres.NewTrackedLinkRepository(db)
 
 	// 初始化外部客户端
 	twitterClient := twitter.NewClient(&cfg.Twitter)
+	streamClient := twitter.NewStreamClient(&cfg.Twitter, cfg.Stream.Endpoint)
 	llmClient := llm.NewClient(&cfg.LLM)
 
 	// 初始化服务
-	followerService := service.NewFollowerService(userRepo, twitterClient, logger)
+	followerService := service.NewFollowerService(userRepo, twitterClient, &cfg.Workflow.Lists, logger)
+	userActivity := service.NewUserActivityService(userRepo, &cfg.Workflow.Activity, logger)
 	tweetService := service.NewTweetService(twitterClient, logger)
+	eligibilityChecker := service.NewEligibilityChecker(&cfg.Workflow.Eligibility)
+	authorPolicy := service.NewAuthorPolicyChecker(replyLogRepo, &cfg.Workflow.AuthorPolicy)
+	groupTargeting := service.NewGroupTargeting(replyLogRepo)
+	campaignTargeting := service.NewCampaignTargeting(campaignRepo, replyLogRepo, cfg.LLM.CostPerCall, logger)
 	hackathonDetector := service.NewHackathonDetector(llmClient, logger)
-	adReplyService := service.NewAdReplyService(adCopyRepo, twitterClient, logger)
+	safetyChecker := service.NewSafetyChecker(llmClient, &cfg.Safety, logger)
+	adReplyService := service.NewAdReplyService(adCopyRepo, replyLogRepo, twitterClient, &cfg.Workflow.AdSelection, &cfg.Workflow.Variation, logger)
+	linkTracker := service.NewLinkTracker(trackedLinkRepo, cfg.Workflow.LinkTracking, logger)
+	adMetrics := service.NewAdMetricsService(adCopyRepo, replyLogRepo, twitterClient, trackedLinkRepo, &cfg.Workflow.AdSelection, logger)
 	workflowService := service.NewWorkflowService(
 		followerService,
+		userActivity,
 		tweetService,
+		eligibilityChecker,
+		authorPolicy,
+		groupTargeting,
+		campaignTargeting,
 		hackathonDetector,
+		safetyChecker,
 		adReplyService,
+		linkTracker,
 		replyLogRepo,
+		searchQueryRepo,
 		&cfg.Workflow,
 		logger,
 	)
 
+	approvalService := service.NewApprovalService(replyLogRepo, adReplyService, linkTracker, &cfg.Workflow, logger)
+	retryService := service.NewRetryService(replyLogRepo, userRepo, adReplyService, linkTracker, &cfg.Workflow, logger)
+	purgeService := service.NewPurgeService(adCopyRepo, userRepo, &cfg.Workflow.Purge, logger)
+	bulkService := service.NewBulkService(adCopyRepo, userRepo, userGroupRepo, logger)
+	streamService := service.NewStreamService(streamClient, workflowService, &cfg.Stream, logger)
+
 	// 初始化 HTTP handlers
-	workflowHandler := handler.NewWorkflowHandler(workflowService, followerService, replyLogRepo)
-	adCopyHandler := handler.NewAdCopyHandler(adCopyRepo)
-	userHandler := handler.NewUserHandler(userRepo)
+	workflowHandler := handler.NewWorkflowHandler(workflowService, followerService, retryService, replyLogRepo)
+	adCopyHandler := handler.NewAdCopyHandler(adCopyRepo, adMetrics)
+	userHandler := handler.NewUserHandler(userRepo, followerService, userActivity)
+	groupHandler := handler.NewUserGroupHandler(userGroupRepo, userRepo)
+	campaignHandler := handler.NewCampaignHandler(campaignRepo, campaignTargeting)
+	linkHandler := handler.NewLinkHandler(linkTracker, trackedLinkRepo)
+	bulkHandler := handler.NewBulkHandler(bulkService)
+	approvalHandler := handler.NewApprovalHandler(approvalService)
+	searchHandler := handler.NewSearchQueryHandler(searchQueryRepo)
+	streamHandler := handler.NewStreamHandler(streamService)
 
 	// 初始化路由
 	apiKey := os.Getenv("API_KEY")
-	router := api.NewRouter(workflowHandler, adCopyHandler, userHandler, cfg.Server.Mode, apiKey)
+	router := api.NewRouter(workflowHandler, adCopyHandler, userHandler, groupHandler, campaignHandler, linkHandler, bulkHandler, approvalHandler, searchHandler, streamHandler, cfg.Server.Mode, apiKey)
 
 	// 初始化定时任务
-	sched := scheduler.NewScheduler(workflowService, &cfg.Workflow, logger)
+	sched := scheduler.NewScheduler(workflowService, followerService, userActivity, adMetrics, adReplyService, approvalService, retryService, purgeService, &cfg.Workflow, logger)
 	if err := sched.Start(); err != nil {
 		logger.Error("启动定时任务失败", zap.Error(err))
 	}
 
+	// 启动 filtered stream 实时消费
+	if cfg.Stream.Enabled {
+		if err := streamService.Start(); err != nil {
+			logger.Error("启动实时流消费失败", zap.Error(err))
+		}
+	}
+
 	// 启动 HTTP 服务
 	server := &http.Server{
 		Addr:    fmt.Sprintf(":%d", cfg.Server.Port),
@@ -115,6 +156,9 @@ func main() {
 	// 停止定时任务
 	sched.Stop()
 
+	// 停止实时流消费
+	streamService.Stop()
+
 	// 优雅关闭 HTTP 服务
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -0,0 +1,146 @@
+// xbotctl 命令行工具：批量导入导出广告文案和监控用户
+//
+//	xbotctl [-config config/config.yaml] export ad-copies|users [-format csv|json] [-o 文件]
+//	xbotctl [-config config/config.yaml] import ad-copies|users -file 文件 [-format csv|json] [-dry-run]
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/zhoubofsy/x-bot/internal/application/service"
+	"github.com/zhoubofsy/x-bot/internal/config"
+	"github.com/zhoubofsy/x-bot/internal/infrastructure/persistence/postgres"
+	"go.uber.org/zap"
+)
+
+const usage = `用法:
+  xbotctl [-config 配置文件] export ad-copies|users [-format csv|json] [-o 输出文件]
+  xbotctl [-config 配置文件] import ad-copies|users -file 文件 [-format csv|json] [-dry-run]
+`
+
+func main() {
+	configPath := flag.String("config", "config/config.yaml", "配置文件路径")
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) < 2 || (args[1] != "ad-copies" && args[1] != "users") {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fatalf("加载配置失败: %v", err)
+	}
+	db, err := postgres.NewDB(&cfg.Database)
+	if err != nil {
+		fatalf("连接数据库失败: %v", err)
+	}
+
+	bulkService := service.NewBulkService(
+		postgres.NewAdCopyRepository(db),
+		postgres.NewUserRepository(db),
+		postgres.NewUserGroupRepository(db),
+		zap.NewNop(),
+	)
+
+	ctx := context.Background()
+	switch args[0] {
+	case "export":
+		runExport(ctx, bulkService, args[1], args[2:])
+	case "import":
+		runImport(ctx, bulkService, args[1], args[2:])
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+func runExport(ctx context.Context, bulkService service.BulkService, target string, args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	format := fs.String("format", "", "csv 或 json，默认按输出文件扩展名判断，否则为 csv")
+	output := fs.String("o", "", "输出文件，默认输出到标准输出")
+	fs.Parse(args)
+
+	if *format == "" {
+		*format = service.BulkFormatOf(*output)
+	}
+	if *format == "" {
+		*format = service.BulkFormatCSV
+	}
+
+	w := io.Writer(os.Stdout)
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			fatalf("创建输出文件失败: %v", err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	export := bulkService.ExportAdCopies
+	if target == "users" {
+		export = bulkService.ExportUsers
+	}
+	if err := export(ctx, *format, w); err != nil {
+		fatalf("导出失败: %v", err)
+	}
+}
+
+func runImport(ctx context.Context, bulkService service.BulkService, target string, args []string) {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	path := fs.String("file", "", "导入文件（必填）")
+	format := fs.String("format", "", "csv 或 json，默认按文件扩展名判断")
+	dryRun := fs.Bool("dry-run", false, "只校验不写入")
+	fs.Parse(args)
+
+	if *path == "" {
+		fatalf("缺少 -file 参数")
+	}
+	if *format == "" {
+		*format = service.BulkFormatOf(*path)
+	}
+	if *format == "" {
+		fatalf("无法根据文件扩展名判断格式，请指定 -format")
+	}
+
+	file, err := os.Open(*path)
+	if err != nil {
+		fatalf("打开文件失败: %v", err)
+	}
+	defer file.Close()
+
+	importer := bulkService.ImportAdCopies
+	if target == "users" {
+		importer = bulkService.ImportUsers
+	}
+	result, err := importer(ctx, *format, file, *dryRun)
+	if err != nil {
+		fatalf("导入失败: %v", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(result)
+
+	// 有失败记录时以非零状态退出，便于脚本判断
+	if result.Failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
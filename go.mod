@@ -10,6 +10,7 @@ require (
 	github.com/robfig/cron/v3 v3.0.0
 	github.com/spf13/viper v1.21.0
 	go.uber.org/zap v1.27.1
+	golang.org/x/text v0.28.0
 	google.golang.org/genai v1.36.0
 	gorm.io/driver/postgres v1.5.11
 	gorm.io/gorm v1.31.1
@@ -69,7 +70,6 @@ require (
 	golang.org/x/net v0.42.0 // indirect
 	golang.org/x/sync v0.16.0 // indirect
 	golang.org/x/sys v0.35.0 // indirect
-	golang.org/x/text v0.28.0 // indirect
 	golang.org/x/tools v0.35.0 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20240903143218-8af14fe29dc1 // indirect
 	google.golang.org/grpc v1.66.2 // indirect
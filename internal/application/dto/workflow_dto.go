@@ -1,5 +1,12 @@
 package dto
 
+import (
+	"time"
+
+	"github.com/zhoubofsy/x-bot/internal/domain/entity"
+	"github.com/zhoubofsy/x-bot/internal/domain/repository"
+)
+
 // WorkflowParams 工作流执行参数
 type WorkflowParams struct {
 	TweetCount int  `json:"tweet_count" form:"tweet_count"` // 每个用户获取的推文数量
@@ -9,28 +16,196 @@ type WorkflowParams struct {
 // WorkflowResult 工作流执行结果
 type WorkflowResult struct {
 	TotalUsers        int      `json:"total_users"`
+	DeferredUsers     int      `json:"deferred_users"` // 未到自适应拉取时间而跳过的用户数
+	TotalQueries      int      `json:"total_queries"`
+	TotalLists        int      `json:"total_lists"`
+	ActiveCampaigns   int      `json:"active_campaigns"`
 	TotalTweets       int      `json:"total_tweets"`
 	HackathonTweets   int      `json:"hackathon_tweets"`
 	SuccessfulReplies int      `json:"successful_replies"`
 	FailedReplies     int      `json:"failed_replies"`
 	SkippedTweets     int      `json:"skipped_tweets"`
+	BlockedTweets     int      `json:"blocked_tweets"`
+	PendingApprovals  int      `json:"pending_approvals"`
 	Errors            []string `json:"errors,omitempty"`
 }
 
 // ProcessResult 单条推文处理结果
 type ProcessResult struct {
 	TweetID     string
+	Detected    bool // 推文经过了黑客松检测，用于统计用户命中率
 	IsHackathon bool
 	Success     bool
 	Skipped     bool
+	Blocked     bool
+	Pending     bool
 	Error       error
 }
 
+// SafetyCheckResult 回复前安全审核结果
+type SafetyCheckResult struct {
+	Safe        bool   `json:"safe"`
+	Category    string `json:"category,omitempty"`
+	Reason      string `json:"reason,omitempty"`
+	RawResponse string `json:"raw_response,omitempty"`
+}
+
+// ApprovalSendResult 审核队列发送结果
+type ApprovalSendResult struct {
+	ReplyLogID   int    `json:"reply_log_id"`
+	TweetID      string `json:"tweet_id"`
+	ReplyTweetID string `json:"reply_tweet_id,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+// RetryResult 失败回复重试结果
+type RetryResult struct {
+	ReplyLogID    int                `json:"reply_log_id"`
+	TweetID       string             `json:"tweet_id"`
+	Status        entity.ReplyStatus `json:"status"`
+	FailureReason string             `json:"failure_reason,omitempty"`
+	RetryCount    int                `json:"retry_count"`
+}
+
+// StreamStatus filtered stream 消费状态
+type StreamStatus struct {
+	Running         bool       `json:"running"`
+	Connected       bool       `json:"connected"`
+	ConnectedAt     *time.Time `json:"connected_at,omitempty"`
+	Reconnects      int        `json:"reconnects"`
+	LastError       string     `json:"last_error,omitempty"`
+	ReceivedTweets  int64      `json:"received_tweets"`
+	ProcessedTweets int64      `json:"processed_tweets"`
+	DroppedTweets   int64      `json:"dropped_tweets"`
+}
+
 // SyncFollowingResult 同步关注用户结果
 type SyncFollowingResult struct {
+	TotalCount   int            `json:"total_count"`
+	NewCount     int            `json:"new_count"`
+	UpdatedCount int            `json:"updated_count"`
+	RemovedCount int            `json:"removed_count"`           // 已取消关注或移出列表而被停用的用户数
+	Source       string         `json:"source,omitempty"`        // "twitter_api" 或 "database"
+	SourceCounts map[string]int `json:"source_counts,omitempty"` // 各来源（following、list:<id>）的用户数
+	Errors       []string       `json:"errors,omitempty"`
+}
+
+// RefreshProfilesResult 刷新用户资料结果
+type RefreshProfilesResult struct {
 	TotalCount   int      `json:"total_count"`
-	NewCount     int      `json:"new_count"`
 	UpdatedCount int      `json:"updated_count"`
-	Source       string   `json:"source,omitempty"` // "twitter_api" 或 "database"
 	Errors       []string `json:"errors,omitempty"`
 }
+
+// ActivityEvaluationResult 用户活跃度评估结果
+type ActivityEvaluationResult struct {
+	TotalCount         int      `json:"total_count"`
+	DeactivatedCount   int      `json:"deactivated_count"`
+	DeprioritizedCount int      `json:"deprioritized_count"`
+	RestoredCount      int      `json:"restored_count"` // 不再满足休眠条件而恢复正常的用户数
+	Errors             []string `json:"errors,omitempty"`
+}
+
+// ReplyMetricsResult 拉取回复互动数据结果
+type ReplyMetricsResult struct {
+	TotalCount   int      `json:"total_count"`
+	UpdatedCount int      `json:"updated_count"`
+	MissingCount int      `json:"missing_count"` // 已删除或不可见的回复数
+	Errors       []string `json:"errors,omitempty"`
+}
+
+// PurgeResult 清理已删除数据的结果
+type PurgeResult struct {
+	AdCopies int64 `json:"ad_copies"`
+	Users    int64 `json:"users"`
+}
+
+// AdCopyPerformance 广告文案效果
+type AdCopyPerformance struct {
+	repository.AdCopyEngagement
+	Name           string  `json:"name"`
+	Category       string  `json:"category"`
+	UseCount       int     `json:"use_count"`
+	EngagementRate float64 `json:"engagement_rate"`
+	TrackedReplies int64   `json:"tracked_replies"` // 带短链接的成功回复数
+	ClickedReplies int64   `json:"clicked_replies"`
+	Clicks         int64   `json:"clicks"`
+	CTR            float64 `json:"ctr"` // 链接被点击过的回复占比
+}
+
+// CampaignStats 推广活动统计
+type CampaignStats struct {
+	CampaignID         int                   `json:"campaign_id"`
+	Status             entity.CampaignStatus `json:"status"`
+	Replies            int64                 `json:"replies"` // 已发送或即将发送的回复数，计入回复预算
+	SuccessCount       int64                 `json:"success_count"`
+	PendingCount       int64                 `json:"pending_count"` // 待审核和已批准待发送
+	FailedCount        int64                 `json:"failed_count"`
+	BlockedCount       int64                 `json:"blocked_count"`
+	DryRunCount        int64                 `json:"dry_run_count"`
+	LLMCalls           int                   `json:"llm_calls"`
+	LLMSpend           float64               `json:"llm_spend"`
+	RemainingReplies   *int64                `json:"remaining_replies"`    // 不限制时为 null
+	RemainingLLMBudget *float64              `json:"remaining_llm_budget"` // 不限制时为 null
+	AdCopyCount        int                   `json:"ad_copy_count"`
+}
+
+// UserLookupResult 按用户名查询用户的结果，查询失败时 User 为空、Error 为原因
+type UserLookupResult struct {
+	Handle   string               `json:"handle"`
+	Username string               `json:"username"`
+	User     *entity.FollowedUser `json:"user,omitempty"`
+	Error    string               `json:"error,omitempty"`
+}
+
+// AdCopyRecord 批量导入导出的广告文案，按名称匹配已有文案；导入时一条记录即文案的完整配置
+type AdCopyRecord struct {
+	Name         string                      `json:"name"`
+	Content      string                      `json:"content"`
+	LinkURL      string                      `json:"link_url,omitempty"`
+	Language     string                      `json:"language,omitempty"`
+	Category     string                      `json:"category,omitempty"`
+	CampaignID   *int                        `json:"campaign_id,omitempty"`
+	Priority     int                         `json:"priority"`
+	IsActive     *bool                       `json:"is_active,omitempty"` // 为空时新建的文案启用、已有文案保持不变
+	Variants     []entity.AdCopyVariantInput `json:"variants,omitempty"`
+	Emojis       []string                    `json:"emojis,omitempty"`
+	Hashtags     []string                    `json:"hashtags,omitempty"`
+	StartsAt     *time.Time                  `json:"starts_at,omitempty"`
+	EndsAt       *time.Time                  `json:"ends_at,omitempty"`
+	MaxDailyUses int                         `json:"max_daily_uses,omitempty"`
+	MaxTotalUses int                         `json:"max_total_uses,omitempty"`
+	Weekdays     []int                       `json:"weekdays,omitempty"`
+	StartHour    *int                        `json:"start_hour,omitempty"`
+	EndHour      *int                        `json:"end_hour,omitempty"`
+	Timezone     string                      `json:"timezone,omitempty"`
+}
+
+// UserRecord 批量导入导出的监控用户，按 twitter_user_id 匹配已有用户
+type UserRecord struct {
+	TwitterUserID        string   `json:"twitter_user_id"`
+	Username             string   `json:"username"`
+	DisplayName          string   `json:"display_name,omitempty"`
+	IsActive             *bool    `json:"is_active,omitempty"`              // 为空时新建的用户启用、已有用户保持不变
+	ReplyCooldownSeconds *int     `json:"reply_cooldown_seconds,omitempty"` // 为空表示使用全局默认值
+	MaxRepliesPerWeek    *int     `json:"max_replies_per_week,omitempty"`
+	Groups               []string `json:"groups,omitempty"` // 分组名称，导入时替换用户的分组，为空（未给出）时保持不变
+}
+
+// ImportResult 批量导入结果，dry_run 时只校验，created/updated 为将要执行的操作数
+type ImportResult struct {
+	DryRun  bool               `json:"dry_run"`
+	Total   int                `json:"total"`
+	Created int                `json:"created"`
+	Updated int                `json:"updated"`
+	Failed  int                `json:"failed"`
+	Rows    []*ImportRowResult `json:"rows"`
+}
+
+// ImportRowResult 单条记录的导入结果
+type ImportRowResult struct {
+	Row    int    `json:"row"`    // 记录序号，从 1 开始（CSV 不含表头）
+	Key    string `json:"key"`    // 文案名称或 twitter_user_id
+	Action string `json:"action"` // create、update、failed
+	Error  string `json:"error,omitempty"`
+}
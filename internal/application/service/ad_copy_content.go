@@ -0,0 +1,191 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zhoubofsy/x-bot/internal/domain/entity"
+	"github.com/zhoubofsy/x-bot/pkg/adtemplate"
+	"github.com/zhoubofsy/x-bot/pkg/langdetect"
+	"github.com/zhoubofsy/x-bot/pkg/spintax"
+	"github.com/zhoubofsy/x-bot/pkg/tweettext"
+)
+
+// NormalizeAdCopyLanguage 规范化文案语言，未指定或无法识别时为空
+func NormalizeAdCopyLanguage(language string) string {
+	if lang := langdetect.Normalize(language); lang != langdetect.Undetermined {
+		return lang
+	}
+	return ""
+}
+
+// AdCopySampleData 示例数据，文案有推广链接时使用该链接
+func AdCopySampleData(linkURL string) adtemplate.Data {
+	data := adtemplate.Sample()
+	if linkURL != "" {
+		data.TrackingLink = linkURL
+	}
+	return data
+}
+
+// AdCopyLongestSampleData 示例数据，emoji 和话题标签使用文案集合中最长的一项，用于计算最大长度
+func AdCopyLongestSampleData(adCopy *entity.AdCopy) adtemplate.Data {
+	data := AdCopySampleData(adCopy.LinkURL)
+	if len(adCopy.Emojis) > 0 {
+		data.Emoji = longestItem(adCopy.Emojis)
+	}
+	if len(adCopy.Hashtags) > 0 {
+		data.Hashtag = longestItem(adCopy.Hashtags)
+	}
+	return data
+}
+
+// ValidateAdCopyContent 校验 spintax 和文案模板，并以示例数据渲染覆盖全部候选项的变体和最长的变体，检查推文加权长度
+func ValidateAdCopyContent(content string, linkURL string) error {
+	return validateAdCopyContent(content, AdCopySampleData(linkURL))
+}
+
+// ValidateAdCopyVariants 校验已有语言变体，用于只修改推广链接或回滚到历史版本时重新检查
+func ValidateAdCopyVariants(variants []entity.AdCopyVariant, linkURL string) error {
+	for _, v := range variants {
+		if err := ValidateAdCopyContent(v.Content, linkURL); err != nil {
+			return fmt.Errorf("variant %s: %w", v.Language, err)
+		}
+	}
+	return nil
+}
+
+// ValidateAdCopyVariation 校验文案的 emoji 和话题标签集合，
+// 并使用其中最长的一项渲染文案及各语言变体，检查推文加权长度
+func ValidateAdCopyVariation(adCopy *entity.AdCopy) error {
+	for _, emoji := range adCopy.Emojis {
+		if strings.TrimSpace(emoji) == "" || strings.ContainsAny(emoji, " \t\n") {
+			return fmt.Errorf("无效的 emoji: %q", emoji)
+		}
+	}
+	for _, hashtag := range adCopy.Hashtags {
+		if len(hashtag) < 2 || !strings.HasPrefix(hashtag, "#") || strings.ContainsAny(hashtag, " \t\n") {
+			return fmt.Errorf("无效的话题标签: %q，应以 # 开头且不含空格", hashtag)
+		}
+	}
+	if len(adCopy.Emojis) == 0 && len(adCopy.Hashtags) == 0 {
+		return nil
+	}
+
+	data := AdCopyLongestSampleData(adCopy)
+	if err := validateAdCopyContent(adCopy.Content, data); err != nil {
+		return err
+	}
+	for _, v := range adCopy.Variants {
+		if err := validateAdCopyContent(v.Content, data); err != nil {
+			return fmt.Errorf("variant %s: %w", v.Language, err)
+		}
+	}
+	return nil
+}
+
+func validateAdCopyContent(content string, data adtemplate.Data) error {
+	parsed, err := spintax.Parse(content)
+	if err != nil {
+		return err
+	}
+	// 模板语法可能只出现在部分候选项中，校验覆盖全部候选项的变体，
+	// 以及每组都选择渲染后最长候选项的变体
+	variants := append(parsed.Cover(), parsed.Longest(renderedLength(data)))
+	for _, variant := range variants {
+		if err := adtemplate.Validate(variant); err != nil {
+			return err
+		}
+		text, err := adtemplate.Render(variant, data)
+		if err != nil {
+			return err
+		}
+		if err := tweettext.Validate(text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AdCopyMaxWeightedLength 文案最长的 spintax 变体以示例数据渲染后的推文加权长度，渲染失败时按原文计算
+func AdCopyMaxWeightedLength(content string, data adtemplate.Data) int {
+	if parsed, err := spintax.Parse(content); err == nil {
+		content = parsed.Longest(renderedLength(data))
+	}
+	return renderedLength(data)(content)
+}
+
+// renderedLength 以示例数据渲染后计算推文加权长度；候选项单独无法渲染时（如模板动作跨越候选项）按原文计算
+func renderedLength(data adtemplate.Data) func(string) int {
+	return func(text string) int {
+		if rendered, err := adtemplate.Render(text, data); err == nil {
+			text = rendered
+		}
+		return tweettext.WeightedLength(text)
+	}
+}
+
+// longestItem 按推文加权长度选择最长的一项
+func longestItem(items []string) string {
+	longest := ""
+	for _, item := range items {
+		if tweettext.WeightedLength(item) > tweettext.WeightedLength(longest) {
+			longest = item
+		}
+	}
+	return longest
+}
+
+// RenderAdCopySample 随机展开 spintax、选择 emoji 和话题标签后渲染文案，返回结果及可能的变体数量，用于预览
+func RenderAdCopySample(content string, emojis, hashtags []string, data adtemplate.Data) (string, int, error) {
+	parsed, err := spintax.Parse(content)
+	if err != nil {
+		return "", 0, err
+	}
+	data.Emoji = pickOne(emojis)
+	data.Hashtag = pickOne(hashtags)
+	text, err := adtemplate.Render(parsed.Spin(), data)
+	if err != nil {
+		return "", 0, err
+	}
+	variations := min(parsed.Count()*max(len(emojis), 1)*max(len(hashtags), 1), 1<<20)
+	return text, variations, nil
+}
+
+// ValidateAdCopySchedule 校验文案的投放时间和时区
+func ValidateAdCopySchedule(adCopy *entity.AdCopy) error {
+	if adCopy.StartsAt != nil && adCopy.EndsAt != nil && !adCopy.EndsAt.After(*adCopy.StartsAt) {
+		return fmt.Errorf("ends_at must be after starts_at")
+	}
+	if adCopy.Timezone != "" {
+		if _, err := time.LoadLocation(adCopy.Timezone); err != nil {
+			return fmt.Errorf("无效的时区: %s", adCopy.Timezone)
+		}
+	}
+	return nil
+}
+
+// BuildAdCopyVariants 校验并转换语言变体输入
+func BuildAdCopyVariants(inputs []entity.AdCopyVariantInput, linkURL string) ([]entity.AdCopyVariant, error) {
+	variants := make([]entity.AdCopyVariant, 0, len(inputs))
+	seen := make(map[string]bool)
+	for _, input := range inputs {
+		lang := NormalizeAdCopyLanguage(input.Language)
+		if lang == "" || strings.TrimSpace(input.Content) == "" {
+			return nil, fmt.Errorf("variant language and content are required")
+		}
+		if seen[lang] {
+			return nil, fmt.Errorf("duplicate variant language: %s", lang)
+		}
+		if err := ValidateAdCopyContent(input.Content, linkURL); err != nil {
+			return nil, fmt.Errorf("variant %s: %w", lang, err)
+		}
+		seen[lang] = true
+		variants = append(variants, entity.AdCopyVariant{
+			Language: lang,
+			Content:  input.Content,
+		})
+	}
+	return variants, nil
+}
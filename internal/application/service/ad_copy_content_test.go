@@ -0,0 +1,46 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zhoubofsy/x-bot/pkg/adtemplate"
+)
+
+func TestValidateAdCopyContent(t *testing.T) {
+	// 200 个字符的正文，加上较长的候选项后超过推文长度上限
+	body := strings.Repeat("a", 200) + " "
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{"plain", "Good luck with {{.Event.Name}}!", false},
+		{"spintax", "{Hi|Hey} @{{.Author.Username}}, {good luck|all the best}!", false},
+		{"bad template in one of many choices", "{a|b|c|d|e|f|g|h|i|j|k|l|m|n|o|p|q|r|s|t|u|v|w|x|y|{{.Author.Handle}}}", true},
+		{"bad template in a nested choice", "{Hi|Hey} {there|{you|{{.Missing}}}}", true},
+		{"long raw choice within limit", body + "{x|" + strings.Repeat("y", 30) + "}", false},
+		// 模板原文比另一个候选项短，渲染后（示例推文约 90 个字符）超过上限
+		{"long rendered choice over limit", body + "{" + strings.Repeat("y", 30) + "|{{.Tweet.Text}}}", true},
+		{"unbalanced spintax", "{Hi|Hey", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateAdCopyContent(tt.content, adtemplate.Sample())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateAdCopyContent(%q) error = %v, wantErr %v", tt.content, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAdCopyMaxWeightedLength(t *testing.T) {
+	data := adtemplate.Sample()
+	// 示例作者名 alice_dev 渲染后比原文 xxxxxxxxxxxx 短，示例推文比 yyyyyyyyyyyyyyyyyyyy 长
+	content := "{{{.Author.Username}}|xxxxxxxxxxxx} {yyyyyyyyyyyyyyyyyyyy|{{.Tweet.Text}}}"
+	want := renderedLength(data)("xxxxxxxxxxxx " + data.Tweet.Text)
+	if got := AdCopyMaxWeightedLength(content, data); got != want {
+		t.Errorf("AdCopyMaxWeightedLength() = %d, want %d", got, want)
+	}
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+
+	"github.com/zhoubofsy/x-bot/internal/domain/entity"
+	"github.com/zhoubofsy/x-bot/internal/domain/repository"
+)
+
+// 广告文案选择策略
+const (
+	SelectionRoundRobin     = "round_robin"     // 按优先级和使用次数轮换
+	SelectionWeightedRandom = "weighted_random" // 按优先级加权随机
+	SelectionEpsilonGreedy  = "epsilon_greedy"  // 以 epsilon 概率随机探索，其余选择互动率最高的文案
+	SelectionThompson       = "thompson"        // Thompson 采样：按互动率的 Beta 后验分布采样
+)
+
+// AdCopySelector 从候选文案中选择本次回复使用的文案
+type AdCopySelector interface {
+	// Select 选择文案，candidates 已按优先级降序、使用次数升序排列；stats 中可能缺少未拉取到互动数据的文案
+	Select(candidates []*entity.AdCopy, stats map[int]*repository.AdCopyEngagement) *entity.AdCopy
+
+	// UsesEngagement 是否依赖互动数据，不依赖时无需查询 stats
+	UsesEngagement() bool
+}
+
+// randSource 选择器使用的随机数来源，测试中可替换为固定种子的 *rand.Rand
+type randSource interface {
+	IntN(n int) int
+	Float64() float64
+	NormFloat64() float64
+}
+
+// globalRand 使用 math/rand/v2 的全局随机数，并发安全
+type globalRand struct{}
+
+func (globalRand) IntN(n int) int       { return rand.IntN(n) }
+func (globalRand) Float64() float64     { return rand.Float64() }
+func (globalRand) NormFloat64() float64 { return rand.NormFloat64() }
+
+// NewAdCopySelector 根据策略名称创建选择器
+func NewAdCopySelector(strategy string, epsilon float64) (AdCopySelector, error) {
+	switch strategy {
+	case SelectionRoundRobin, "":
+		return roundRobinSelector{rng: globalRand{}}, nil
+	case SelectionWeightedRandom:
+		return weightedRandomSelector{rng: globalRand{}}, nil
+	case SelectionEpsilonGreedy:
+		if epsilon < 0 || epsilon > 1 {
+			return nil, fmt.Errorf("epsilon 必须在 0 到 1 之间: %v", epsilon)
+		}
+		return epsilonGreedySelector{epsilon: epsilon, rng: globalRand{}}, nil
+	case SelectionThompson:
+		return thompsonSelector{rng: globalRand{}}, nil
+	default:
+		return nil, fmt.Errorf("未知的文案选择策略: %s", strategy)
+	}
+}
+
+// roundRobinSelector 选择优先级最高、使用次数最少的文案，相同时随机
+type roundRobinSelector struct {
+	rng randSource
+}
+
+func (roundRobinSelector) UsesEngagement() bool { return false }
+
+func (s roundRobinSelector) Select(candidates []*entity.AdCopy, _ map[int]*repository.AdCopyEngagement) *entity.AdCopy {
+	if len(candidates) == 0 {
+		return nil
+	}
+	first := candidates[0]
+	n := 1
+	for n < len(candidates) && candidates[n].Priority == first.Priority && candidates[n].UseCount == first.UseCount {
+		n++
+	}
+	return candidates[s.rng.IntN(n)]
+}
+
+// weightedRandomSelector 按优先级加权随机，权重为 priority+1（负数按 1 计算）
+type weightedRandomSelector struct {
+	rng randSource
+}
+
+func (weightedRandomSelector) UsesEngagement() bool { return false }
+
+func (s weightedRandomSelector) Select(candidates []*entity.AdCopy, _ map[int]*repository.AdCopyEngagement) *entity.AdCopy {
+	if len(candidates) == 0 {
+		return nil
+	}
+	weight := func(a *entity.AdCopy) int {
+		if a.Priority < 0 {
+			return 1
+		}
+		return a.Priority + 1
+	}
+
+	total := 0
+	for _, a := range candidates {
+		total += weight(a)
+	}
+	pick := s.rng.IntN(total)
+	for _, a := range candidates {
+		pick -= weight(a)
+		if pick < 0 {
+			return a
+		}
+	}
+	return candidates[len(candidates)-1]
+}
+
+// epsilonGreedySelector 以 epsilon 概率随机选择，否则选择互动率最高的文案；
+// 还没有互动数据的文案优先尝试
+type epsilonGreedySelector struct {
+	epsilon float64
+	rng     randSource
+}
+
+func (epsilonGreedySelector) UsesEngagement() bool { return true }
+
+func (s epsilonGreedySelector) Select(candidates []*entity.AdCopy, stats map[int]*repository.AdCopyEngagement) *entity.AdCopy {
+	if len(candidates) == 0 {
+		return nil
+	}
+	if s.rng.Float64() < s.epsilon {
+		return candidates[s.rng.IntN(len(candidates))]
+	}
+
+	var best *entity.AdCopy
+	bestRate := -1.0
+	for _, a := range candidates {
+		e := stats[a.ID]
+		if e == nil || e.Replies == 0 {
+			return a
+		}
+		if rate := e.EngagementRate(); rate > bestRate {
+			best, bestRate = a, rate
+		}
+	}
+	return best
+}
+
+// thompsonSelector 对每个文案从 Beta(1+互动数, 1+无互动数) 采样，选择采样值最大的文案
+type thompsonSelector struct {
+	rng randSource
+}
+
+func (thompsonSelector) UsesEngagement() bool { return true }
+
+func (s thompsonSelector) Select(candidates []*entity.AdCopy, stats map[int]*repository.AdCopyEngagement) *entity.AdCopy {
+	var best *entity.AdCopy
+	bestSample := -1.0
+	for _, a := range candidates {
+		alpha, beta := 1.0, 1.0
+		if e := stats[a.ID]; e != nil {
+			alpha += float64(e.Engaged)
+			beta += float64(e.Replies - e.Engaged)
+		}
+		if sample := sampleBeta(s.rng, alpha, beta); sample > bestSample {
+			best, bestSample = a, sample
+		}
+	}
+	return best
+}
+
+// sampleBeta 通过两个 Gamma 分布采样 Beta(alpha, beta)
+func sampleBeta(rng randSource, alpha, beta float64) float64 {
+	x := sampleGamma(rng, alpha)
+	y := sampleGamma(rng, beta)
+	if x+y == 0 {
+		return 0
+	}
+	return x / (x + y)
+}
+
+// sampleGamma Marsaglia-Tsang 方法采样 Gamma(shape, 1)，shape < 1 时通过 shape+1 变换
+func sampleGamma(rng randSource, shape float64) float64 {
+	if shape < 1 {
+		return sampleGamma(rng, shape+1) * math.Pow(rng.Float64(), 1/shape)
+	}
+
+	d := shape - 1.0/3
+	c := 1 / math.Sqrt(9*d)
+	for {
+		x := rng.NormFloat64()
+		v := 1 + c*x
+		if v <= 0 {
+			continue
+		}
+		v = v * v * v
+		u := rng.Float64()
+		if math.Log(u) < 0.5*x*x+d-d*v+d*math.Log(v) {
+			return d * v
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/zhoubofsy/x-bot/internal/domain/entity"
+	"github.com/zhoubofsy/x-bot/internal/domain/repository"
+)
+
+func seededRand() *rand.Rand {
+	return rand.New(rand.NewPCG(42, 1024))
+}
+
+func TestSampleGammaMean(t *testing.T) {
+	const n = 20000
+	for _, shape := range []float64{0.3, 1, 2.5, 10} {
+		rng := seededRand()
+		sum := 0.0
+		for i := 0; i < n; i++ {
+			x := sampleGamma(rng, shape)
+			if x < 0 || math.IsNaN(x) || math.IsInf(x, 0) {
+				t.Fatalf("sampleGamma(%v) = %v", shape, x)
+			}
+			sum += x
+		}
+		// Gamma(shape, 1) 的均值为 shape
+		if mean := sum / n; math.Abs(mean-shape) > 0.05*shape+0.01 {
+			t.Errorf("sampleGamma(%v) mean = %.4f, want about %v", shape, mean, shape)
+		}
+	}
+}
+
+func TestSampleBetaMean(t *testing.T) {
+	const n = 20000
+	tests := []struct{ alpha, beta float64 }{
+		{1, 1},
+		{2, 8},
+		{31, 6},
+		{1, 1001},
+	}
+	for _, tt := range tests {
+		rng := seededRand()
+		sum := 0.0
+		for i := 0; i < n; i++ {
+			x := sampleBeta(rng, tt.alpha, tt.beta)
+			if x < 0 || x > 1 {
+				t.Fatalf("sampleBeta(%v, %v) = %v, want in [0, 1]", tt.alpha, tt.beta, x)
+			}
+			sum += x
+		}
+		want := tt.alpha / (tt.alpha + tt.beta)
+		if mean := sum / n; math.Abs(mean-want) > 0.01 {
+			t.Errorf("sampleBeta(%v, %v) mean = %.4f, want about %.4f", tt.alpha, tt.beta, mean, want)
+		}
+	}
+}
+
+func TestSampleBetaSeeded(t *testing.T) {
+	a, b := seededRand(), seededRand()
+	for i := 0; i < 100; i++ {
+		if x, y := sampleBeta(a, 3, 7), sampleBeta(b, 3, 7); x != y {
+			t.Fatalf("sample %d: %v != %v with the same seed", i, x, y)
+		}
+	}
+}
+
+func adCopies(priorities ...int) []*entity.AdCopy {
+	copies := make([]*entity.AdCopy, 0, len(priorities))
+	for i, p := range priorities {
+		copies = append(copies, &entity.AdCopy{ID: i + 1, Priority: p})
+	}
+	return copies
+}
+
+// countPicks 多次选择并统计每个文案被选中的次数
+func countPicks(selector AdCopySelector, candidates []*entity.AdCopy, stats map[int]*repository.AdCopyEngagement, n int) map[int]int {
+	picks := make(map[int]int)
+	for i := 0; i < n; i++ {
+		picks[selector.Select(candidates, stats).ID]++
+	}
+	return picks
+}
+
+func TestRoundRobinSelectorTies(t *testing.T) {
+	candidates := adCopies(5, 5, 5, 1)
+	candidates[2].UseCount = 3
+
+	picks := countPicks(roundRobinSelector{rng: seededRand()}, candidates, nil, 1000)
+	if picks[3] != 0 || picks[4] != 0 {
+		t.Errorf("picks = %v, want only the least used copies with the highest priority", picks)
+	}
+	if picks[1] == 0 || picks[2] == 0 {
+		t.Errorf("picks = %v, want ties broken randomly between 1 and 2", picks)
+	}
+}
+
+func TestWeightedRandomSelectorWeights(t *testing.T) {
+	// 权重为 priority+1，负数按 1 计算：1、3、1
+	candidates := adCopies(0, 2, -5)
+
+	const n = 10000
+	picks := countPicks(weightedRandomSelector{rng: seededRand()}, candidates, nil, n)
+	for id, want := range map[int]float64{1: 0.2, 2: 0.6, 3: 0.2} {
+		if got := float64(picks[id]) / n; math.Abs(got-want) > 0.02 {
+			t.Errorf("copy %d picked %.3f of the time, want about %.1f", id, got, want)
+		}
+	}
+}
+
+func TestEpsilonGreedySelector(t *testing.T) {
+	candidates := adCopies(0, 0, 0)
+	stats := map[int]*repository.AdCopyEngagement{
+		1: {AdCopyID: 1, Replies: 10, Engaged: 1},
+		2: {AdCopyID: 2, Replies: 10, Engaged: 6},
+		3: {AdCopyID: 3, Replies: 10, Engaged: 3},
+	}
+
+	greedy := epsilonGreedySelector{epsilon: 0, rng: seededRand()}
+	if got := greedy.Select(candidates, stats); got.ID != 2 {
+		t.Errorf("epsilon 0 selected %d, want the highest engagement rate 2", got.ID)
+	}
+
+	// 没有互动数据的文案优先尝试
+	delete(stats, 3)
+	if got := greedy.Select(candidates, stats); got.ID != 3 {
+		t.Errorf("selected %d, want the untried copy 3", got.ID)
+	}
+
+	picks := countPicks(epsilonGreedySelector{epsilon: 1, rng: seededRand()}, candidates, stats, 300)
+	if len(picks) != len(candidates) {
+		t.Errorf("epsilon 1 picks = %v, want every copy explored", picks)
+	}
+}
+
+func TestThompsonSelector(t *testing.T) {
+	candidates := adCopies(0, 0)
+	stats := map[int]*repository.AdCopyEngagement{
+		1: {AdCopyID: 1, Replies: 1000, Engaged: 10},
+		2: {AdCopyID: 2, Replies: 1000, Engaged: 900},
+	}
+
+	picks := countPicks(thompsonSelector{rng: seededRand()}, candidates, stats, 200)
+	if picks[2] != 200 {
+		t.Errorf("picks = %v, want the clearly better copy 2 every time", picks)
+	}
+
+	// 没有数据时两者的后验相同，都有机会被选中
+	picks = countPicks(thompsonSelector{rng: seededRand()}, candidates, nil, 200)
+	if picks[1] == 0 || picks[2] == 0 {
+		t.Errorf("picks without stats = %v, want both copies explored", picks)
+	}
+
+	if got := (thompsonSelector{rng: seededRand()}).Select(nil, stats); got != nil {
+		t.Errorf("Select(nil) = %v, want nil", got)
+	}
+}
+
+func TestNewAdCopySelector(t *testing.T) {
+	for _, strategy := range []string{"", SelectionRoundRobin, SelectionWeightedRandom, SelectionEpsilonGreedy, SelectionThompson} {
+		if _, err := NewAdCopySelector(strategy, 0.1); err != nil {
+			t.Errorf("NewAdCopySelector(%q) error = %v", strategy, err)
+		}
+	}
+	if _, err := NewAdCopySelector(SelectionEpsilonGreedy, 1.5); err == nil {
+		t.Error("NewAdCopySelector with epsilon 1.5 should fail")
+	}
+	if _, err := NewAdCopySelector("ucb", 0.1); err == nil {
+		t.Error("NewAdCopySelector with unknown strategy should fail")
+	}
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/zhoubofsy/x-bot/internal/application/dto"
+	"github.com/zhoubofsy/x-bot/internal/config"
+	"github.com/zhoubofsy/x-bot/internal/domain/repository"
+	"github.com/zhoubofsy/x-bot/internal/infrastructure/twitter"
+	"go.uber.org/zap"
+)
+
+type AdMetricsService interface {
+	// CollectReplyMetrics 拉取近期回复推文的互动数据（点赞、回复、转发、引用），供文案选择策略使用
+	CollectReplyMetrics(ctx context.Context) (*dto.ReplyMetricsResult, error)
+
+	// Performance 获取各广告文案的互动和短链接点击汇总
+	Performance(ctx context.Context) ([]*dto.AdCopyPerformance, error)
+}
+
+type adMetricsService struct {
+	adCopyRepo    repository.AdCopyRepository
+	replyLogRepo  repository.ReplyLogRepository
+	twitterClient twitter.Client
+	linkRepo      repository.TrackedLinkRepository
+	cfg           *config.AdSelectionConfig
+	logger        *zap.Logger
+}
+
+func NewAdMetricsService(
+	adCopyRepo repository.AdCopyRepository,
+	replyLogRepo repository.ReplyLogRepository,
+	twitterClient twitter.Client,
+	linkRepo repository.TrackedLinkRepository,
+	cfg *config.AdSelectionConfig,
+	logger *zap.Logger,
+) AdMetricsService {
+	return &adMetricsService{
+		adCopyRepo:    adCopyRepo,
+		replyLogRepo:  replyLogRepo,
+		twitterClient: twitterClient,
+		linkRepo:      linkRepo,
+		cfg:           cfg,
+		logger:        logger,
+	}
+}
+
+func (s *adMetricsService) CollectReplyMetrics(ctx context.Context) (*dto.ReplyMetricsResult, error) {
+	window := s.cfg.MetricsWindow
+	if window <= 0 {
+		window = 7 * 24 * time.Hour
+	}
+	batch := s.cfg.MetricsBatch
+	if batch <= 0 {
+		batch = 300
+	}
+
+	logs, err := s.replyLogRepo.GetRepliesForMetrics(ctx, time.Now().Add(-window), batch)
+	if err != nil {
+		return nil, err
+	}
+	result := &dto.ReplyMetricsResult{TotalCount: len(logs)}
+	if len(logs) == 0 {
+		return result, nil
+	}
+
+	ids := make([]string, 0, len(logs))
+	for _, log := range logs {
+		ids = append(ids, log.ReplyTweetID)
+	}
+	tweets, err := s.twitterClient.GetTweetsByIDs(ctx, ids)
+	if err != nil {
+		s.logger.Error("获取回复推文互动数据失败", zap.Error(err))
+		return nil, err
+	}
+
+	byID := make(map[string]twitter.Tweet, len(tweets))
+	for _, tweet := range tweets {
+		byID[tweet.ID] = tweet
+	}
+
+	now := time.Now()
+	for _, log := range logs {
+		metrics := repository.ReplyMetrics{UpdatedAt: now}
+		tweet, ok := byID[log.ReplyTweetID]
+		if !ok {
+			// 回复已被删除或不可见，保留已有数据，只更新拉取时间
+			result.MissingCount++
+			metrics.LikeCount = log.ReplyLikeCount
+			metrics.ReplyCount = log.ReplyReplyCount
+			metrics.RetweetCount = log.ReplyRetweetCount
+			metrics.QuoteCount = log.ReplyQuoteCount
+		} else if tweet.PublicMetrics != nil {
+			metrics.LikeCount = tweet.PublicMetrics.LikeCount
+			metrics.ReplyCount = tweet.PublicMetrics.ReplyCount
+			metrics.RetweetCount = tweet.PublicMetrics.RetweetCount
+			metrics.QuoteCount = tweet.PublicMetrics.QuoteCount
+		}
+
+		if err := s.replyLogRepo.UpdateReplyMetrics(ctx, log.ID, metrics); err != nil {
+			result.Errors = append(result.Errors, err.Error())
+			continue
+		}
+		result.UpdatedCount++
+	}
+
+	s.logger.Info("回复互动数据更新完成",
+		zap.Int("total", result.TotalCount),
+		zap.Int("updated", result.UpdatedCount),
+		zap.Int("missing", result.MissingCount),
+	)
+
+	return result, nil
+}
+
+func (s *adMetricsService) Performance(ctx context.Context) ([]*dto.AdCopyPerformance, error) {
+	adCopies, err := s.adCopyRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := s.replyLogRepo.GetAdCopyEngagement(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	clicks, err := s.linkRepo.GetAdCopyClicks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	performance := make([]*dto.AdCopyPerformance, 0, len(adCopies))
+	for _, adCopy := range adCopies {
+		p := &dto.AdCopyPerformance{
+			Name:     adCopy.Name,
+			Category: adCopy.Category,
+			UseCount: adCopy.UseCount,
+		}
+		if e := stats[adCopy.ID]; e != nil {
+			p.AdCopyEngagement = *e
+			p.EngagementRate = e.EngagementRate()
+		}
+		if cl := clicks[adCopy.ID]; cl != nil {
+			p.TrackedReplies = cl.TrackedReplies
+			p.ClickedReplies = cl.ClickedReplies
+			p.Clicks = cl.Clicks
+			p.CTR = cl.CTR()
+		}
+		p.AdCopyEngagement.AdCopyID = adCopy.ID
+		performance = append(performance, p)
+	}
+	return performance, nil
+}
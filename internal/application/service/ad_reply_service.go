@@ -2,54 +2,221 @@ package service
 
 import (
 	"context"
+	"math"
+	"math/rand/v2"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/zhoubofsy/x-bot/internal/config"
 	"github.com/zhoubofsy/x-bot/internal/domain/entity"
 	"github.com/zhoubofsy/x-bot/internal/domain/repository"
+	"github.com/zhoubofsy/x-bot/internal/infrastructure/llm"
 	"github.com/zhoubofsy/x-bot/internal/infrastructure/twitter"
+	"github.com/zhoubofsy/x-bot/pkg/adtemplate"
+	apperrors "github.com/zhoubofsy/x-bot/pkg/errors"
+	"github.com/zhoubofsy/x-bot/pkg/spintax"
+	"github.com/zhoubofsy/x-bot/pkg/tweettext"
 	"go.uber.org/zap"
 )
 
 type AdReplyService interface {
-	// GetNextAdCopy 获取下一个可用的广告文案
-	GetNextAdCopy(ctx context.Context, category string) (*entity.AdCopy, error)
+	// GetNextAdCopy 获取下一个符合条件的广告文案，优先选择有对应语言变体的文案，
+	// 再按类别配置的选择策略（轮换、加权随机、epsilon-greedy、Thompson 采样）选出一个
+	GetNextAdCopy(ctx context.Context, query repository.AdCopyQuery) (*entity.AdCopy, error)
 
-	// ReplyWithAd 在推文下回复广告
-	ReplyWithAd(ctx context.Context, tweetID string, adCopy *entity.AdCopy) (*twitter.Tweet, error)
+	// RenderContent 选取与语言匹配的文案变体，随机展开 spintax、选择 emoji 和话题标签，
+	// 并使用推文、作者、活动等变量渲染模板
+	RenderContent(adCopy *entity.AdCopy, language string, data adtemplate.Data) (string, error)
+
+	// RenderVariant 与 RenderContent 相同，但会生成多个随机变体，选择与最近回复的编辑距离足够大的一个，
+	// 避免重复内容被 Twitter 拒绝
+	RenderVariant(ctx context.Context, adCopy *entity.AdCopy, language string, data adtemplate.Data) (string, error)
+
+	// ReplyWithContent 使用已渲染的文本（RenderVariant 的结果或人工审核后编辑过的文案）回复推文，并记录所用广告文案的使用次数
+	ReplyWithContent(ctx context.Context, tweetID string, content string, adCopyID *int) (*twitter.Tweet, error)
+
+	// DeactivateExpired 停用已过结束时间的文案
+	DeactivateExpired(ctx context.Context) (int64, error)
 }
 
 type adReplyService struct {
 	adCopyRepo    repository.AdCopyRepository
+	replyLogRepo  repository.ReplyLogRepository
 	twitterClient twitter.Client
+	selectors     map[string]AdCopySelector // 按类别配置的选择策略
+	defaultSel    AdCopySelector
+	variation     *config.VariationConfig
 	logger        *zap.Logger
 }
 
 func NewAdReplyService(
 	adCopyRepo repository.AdCopyRepository,
+	replyLogRepo repository.ReplyLogRepository,
 	twitterClient twitter.Client,
+	cfg *config.AdSelectionConfig,
+	variation *config.VariationConfig,
 	logger *zap.Logger,
 ) AdReplyService {
-	return &adReplyService{
+	s := &adReplyService{
 		adCopyRepo:    adCopyRepo,
+		replyLogRepo:  replyLogRepo,
 		twitterClient: twitterClient,
+		selectors:     make(map[string]AdCopySelector, len(cfg.Categories)),
+		variation:     variation,
 		logger:        logger,
 	}
+
+	s.defaultSel = s.newSelector(cfg.Strategy, cfg.Epsilon)
+	for category, strategy := range cfg.Categories {
+		s.selectors[category] = s.newSelector(strategy, cfg.Epsilon)
+	}
+
+	return s
+}
+
+// newSelector 创建选择策略，配置无效时回退为轮换
+func (s *adReplyService) newSelector(strategy string, epsilon float64) AdCopySelector {
+	selector, err := NewAdCopySelector(strategy, epsilon)
+	if err != nil {
+		s.logger.Warn("文案选择策略配置无效，使用 round_robin", zap.String("strategy", strategy), zap.Error(err))
+		return roundRobinSelector{rng: globalRand{}}
+	}
+	return selector
+}
+
+// selectorFor 只限定一个类别时使用该类别的策略，否则使用默认策略
+func (s *adReplyService) selectorFor(categories []string) AdCopySelector {
+	if len(categories) == 1 {
+		if selector, ok := s.selectors[categories[0]]; ok {
+			return selector
+		}
+	}
+	return s.defaultSel
 }
 
-func (s *adReplyService) GetNextAdCopy(ctx context.Context, category string) (*entity.AdCopy, error) {
-	adCopy, err := s.adCopyRepo.GetNextAvailable(ctx, category)
+func (s *adReplyService) GetNextAdCopy(ctx context.Context, query repository.AdCopyQuery) (*entity.AdCopy, error) {
+	candidates, err := s.adCopyRepo.GetCandidates(ctx, query)
 	if err != nil {
 		s.logger.Error("获取广告文案失败",
-			zap.String("category", category),
+			zap.Strings("categories", query.Categories),
+			zap.Ints("ad_copy_ids", query.IDs),
+			zap.String("language", query.Language),
 			zap.Error(err),
 		)
 		return nil, err
 	}
+	if len(candidates) == 0 {
+		return nil, apperrors.Wrap(apperrors.ErrNotFound, "NO_AD_COPY", "没有可用的广告文案")
+	}
+
+	selector := s.selectorFor(query.Categories)
+
+	var stats map[int]*repository.AdCopyEngagement
+	if selector.UsesEngagement() {
+		ids := make([]int, 0, len(candidates))
+		for _, c := range candidates {
+			ids = append(ids, c.ID)
+		}
+		// 互动数据获取失败时不影响回复，按没有数据处理
+		if stats, err = s.replyLogRepo.GetAdCopyEngagement(ctx, ids); err != nil {
+			s.logger.Warn("获取文案互动数据失败", zap.Error(err))
+		}
+	}
 
-	return adCopy, nil
+	return selector.Select(candidates, stats), nil
 }
 
-func (s *adReplyService) ReplyWithAd(ctx context.Context, tweetID string, adCopy *entity.AdCopy) (*twitter.Tweet, error) {
-	reply, err := s.twitterClient.ReplyToTweet(ctx, tweetID, adCopy.Content)
+func (s *adReplyService) RenderContent(adCopy *entity.AdCopy, language string, data adtemplate.Data) (string, error) {
+	if data.TrackingLink == "" {
+		data.TrackingLink = adCopy.LinkURL
+	}
+	data.Emoji = pickOne(adCopy.Emojis)
+	data.Hashtag = pickOne(adCopy.Hashtags)
+
+	content, err := adtemplate.Render(spintax.Spin(adCopy.ContentFor(language)), data)
+	if err != nil {
+		s.logger.Error("渲染广告文案失败",
+			zap.Int("ad_copy_id", adCopy.ID),
+			zap.String("language", language),
+			zap.Error(err),
+		)
+		return "", err
+	}
+	if err := validateReplyLength(content); err != nil {
+		s.logger.Error("渲染后的广告文案超出推文长度限制",
+			zap.Int("ad_copy_id", adCopy.ID),
+			zap.String("language", language),
+			zap.Int("weighted_length", tweettext.WeightedLength(content)),
+		)
+		return "", err
+	}
+	return content, nil
+}
+
+func (s *adReplyService) RenderVariant(ctx context.Context, adCopy *entity.AdCopy, language string, data adtemplate.Data) (string, error) {
+	if s.variation.History <= 0 || s.variation.MinDistance <= 0 || !hasVariation(adCopy, language) {
+		return s.RenderContent(adCopy, language, data)
+	}
+
+	// 读取失败时不影响回复，按没有历史回复处理
+	recent, err := s.replyLogRepo.GetRecentReplyContents(ctx, s.variation.History)
+	if err != nil {
+		s.logger.Warn("获取最近回复内容失败", zap.Error(err))
+	}
+	for i := range recent {
+		recent[i] = comparableText(recent[i])
+	}
+
+	attempts := s.variation.MaxAttempts
+	if attempts <= 0 {
+		attempts = 10
+	}
+	best, bestDistance := "", -1
+	var lastErr error
+	for i := 0; i < attempts; i++ {
+		content, err := s.RenderContent(adCopy, language, data)
+		if err != nil {
+			// 超长的变体换一个重试，模板错误每次都会出现
+			if apperrors.Is(err, apperrors.ErrInvalidInput) {
+				lastErr = err
+				continue
+			}
+			return "", err
+		}
+		distance := minEditDistance(comparableText(content), recent)
+		if distance >= s.variation.MinDistance {
+			return content, nil
+		}
+		if distance > bestDistance {
+			best, bestDistance = content, distance
+		}
+	}
+	if best == "" {
+		return "", lastErr
+	}
+
+	s.logger.Warn("未能生成与最近回复差异足够大的文案变体，使用差异最大的变体",
+		zap.Int("ad_copy_id", adCopy.ID),
+		zap.Int("distance", bestDistance),
+		zap.Int("min_distance", s.variation.MinDistance),
+	)
+	return best, nil
+}
+
+func (s *adReplyService) ReplyWithContent(ctx context.Context, tweetID string, content string, adCopyID *int) (*twitter.Tweet, error) {
+	// 发送前再次校验，避免人工修改或历史数据超长导致 Twitter 拒绝
+	if err := validateReplyLength(content); err != nil {
+		s.logger.Error("回复内容超出推文长度限制",
+			zap.String("tweet_id", tweetID),
+			zap.Int("weighted_length", tweettext.WeightedLength(content)),
+		)
+		return nil, err
+	}
+
+	reply, err := s.twitterClient.ReplyToTweet(ctx, tweetID, content)
 	if err != nil {
 		s.logger.Error("回复推文失败",
 			zap.String("tweet_id", tweetID),
@@ -58,10 +225,18 @@ func (s *adReplyService) ReplyWithAd(ctx context.Context, tweetID string, adCopy
 		return nil, err
 	}
 
+	if adCopyID == nil {
+		s.logger.Info("回复成功",
+			zap.String("tweet_id", tweetID),
+			zap.String("reply_id", reply.ID),
+		)
+		return reply, nil
+	}
+
 	// 增加使用次数
-	if err := s.adCopyRepo.IncrementUseCount(ctx, adCopy.ID); err != nil {
+	if err := s.adCopyRepo.IncrementUseCount(ctx, *adCopyID); err != nil {
 		s.logger.Warn("更新广告使用次数失败",
-			zap.Int("ad_copy_id", adCopy.ID),
+			zap.Int("ad_copy_id", *adCopyID),
 			zap.Error(err),
 		)
 	}
@@ -69,9 +244,139 @@ func (s *adReplyService) ReplyWithAd(ctx context.Context, tweetID string, adCopy
 	s.logger.Info("广告回复成功",
 		zap.String("tweet_id", tweetID),
 		zap.String("reply_id", reply.ID),
-		zap.Int("ad_copy_id", adCopy.ID),
+		zap.Int("ad_copy_id", *adCopyID),
 	)
 
 	return reply, nil
 }
 
+func (s *adReplyService) DeactivateExpired(ctx context.Context) (int64, error) {
+	count, err := s.adCopyRepo.DeactivateExpired(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	if count > 0 {
+		s.logger.Info("已停用过期的广告文案", zap.Int64("count", count))
+	}
+	return count, nil
+}
+
+// validateReplyLength 按 Twitter 加权字符数校验回复内容
+func validateReplyLength(content string) error {
+	if err := tweettext.Validate(content); err != nil {
+		return apperrors.Wrap(apperrors.ErrInvalidInput, "TWEET_TOO_LONG", err.Error())
+	}
+	return nil
+}
+
+// hasVariation 文案是否包含 spintax 或多个 emoji、话题标签，没有时每次渲染的结果只随模板变量变化
+func hasVariation(adCopy *entity.AdCopy, language string) bool {
+	if len(adCopy.Emojis) > 1 || len(adCopy.Hashtags) > 1 {
+		return true
+	}
+	parsed, err := spintax.Parse(adCopy.ContentFor(language))
+	return err == nil && parsed.Count() > 1
+}
+
+// pickOne 随机选择一项，集合为空时返回空字符串
+func pickOne(items []string) string {
+	if len(items) == 0 {
+		return ""
+	}
+	return items[rand.IntN(len(items))]
+}
+
+// comparableText 比较回复差异时忽略链接：启用链接追踪后每条回复的短链接都不同
+func comparableText(text string) string {
+	var sb strings.Builder
+	last := 0
+	for _, loc := range tweettext.FindURLs(text) {
+		sb.WriteString(text[last:loc[0]])
+		last = loc[1]
+	}
+	sb.WriteString(text[last:])
+	return strings.TrimSpace(sb.String())
+}
+
+// minEditDistance 与最近回复的最小编辑距离，没有历史回复时视为差异足够大
+func minEditDistance(text string, recent []string) int {
+	distance := math.MaxInt
+	for _, r := range recent {
+		distance = min(distance, tweettext.EditDistance(text, r))
+	}
+	return distance
+}
+
+// buildTemplateData 根据推文、作者和 LLM 检测结果构建文案模板变量；
+// 作者优先使用推文展开的作者信息，没有时使用监控用户信息
+func buildTemplateData(tweet twitter.Tweet, user *entity.FollowedUser, llmResponse string) adtemplate.Data {
+	data := adtemplate.Data{
+		Author: adtemplate.Author{ID: tweet.AuthorID},
+		Tweet: adtemplate.Tweet{
+			ID:   tweet.ID,
+			Text: tweet.Text,
+			Lang: tweet.Lang,
+		},
+	}
+
+	switch {
+	case tweet.Author != nil:
+		data.Author.Username = tweet.Author.Username
+		data.Author.DisplayName = tweet.Author.Name
+	case user != nil:
+		data.Author.Username = user.Username
+		data.Author.DisplayName = user.DisplayName
+	}
+
+	if data.Author.Username != "" {
+		data.Tweet.URL = "https://x.com/" + data.Author.Username + "/status/" + tweet.ID
+	} else if tweet.ID != "" {
+		data.Tweet.URL = "https://x.com/i/web/status/" + tweet.ID
+	}
+
+	if detection := llm.ParseHackathonDetection(llmResponse); detection != nil {
+		data.Event = adtemplate.Event{
+			Name: sanitizeEventText(detection.EventName, maxEventNameLength),
+			URL:  sanitizeEventURL(detection.EventURL, tweet.Text),
+		}
+		data.Deadline = sanitizeEventText(detection.Deadline, maxDeadlineLength)
+	}
+
+	return data
+}
+
+// LLM 提取的活动字段长度上限（字符数），超出时视为不可信并丢弃
+const (
+	maxEventNameLength = 60
+	maxDeadlineLength  = 40
+)
+
+// sanitizeEventText 清理 LLM 从推文中提取的文本字段：合并空白和控制字符，
+// 过长或包含链接、@提及的内容直接丢弃，避免被推文内容诱导在回复中插入链接或提及他人
+func sanitizeEventText(text string, maxLength int) string {
+	text = strings.Join(strings.FieldsFunc(text, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsControl(r)
+	}), " ")
+	if text == "" || utf8.RuneCountInString(text) > maxLength {
+		return ""
+	}
+	if strings.Contains(text, "@") || len(tweettext.FindURLs(text)) > 0 {
+		return ""
+	}
+	return text
+}
+
+// sanitizeEventURL 只保留推文原文中出现过的 http(s) 链接，LLM 编造或改写的链接直接丢弃
+func sanitizeEventURL(rawURL, tweetText string) string {
+	rawURL = strings.TrimSpace(rawURL)
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ""
+	}
+	for _, loc := range tweettext.FindURLs(tweetText) {
+		if tweetText[loc[0]:loc[1]] == rawURL {
+			return rawURL
+		}
+	}
+	return ""
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zhoubofsy/x-bot/internal/application/dto"
+	"github.com/zhoubofsy/x-bot/internal/config"
+	"github.com/zhoubofsy/x-bot/internal/domain/entity"
+	"github.com/zhoubofsy/x-bot/internal/domain/repository"
+	apperrors "github.com/zhoubofsy/x-bot/pkg/errors"
+	"go.uber.org/zap"
+)
+
+type ApprovalService interface {
+	// ListPending 获取待审核的回复
+	ListPending(ctx context.Context, limit int) ([]*entity.ReplyLog, error)
+
+	// Edit 修改待审核回复的文案
+	Edit(ctx context.Context, id int, content string) (*entity.ReplyLog, error)
+
+	// Approve 批准回复（可同时修改文案），批准后由后台发送
+	Approve(ctx context.Context, id int, content *string) (*entity.ReplyLog, error)
+
+	// Reject 拒绝回复
+	Reject(ctx context.Context, id int, reason string) (*entity.ReplyLog, error)
+
+	// SendNextApproved 发送最早批准的一条回复，遵守每日回复上限
+	// 队列为空或已达上限时返回 nil
+	SendNextApproved(ctx context.Context) (*dto.ApprovalSendResult, error)
+}
+
+type approvalService struct {
+	replyLogRepo   repository.ReplyLogRepository
+	adReplyService AdReplyService
+	linkTracker    LinkTracker
+	cfg            *config.WorkflowConfig
+	logger         *zap.Logger
+}
+
+func NewApprovalService(
+	replyLogRepo repository.ReplyLogRepository,
+	adReplyService AdReplyService,
+	linkTracker LinkTracker,
+	cfg *config.WorkflowConfig,
+	logger *zap.Logger,
+) ApprovalService {
+	return &approvalService{
+		replyLogRepo:   replyLogRepo,
+		adReplyService: adReplyService,
+		linkTracker:    linkTracker,
+		cfg:            cfg,
+		logger:         logger,
+	}
+}
+
+func (s *approvalService) ListPending(ctx context.Context, limit int) ([]*entity.ReplyLog, error) {
+	return s.replyLogRepo.GetOldestByStatus(ctx, entity.ReplyStatusPending, limit)
+}
+
+func (s *approvalService) Edit(ctx context.Context, id int, content string) (*entity.ReplyLog, error) {
+	log, err := s.getPending(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(content) == "" {
+		return nil, apperrors.Wrap(apperrors.ErrInvalidInput, "INVALID_CONTENT", "回复内容不能为空")
+	}
+	if err := validateReplyLength(content); err != nil {
+		return nil, err
+	}
+	log.ReplyContent = content
+
+	if err := s.replyLogRepo.Update(ctx, log); err != nil {
+		return nil, err
+	}
+	return log, nil
+}
+
+func (s *approvalService) Approve(ctx context.Context, id int, content *string) (*entity.ReplyLog, error) {
+	log, err := s.getPending(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if content != nil {
+		if strings.TrimSpace(*content) == "" {
+			return nil, apperrors.Wrap(apperrors.ErrInvalidInput, "INVALID_CONTENT", "回复内容不能为空")
+		}
+		if err := validateReplyLength(*content); err != nil {
+			return nil, err
+		}
+		log.ReplyContent = *content
+	}
+
+	now := time.Now()
+	log.Status = entity.ReplyStatusApproved
+	log.ReviewedAt = &now
+
+	if err := s.replyLogRepo.Update(ctx, log); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("回复已批准", zap.Int("reply_log_id", id), zap.String("tweet_id", log.TweetID))
+	return log, nil
+}
+
+func (s *approvalService) Reject(ctx context.Context, id int, reason string) (*entity.ReplyLog, error) {
+	log, err := s.getPending(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	log.Status = entity.ReplyStatusRejected
+	log.SkipReason = reason
+	log.ReviewedAt = &now
+
+	if err := s.replyLogRepo.Update(ctx, log); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("回复已拒绝", zap.Int("reply_log_id", id), zap.String("reason", reason))
+	return log, nil
+}
+
+func (s *approvalService) SendNextApproved(ctx context.Context) (*dto.ApprovalSendResult, error) {
+	todayCount, err := s.replyLogRepo.GetTodaySuccessCount(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if todayCount >= int64(s.cfg.MaxDailyReplies) {
+		s.logger.Debug("已达到今日回复上限，暂停发送审核队列")
+		return nil, nil
+	}
+
+	logs, err := s.replyLogRepo.GetOldestByStatus(ctx, entity.ReplyStatusApproved, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(logs) == 0 {
+		return nil, nil
+	}
+
+	// 先将回复领取为 sending 再发送，并发的定时任务或手动发送领取失败时直接返回，避免重复发送
+	log := logs[0]
+	claimed, err := s.replyLogRepo.UpdateStatusIf(ctx, log.ID, entity.ReplyStatusApproved, entity.ReplyStatusSending)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		s.logger.Debug("审核回复已被其他任务领取", zap.Int("reply_log_id", log.ID))
+		return nil, nil
+	}
+	log.Status = entity.ReplyStatusSending
+	result := &dto.ApprovalSendResult{ReplyLogID: log.ID, TweetID: log.TweetID}
+
+	// 审核通过后才生成短链接，审核期间编辑或拒绝的回复不会留下无用的短链接
+	log.ReplyContent = s.linkTracker.Rewrite(ctx, log, log.AdCopy)
+	reply, err := s.adReplyService.ReplyWithContent(ctx, log.TweetID, log.ReplyContent, log.AdCopyID)
+	applyReplyOutcome(log, reply, err, s.cfg.Retry, time.Now())
+	if err != nil {
+		result.Error = err.Error()
+	} else {
+		result.ReplyTweetID = reply.ID
+	}
+
+	if err := s.replyLogRepo.Update(ctx, log); err != nil {
+		s.logger.Error("更新审核回复状态失败", zap.Int("reply_log_id", log.ID), zap.Error(err))
+		return result, err
+	}
+
+	return result, nil
+}
+
+// getPending 获取待审核的回复日志，状态不是 pending 时返回错误
+func (s *approvalService) getPending(ctx context.Context, id int) (*entity.ReplyLog, error) {
+	log, err := s.replyLogRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.ErrNotFound, "REPLY_LOG_NOT_FOUND", "回复记录不存在")
+	}
+
+	if log.Status != entity.ReplyStatusPending {
+		return nil, apperrors.Wrap(apperrors.ErrInvalidInput, "INVALID_STATUS",
+			fmt.Sprintf("当前状态为 %s，只能审核 pending 状态的回复", log.Status))
+	}
+	return log, nil
+}
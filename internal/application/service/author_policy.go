@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zhoubofsy/x-bot/internal/config"
+	"github.com/zhoubofsy/x-bot/internal/domain/entity"
+	"github.com/zhoubofsy/x-bot/internal/domain/repository"
+)
+
+type AuthorPolicyChecker interface {
+	// Check 检查是否允许再次回复该作者（冷却时间、每周回复上限）
+	// user 为空时使用全局默认策略
+	Check(ctx context.Context, authorID string, user *entity.FollowedUser) (ok bool, reason string, err error)
+}
+
+type authorPolicyChecker struct {
+	replyLogRepo repository.ReplyLogRepository
+	cfg          *config.AuthorPolicyConfig
+}
+
+func NewAuthorPolicyChecker(
+	replyLogRepo repository.ReplyLogRepository,
+	cfg *config.AuthorPolicyConfig,
+) AuthorPolicyChecker {
+	return &authorPolicyChecker{
+		replyLogRepo: replyLogRepo,
+		cfg:          cfg,
+	}
+}
+
+func (c *authorPolicyChecker) Check(ctx context.Context, authorID string, user *entity.FollowedUser) (bool, string, error) {
+	cooldown, maxPerWeek := c.resolve(user)
+	now := time.Now()
+
+	if cooldown > 0 {
+		last, err := c.replyLogRepo.GetLastReplyTimeByAuthor(ctx, authorID)
+		if err != nil {
+			return false, "", err
+		}
+		if last != nil && now.Sub(*last) < cooldown {
+			return false, fmt.Sprintf("距上次回复该作者不足 %s", cooldown), nil
+		}
+	}
+
+	if maxPerWeek > 0 {
+		count, err := c.replyLogRepo.CountRepliesByAuthorSince(ctx, authorID, now.Add(-7*24*time.Hour))
+		if err != nil {
+			return false, "", err
+		}
+		if count >= int64(maxPerWeek) {
+			return false, fmt.Sprintf("本周已回复该作者 %d 次，达到上限 %d", count, maxPerWeek), nil
+		}
+	}
+
+	return true, "", nil
+}
+
+// resolve 用户级设置优先，未设置时使用全局默认值
+func (c *authorPolicyChecker) resolve(user *entity.FollowedUser) (time.Duration, int) {
+	cooldown := c.cfg.Cooldown
+	maxPerWeek := c.cfg.MaxRepliesPerWeek
+
+	if user != nil {
+		if user.ReplyCooldownSeconds != nil {
+			cooldown = time.Duration(*user.ReplyCooldownSeconds) * time.Second
+		}
+		if user.MaxRepliesPerWeek != nil {
+			maxPerWeek = *user.MaxRepliesPerWeek
+		}
+	}
+
+	return cooldown, maxPerWeek
+}
@@ -0,0 +1,393 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zhoubofsy/x-bot/internal/application/dto"
+	"github.com/zhoubofsy/x-bot/internal/domain/entity"
+)
+
+// 批量导入导出支持的文件格式
+const (
+	BulkFormatCSV  = "csv"
+	BulkFormatJSON = "json"
+)
+
+// ValidBulkFormat 判断文件格式是否支持
+func ValidBulkFormat(format string) bool {
+	return format == BulkFormatCSV || format == BulkFormatJSON
+}
+
+// BulkFormatOf 根据文件扩展名判断格式，无法判断时为空
+func BulkFormatOf(filename string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return BulkFormatCSV
+	case ".json":
+		return BulkFormatJSON
+	}
+	return ""
+}
+
+// CSV 列：多值字段（emojis、hashtags、weekdays、groups）以分号分隔，文案变体为 JSON 数组
+var (
+	adCopyColumns = []string{
+		"name", "content", "link_url", "language", "category", "campaign_id", "priority", "is_active", "variants",
+		"emojis", "hashtags", "starts_at", "ends_at", "max_daily_uses", "max_total_uses", "weekdays", "start_hour", "end_hour", "timezone",
+	}
+	userColumns = []string{
+		"twitter_user_id", "username", "display_name", "is_active", "reply_cooldown_seconds", "max_replies_per_week", "groups",
+	}
+)
+
+// decodeRecords 解析导入文件；CSV 中单行字段格式错误时错误放在 rowErrs 中对应位置
+func decodeRecords[T any](format string, r io.Reader, columns []string, fromCSV func(map[string]string) (*T, error)) ([]*T, []error, error) {
+	if format == BulkFormatJSON {
+		var records []*T
+		if err := json.NewDecoder(r).Decode(&records); err != nil {
+			return nil, nil, fmt.Errorf("解析 JSON 失败: %w", err)
+		}
+		return records, make([]error, len(records)), nil
+	}
+
+	reader := csv.NewReader(skipBOM(r))
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("解析 CSV 失败: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil, fmt.Errorf("CSV 缺少表头")
+	}
+
+	header := make([]string, len(rows[0]))
+	present := make(map[string]bool, len(header))
+	for i, name := range rows[0] {
+		header[i] = strings.ToLower(strings.TrimSpace(name))
+		present[header[i]] = true
+	}
+	// 前两列为必填的匹配字段
+	for _, name := range columns[:2] {
+		if !present[name] {
+			return nil, nil, fmt.Errorf("CSV 表头缺少 %s 列", name)
+		}
+	}
+
+	records := make([]*T, 0, len(rows)-1)
+	rowErrs := make([]error, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		values := make(map[string]string, len(header))
+		for i, value := range row {
+			if i < len(header) {
+				values[header[i]] = strings.TrimSpace(value)
+			}
+		}
+		record, err := fromCSV(values)
+		records = append(records, record)
+		rowErrs = append(rowErrs, err)
+	}
+	return records, rowErrs, nil
+}
+
+// encodeRecords 按格式输出记录，JSON 为带缩进的数组
+func encodeRecords[T any](format string, w io.Writer, records []*T, columns []string, toCSV func(*T) []string) error {
+	if format == BulkFormatJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		if records == nil {
+			records = []*T{}
+		}
+		return encoder.Encode(records)
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(columns); err != nil {
+		return err
+	}
+	for _, record := range records {
+		if err := writer.Write(toCSV(record)); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// skipBOM 去掉 Excel 导出的 CSV 开头的 UTF-8 BOM
+func skipBOM(r io.Reader) io.Reader {
+	br := bufio.NewReader(r)
+	if prefix, err := br.Peek(3); err == nil && bytes.Equal(prefix, []byte{0xEF, 0xBB, 0xBF}) {
+		br.Discard(3)
+	}
+	return br
+}
+
+func adCopyToCSV(record *dto.AdCopyRecord) []string {
+	variants := ""
+	if len(record.Variants) > 0 {
+		data, _ := json.Marshal(record.Variants)
+		variants = string(data)
+	}
+	return []string{
+		record.Name,
+		record.Content,
+		record.LinkURL,
+		record.Language,
+		record.Category,
+		formatIntPtr(record.CampaignID),
+		strconv.Itoa(record.Priority),
+		formatBoolPtr(record.IsActive),
+		variants,
+		strings.Join(record.Emojis, ";"),
+		strings.Join(record.Hashtags, ";"),
+		formatTimePtr(record.StartsAt),
+		formatTimePtr(record.EndsAt),
+		strconv.Itoa(record.MaxDailyUses),
+		strconv.Itoa(record.MaxTotalUses),
+		joinInts(record.Weekdays),
+		formatIntPtr(record.StartHour),
+		formatIntPtr(record.EndHour),
+		record.Timezone,
+	}
+}
+
+func adCopyFromCSV(values map[string]string) (*dto.AdCopyRecord, error) {
+	record := &dto.AdCopyRecord{
+		Name:     values["name"],
+		Content:  values["content"],
+		LinkURL:  values["link_url"],
+		Language: values["language"],
+		Category: values["category"],
+		Emojis:   splitList(values["emojis"]),
+		Hashtags: splitList(values["hashtags"]),
+		Timezone: values["timezone"],
+	}
+
+	var err error
+	if record.CampaignID, err = parseIntPtr(values, "campaign_id"); err != nil {
+		return record, err
+	}
+	if record.Priority, err = parseInt(values, "priority"); err != nil {
+		return record, err
+	}
+	if record.IsActive, err = parseBoolPtr(values, "is_active"); err != nil {
+		return record, err
+	}
+	if v := values["variants"]; v != "" {
+		if err := json.Unmarshal([]byte(v), &record.Variants); err != nil {
+			return record, fmt.Errorf("variants 应为 JSON 数组: %w", err)
+		}
+	}
+	if record.StartsAt, err = parseTimePtr(values, "starts_at"); err != nil {
+		return record, err
+	}
+	if record.EndsAt, err = parseTimePtr(values, "ends_at"); err != nil {
+		return record, err
+	}
+	if record.MaxDailyUses, err = parseInt(values, "max_daily_uses"); err != nil {
+		return record, err
+	}
+	if record.MaxTotalUses, err = parseInt(values, "max_total_uses"); err != nil {
+		return record, err
+	}
+	if record.Weekdays, err = splitInts(values, "weekdays"); err != nil {
+		return record, err
+	}
+	if record.StartHour, err = parseIntPtr(values, "start_hour"); err != nil {
+		return record, err
+	}
+	if record.EndHour, err = parseIntPtr(values, "end_hour"); err != nil {
+		return record, err
+	}
+	return record, nil
+}
+
+func userToCSV(record *dto.UserRecord) []string {
+	return []string{
+		record.TwitterUserID,
+		record.Username,
+		record.DisplayName,
+		formatBoolPtr(record.IsActive),
+		formatIntPtr(record.ReplyCooldownSeconds),
+		formatIntPtr(record.MaxRepliesPerWeek),
+		strings.Join(record.Groups, ";"),
+	}
+}
+
+func userFromCSV(values map[string]string) (*dto.UserRecord, error) {
+	record := &dto.UserRecord{
+		TwitterUserID: values["twitter_user_id"],
+		Username:      values["username"],
+		DisplayName:   values["display_name"],
+	}
+	// 有 groups 列时按列中的分组替换成员关系，空值表示移出所有分组
+	if value, ok := values["groups"]; ok {
+		record.Groups = append([]string{}, splitList(value)...)
+	}
+
+	var err error
+	if record.IsActive, err = parseBoolPtr(values, "is_active"); err != nil {
+		return record, err
+	}
+	if record.ReplyCooldownSeconds, err = parseIntPtr(values, "reply_cooldown_seconds"); err != nil {
+		return record, err
+	}
+	if record.MaxRepliesPerWeek, err = parseIntPtr(values, "max_replies_per_week"); err != nil {
+		return record, err
+	}
+	return record, nil
+}
+
+// adCopyRecordOf 将文案转换为导出记录
+func adCopyRecordOf(adCopy *entity.AdCopy) *dto.AdCopyRecord {
+	isActive := adCopy.IsActive
+	record := &dto.AdCopyRecord{
+		Name:         adCopy.Name,
+		Content:      adCopy.Content,
+		LinkURL:      adCopy.LinkURL,
+		Language:     adCopy.Language,
+		Category:     adCopy.Category,
+		CampaignID:   adCopy.CampaignID,
+		Priority:     adCopy.Priority,
+		IsActive:     &isActive,
+		Emojis:       adCopy.Emojis,
+		Hashtags:     adCopy.Hashtags,
+		StartsAt:     adCopy.StartsAt,
+		EndsAt:       adCopy.EndsAt,
+		MaxDailyUses: adCopy.MaxDailyUses,
+		MaxTotalUses: adCopy.MaxTotalUses,
+		Weekdays:     adCopy.Weekdays,
+		StartHour:    adCopy.StartHour,
+		EndHour:      adCopy.EndHour,
+		Timezone:     adCopy.Timezone,
+	}
+	for _, v := range adCopy.Variants {
+		record.Variants = append(record.Variants, entity.AdCopyVariantInput{Language: v.Language, Content: v.Content})
+	}
+	return record
+}
+
+// userRecordOf 将用户转换为导出记录
+func userRecordOf(user *entity.FollowedUser) *dto.UserRecord {
+	isActive := user.IsActive
+	record := &dto.UserRecord{
+		TwitterUserID:        user.TwitterUserID,
+		Username:             user.Username,
+		DisplayName:          user.DisplayName,
+		IsActive:             &isActive,
+		ReplyCooldownSeconds: user.ReplyCooldownSeconds,
+		MaxRepliesPerWeek:    user.MaxRepliesPerWeek,
+	}
+	for _, group := range user.Groups {
+		record.Groups = append(record.Groups, group.Name)
+	}
+	return record
+}
+
+func parseInt(values map[string]string, column string) (int, error) {
+	v := values[column]
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s 应为整数: %s", column, v)
+	}
+	return n, nil
+}
+
+func parseIntPtr(values map[string]string, column string) (*int, error) {
+	if values[column] == "" {
+		return nil, nil
+	}
+	n, err := parseInt(values, column)
+	if err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+func parseBoolPtr(values map[string]string, column string) (*bool, error) {
+	v := values[column]
+	if v == "" {
+		return nil, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return nil, fmt.Errorf("%s 应为 true 或 false: %s", column, v)
+	}
+	return &b, nil
+}
+
+func parseTimePtr(values map[string]string, column string) (*time.Time, error) {
+	v := values[column]
+	if v == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, fmt.Errorf("%s 应为 RFC3339 时间: %s", column, v)
+	}
+	return &t, nil
+}
+
+func splitInts(values map[string]string, column string) ([]int, error) {
+	var result []int
+	for _, item := range splitList(values[column]) {
+		n, err := strconv.Atoi(item)
+		if err != nil {
+			return nil, fmt.Errorf("%s 应为分号分隔的整数: %s", column, values[column])
+		}
+		result = append(result, n)
+	}
+	return result, nil
+}
+
+func splitList(value string) []string {
+	var result []string
+	for _, item := range strings.Split(value, ";") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func joinInts(values []int) string {
+	items := make([]string, 0, len(values))
+	for _, v := range values {
+		items = append(items, strconv.Itoa(v))
+	}
+	return strings.Join(items, ";")
+}
+
+func formatIntPtr(v *int) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.Itoa(*v)
+}
+
+func formatBoolPtr(v *bool) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatBool(*v)
+}
+
+func formatTimePtr(v *time.Time) string {
+	if v == nil {
+		return ""
+	}
+	return v.Format(time.RFC3339)
+}
@@ -0,0 +1,383 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/zhoubofsy/x-bot/internal/application/dto"
+	"github.com/zhoubofsy/x-bot/internal/domain/entity"
+	"github.com/zhoubofsy/x-bot/internal/domain/repository"
+	apperrors "github.com/zhoubofsy/x-bot/pkg/errors"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// 导入记录的处理结果
+const (
+	ImportActionCreate = "create"
+	ImportActionUpdate = "update"
+	ImportActionFailed = "failed"
+)
+
+type BulkService interface {
+	// ExportAdCopies 按格式导出全部广告文案（不含已删除）
+	ExportAdCopies(ctx context.Context, format string, w io.Writer) error
+
+	// ImportAdCopies 按名称新增或更新广告文案，dryRun 为 true 时只校验不写入
+	ImportAdCopies(ctx context.Context, format string, r io.Reader, dryRun bool) (*dto.ImportResult, error)
+
+	// ExportUsers 按格式导出全部监控用户（不含已删除）
+	ExportUsers(ctx context.Context, format string, w io.Writer) error
+
+	// ImportUsers 按 twitter_user_id 新增或更新监控用户，dryRun 为 true 时只校验不写入
+	ImportUsers(ctx context.Context, format string, r io.Reader, dryRun bool) (*dto.ImportResult, error)
+}
+
+type bulkService struct {
+	adCopyRepo    repository.AdCopyRepository
+	userRepo      repository.UserRepository
+	userGroupRepo repository.UserGroupRepository
+	logger        *zap.Logger
+}
+
+func NewBulkService(
+	adCopyRepo repository.AdCopyRepository,
+	userRepo repository.UserRepository,
+	userGroupRepo repository.UserGroupRepository,
+	logger *zap.Logger,
+) BulkService {
+	return &bulkService{
+		adCopyRepo:    adCopyRepo,
+		userRepo:      userRepo,
+		userGroupRepo: userGroupRepo,
+		logger:        logger,
+	}
+}
+
+func (s *bulkService) ExportAdCopies(ctx context.Context, format string, w io.Writer) error {
+	if !ValidBulkFormat(format) {
+		return invalidFormat(format)
+	}
+	adCopies, err := s.adCopyRepo.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	records := make([]*dto.AdCopyRecord, 0, len(adCopies))
+	for _, adCopy := range adCopies {
+		records = append(records, adCopyRecordOf(adCopy))
+	}
+	return encodeRecords(format, w, records, adCopyColumns, adCopyToCSV)
+}
+
+func (s *bulkService) ImportAdCopies(ctx context.Context, format string, r io.Reader, dryRun bool) (*dto.ImportResult, error) {
+	if !ValidBulkFormat(format) {
+		return nil, invalidFormat(format)
+	}
+	records, rowErrs, err := decodeRecords(format, r, adCopyColumns, adCopyFromCSV)
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.ErrInvalidInput, "INVALID_IMPORT_FILE", err.Error())
+	}
+
+	result := &dto.ImportResult{DryRun: dryRun, Total: len(records)}
+	// 同一文件中名称重复时后面的记录会覆盖前面的，视为错误
+	seen := make(map[string]int)
+	for i, record := range records {
+		row := &dto.ImportRowResult{Row: i + 1}
+		err := rowErrs[i]
+		if err == nil && record == nil {
+			err = fmt.Errorf("记录为空")
+		}
+		if record != nil {
+			row.Key = strings.TrimSpace(record.Name)
+		}
+		if err == nil {
+			if first, ok := seen[row.Key]; ok {
+				err = fmt.Errorf("名称与第 %d 条记录重复", first)
+			} else {
+				seen[row.Key] = row.Row
+				row.Action, err = s.importAdCopy(ctx, record, dryRun)
+			}
+		}
+		s.addRow(result, row, err)
+	}
+
+	s.logger.Info("导入广告文案完成",
+		zap.Bool("dry_run", dryRun),
+		zap.Int("total", result.Total),
+		zap.Int("created", result.Created),
+		zap.Int("updated", result.Updated),
+		zap.Int("failed", result.Failed))
+	return result, nil
+}
+
+// importAdCopy 校验并写入一条文案记录，返回 create 或 update
+func (s *bulkService) importAdCopy(ctx context.Context, record *dto.AdCopyRecord, dryRun bool) (string, error) {
+	record.Name = strings.TrimSpace(record.Name)
+	if record.Name == "" || strings.TrimSpace(record.Content) == "" {
+		return "", fmt.Errorf("name 和 content 不能为空")
+	}
+	if err := ValidateAdCopyContent(record.Content, record.LinkURL); err != nil {
+		return "", err
+	}
+	variants, err := BuildAdCopyVariants(record.Variants, record.LinkURL)
+	if err != nil {
+		return "", err
+	}
+
+	matches, err := s.adCopyRepo.GetByName(ctx, record.Name)
+	if err != nil {
+		return "", err
+	}
+	if len(matches) > 1 {
+		return "", fmt.Errorf("存在 %d 条同名文案，无法确定要更新的文案", len(matches))
+	}
+
+	var existing *entity.AdCopy
+	adCopy := &entity.AdCopy{IsActive: true}
+	action := ImportActionCreate
+	if len(matches) == 1 {
+		// 在副本上修改，保留更新前的内容用于判断是否生成新版本
+		existing = matches[0]
+		copied := *existing
+		adCopy = &copied
+		action = ImportActionUpdate
+	}
+	// 导入的记录是文案的完整配置（is_active 为空时保持原状态）
+	adCopy.Name = record.Name
+	adCopy.Content = record.Content
+	adCopy.LinkURL = record.LinkURL
+	adCopy.Language = NormalizeAdCopyLanguage(record.Language)
+	adCopy.Category = record.Category
+	if adCopy.Category == "" {
+		adCopy.Category = "hackathon"
+	}
+	adCopy.CampaignID = record.CampaignID
+	if adCopy.CampaignID != nil && *adCopy.CampaignID <= 0 {
+		adCopy.CampaignID = nil
+	}
+	adCopy.Priority = record.Priority
+	if record.IsActive != nil {
+		adCopy.IsActive = *record.IsActive
+	}
+	adCopy.StartsAt = record.StartsAt
+	adCopy.EndsAt = record.EndsAt
+	adCopy.MaxDailyUses = record.MaxDailyUses
+	adCopy.MaxTotalUses = record.MaxTotalUses
+	adCopy.Weekdays = record.Weekdays
+	adCopy.StartHour = record.StartHour
+	adCopy.EndHour = record.EndHour
+	adCopy.Timezone = record.Timezone
+	adCopy.Emojis = record.Emojis
+	adCopy.Hashtags = record.Hashtags
+	adCopy.Variants = variants
+	if err := ValidateAdCopySchedule(adCopy); err != nil {
+		return "", err
+	}
+	if err := ValidateAdCopyVariation(adCopy); err != nil {
+		return "", err
+	}
+	if dryRun {
+		return action, nil
+	}
+
+	// 每条记录的文案、语言变体和版本在同一事务中写入，失败时不留下部分更新
+	err = s.adCopyRepo.Transaction(ctx, func(repo repository.AdCopyRepository) error {
+		if existing == nil {
+			if err := repo.Save(ctx, adCopy); err != nil {
+				return err
+			}
+			// 创建时 is_active 的零值会被数据库默认值覆盖
+			if !adCopy.IsActive {
+				if err := repo.Update(ctx, adCopy); err != nil {
+					return err
+				}
+			}
+			_, err := repo.CreateRevision(ctx, adCopy, "imported")
+			return err
+		}
+
+		if err := repo.Update(ctx, adCopy); err != nil {
+			return err
+		}
+		if err := repo.ReplaceVariants(ctx, adCopy.ID, variants); err != nil {
+			return err
+		}
+		// 回复内容变化时生成新版本
+		if entity.NewAdCopyRevision(existing, "").SameContent(entity.NewAdCopyRevision(adCopy, "")) {
+			return nil
+		}
+		_, err := repo.CreateRevision(ctx, adCopy, "imported")
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return action, nil
+}
+
+func (s *bulkService) ExportUsers(ctx context.Context, format string, w io.Writer) error {
+	if !ValidBulkFormat(format) {
+		return invalidFormat(format)
+	}
+	users, err := s.userRepo.List(ctx, repository.UserFilter{})
+	if err != nil {
+		return err
+	}
+	records := make([]*dto.UserRecord, 0, len(users))
+	for _, user := range users {
+		records = append(records, userRecordOf(user))
+	}
+	return encodeRecords(format, w, records, userColumns, userToCSV)
+}
+
+func (s *bulkService) ImportUsers(ctx context.Context, format string, r io.Reader, dryRun bool) (*dto.ImportResult, error) {
+	if !ValidBulkFormat(format) {
+		return nil, invalidFormat(format)
+	}
+	records, rowErrs, err := decodeRecords(format, r, userColumns, userFromCSV)
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.ErrInvalidInput, "INVALID_IMPORT_FILE", err.Error())
+	}
+
+	groups, err := s.userGroupRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	groupIDs := make(map[string]int, len(groups))
+	for _, group := range groups {
+		groupIDs[group.Name] = group.ID
+	}
+
+	result := &dto.ImportResult{DryRun: dryRun, Total: len(records)}
+	seen := make(map[string]int)
+	for i, record := range records {
+		row := &dto.ImportRowResult{Row: i + 1}
+		err := rowErrs[i]
+		if err == nil && record == nil {
+			err = fmt.Errorf("记录为空")
+		}
+		if record != nil {
+			row.Key = strings.TrimSpace(record.TwitterUserID)
+		}
+		if err == nil {
+			if first, ok := seen[row.Key]; ok {
+				err = fmt.Errorf("twitter_user_id 与第 %d 条记录重复", first)
+			} else {
+				seen[row.Key] = row.Row
+				row.Action, err = s.importUser(ctx, record, groupIDs, dryRun)
+			}
+		}
+		s.addRow(result, row, err)
+	}
+
+	s.logger.Info("导入监控用户完成",
+		zap.Bool("dry_run", dryRun),
+		zap.Int("total", result.Total),
+		zap.Int("created", result.Created),
+		zap.Int("updated", result.Updated),
+		zap.Int("failed", result.Failed))
+	return result, nil
+}
+
+// importUser 校验并写入一条用户记录，返回 create 或 update；已删除的用户按新增处理并被恢复
+func (s *bulkService) importUser(ctx context.Context, record *dto.UserRecord, groupIDs map[string]int, dryRun bool) (string, error) {
+	record.TwitterUserID = strings.TrimSpace(record.TwitterUserID)
+	record.Username = strings.TrimPrefix(strings.TrimSpace(record.Username), "@")
+	if record.TwitterUserID == "" || strings.Trim(record.TwitterUserID, "0123456789") != "" {
+		return "", fmt.Errorf("twitter_user_id 应为数字: %q", record.TwitterUserID)
+	}
+	if record.Username == "" {
+		return "", fmt.Errorf("username 不能为空")
+	}
+	if (record.ReplyCooldownSeconds != nil && *record.ReplyCooldownSeconds < 0) ||
+		(record.MaxRepliesPerWeek != nil && *record.MaxRepliesPerWeek < 0) {
+		return "", fmt.Errorf("回复频率策略不能为负数")
+	}
+	memberOf := make([]int, 0, len(record.Groups))
+	for _, name := range record.Groups {
+		id, ok := groupIDs[name]
+		if !ok {
+			return "", fmt.Errorf("分组不存在: %s", name)
+		}
+		memberOf = append(memberOf, id)
+	}
+
+	existing, err := s.userRepo.GetByTwitterID(ctx, record.TwitterUserID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", err
+	}
+	action := ImportActionCreate
+	if existing != nil {
+		action = ImportActionUpdate
+	}
+	if dryRun {
+		return action, nil
+	}
+
+	// 每条记录的用户、状态、回复策略和分组在同一事务中写入，失败时不留下部分更新
+	err = s.userRepo.Transaction(ctx, func(repo repository.UserRepository) error {
+		user := existing
+		if existing == nil {
+			user = &entity.FollowedUser{
+				TwitterUserID: record.TwitterUserID,
+				Username:      record.Username,
+				DisplayName:   record.DisplayName,
+				IsActive:      true,
+				Source:        entity.UserSourceManual,
+			}
+			if err := repo.Save(ctx, user); err != nil {
+				return err
+			}
+		} else {
+			// 只更新用户名和显示名，其余资料保持同步结果
+			profile := *existing
+			profile.Username = record.Username
+			profile.DisplayName = record.DisplayName
+			if err := repo.UpdateProfile(ctx, &profile); err != nil {
+				return err
+			}
+		}
+		// 新增时 is_active 的零值会被数据库默认值覆盖，统一在此更新；状态未变化时不更新，避免改变手动停用标记
+		if record.IsActive != nil && (existing == nil || *record.IsActive != existing.IsActive) {
+			if err := repo.UpdateActiveStatus(ctx, user.TwitterUserID, *record.IsActive); err != nil {
+				return err
+			}
+		}
+		if err := repo.UpdateReplyPolicy(ctx, user.TwitterUserID, record.ReplyCooldownSeconds, record.MaxRepliesPerWeek); err != nil {
+			return err
+		}
+		// 记录给出 groups 时，分组成员关系替换为记录中的分组
+		if record.Groups != nil {
+			return repo.SetGroups(ctx, user.ID, memberOf)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return action, nil
+}
+
+// addRow 记录单条结果，err 不为空时该条标记为失败
+func (s *bulkService) addRow(result *dto.ImportResult, row *dto.ImportRowResult, err error) {
+	if err != nil {
+		row.Action = ImportActionFailed
+		row.Error = err.Error()
+	}
+	switch row.Action {
+	case ImportActionCreate:
+		result.Created++
+	case ImportActionUpdate:
+		result.Updated++
+	default:
+		result.Failed++
+	}
+	result.Rows = append(result.Rows, row)
+}
+
+func invalidFormat(format string) error {
+	return apperrors.Wrap(apperrors.ErrInvalidInput, "INVALID_FORMAT", fmt.Sprintf("不支持的格式: %s，可选 csv 或 json", format))
+}
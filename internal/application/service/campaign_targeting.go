@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zhoubofsy/x-bot/internal/application/dto"
+	"github.com/zhoubofsy/x-bot/internal/domain/entity"
+	"github.com/zhoubofsy/x-bot/internal/domain/repository"
+	"go.uber.org/zap"
+)
+
+type CampaignTargeting interface {
+	// Running 获取投放中的活动，已过结束时间的活动自动结束
+	Running(ctx context.Context) ([]*entity.Campaign, error)
+
+	// Resolve 返回推文生效的活动：作者所属分组或来源搜索条件被活动投放时，取优先级最高的活动，没有时返回 nil
+	Resolve(campaigns []*entity.Campaign, user *entity.FollowedUser, searchQueryID int) *entity.Campaign
+
+	// Check 检查活动的回复数和 LLM 花费预算，预算用完时结束活动
+	Check(ctx context.Context, campaign *entity.Campaign) (ok bool, reason string, err error)
+
+	// RecordLLMCalls 记录活动的 LLM 调用次数，并按单次调用费用累计花费
+	RecordLLMCalls(ctx context.Context, campaign *entity.Campaign, calls int)
+
+	// Stats 统计活动的回复、LLM 用量和剩余预算
+	Stats(ctx context.Context, campaign *entity.Campaign) (*dto.CampaignStats, error)
+}
+
+type campaignTargeting struct {
+	campaignRepo repository.CampaignRepository
+	replyLogRepo repository.ReplyLogRepository
+	costPerCall  float64
+	logger       *zap.Logger
+}
+
+func NewCampaignTargeting(
+	campaignRepo repository.CampaignRepository,
+	replyLogRepo repository.ReplyLogRepository,
+	costPerCall float64,
+	logger *zap.Logger,
+) CampaignTargeting {
+	return &campaignTargeting{
+		campaignRepo: campaignRepo,
+		replyLogRepo: replyLogRepo,
+		costPerCall:  costPerCall,
+		logger:       logger,
+	}
+}
+
+func (t *campaignTargeting) Running(ctx context.Context) ([]*entity.Campaign, error) {
+	campaigns, err := t.campaignRepo.GetByStatus(ctx, entity.CampaignStatusActive)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var running []*entity.Campaign
+	for _, campaign := range campaigns {
+		if campaign.EndsAt != nil && !now.Before(*campaign.EndsAt) {
+			t.finish(ctx, campaign, "已到结束时间")
+			continue
+		}
+		if campaign.Running(now) {
+			running = append(running, campaign)
+		}
+	}
+	return running, nil
+}
+
+func (t *campaignTargeting) Resolve(campaigns []*entity.Campaign, user *entity.FollowedUser, searchQueryID int) *entity.Campaign {
+	// campaigns 已按优先级降序排列
+	for _, campaign := range campaigns {
+		if (searchQueryID > 0 && campaign.TargetsSearchQuery(searchQueryID)) || campaign.TargetsUser(user) {
+			return campaign
+		}
+	}
+	return nil
+}
+
+func (t *campaignTargeting) Check(ctx context.Context, campaign *entity.Campaign) (bool, string, error) {
+	if campaign == nil {
+		return true, "", nil
+	}
+	if campaign.Status != entity.CampaignStatusActive {
+		return false, fmt.Sprintf("活动 %s 已结束", campaign.Name), nil
+	}
+
+	if campaign.MaxLLMSpend > 0 && campaign.LLMSpend >= campaign.MaxLLMSpend {
+		reason := fmt.Sprintf("LLM 花费 %.4f 达到预算 %.4f", campaign.LLMSpend, campaign.MaxLLMSpend)
+		t.finish(ctx, campaign, reason)
+		return false, fmt.Sprintf("活动 %s %s", campaign.Name, reason), nil
+	}
+
+	if campaign.MaxReplies > 0 {
+		counts, err := t.replyLogRepo.CountByCampaignStatus(ctx, campaign.ID)
+		if err != nil {
+			return false, "", err
+		}
+		if replies := outgoingCount(counts); replies >= int64(campaign.MaxReplies) {
+			reason := fmt.Sprintf("回复数 %d 达到预算 %d", replies, campaign.MaxReplies)
+			t.finish(ctx, campaign, reason)
+			return false, fmt.Sprintf("活动 %s %s", campaign.Name, reason), nil
+		}
+	}
+
+	return true, "", nil
+}
+
+func (t *campaignTargeting) RecordLLMCalls(ctx context.Context, campaign *entity.Campaign, calls int) {
+	if campaign == nil || calls <= 0 {
+		return
+	}
+
+	cost := float64(calls) * t.costPerCall
+	if err := t.campaignRepo.AddLLMUsage(ctx, campaign.ID, calls, cost); err != nil {
+		t.logger.Error("记录活动 LLM 用量失败",
+			zap.Int("campaign_id", campaign.ID),
+			zap.Error(err),
+		)
+		return
+	}
+	// 同一次工作流内后续推文按最新用量检查预算
+	campaign.LLMCalls += calls
+	campaign.LLMSpend += cost
+}
+
+func (t *campaignTargeting) Stats(ctx context.Context, campaign *entity.Campaign) (*dto.CampaignStats, error) {
+	counts, err := t.replyLogRepo.CountByCampaignStatus(ctx, campaign.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &dto.CampaignStats{
+		CampaignID:   campaign.ID,
+		Status:       campaign.Status,
+		Replies:      outgoingCount(counts),
+		SuccessCount: counts[entity.ReplyStatusSuccess],
+		PendingCount: counts[entity.ReplyStatusPending] + counts[entity.ReplyStatusApproved] + counts[entity.ReplyStatusSending],
+		FailedCount:  counts[entity.ReplyStatusFailed],
+		BlockedCount: counts[entity.ReplyStatusBlockedBySafety],
+		DryRunCount:  counts[entity.ReplyStatusDryRun],
+		LLMCalls:     campaign.LLMCalls,
+		LLMSpend:     campaign.LLMSpend,
+		AdCopyCount:  len(campaign.AdCopies),
+	}
+	if campaign.MaxReplies > 0 {
+		remaining := int64(campaign.MaxReplies) - stats.Replies
+		stats.RemainingReplies = &remaining
+	}
+	if campaign.MaxLLMSpend > 0 {
+		remaining := campaign.MaxLLMSpend - campaign.LLMSpend
+		stats.RemainingLLMBudget = &remaining
+	}
+	return stats, nil
+}
+
+// finish 结束活动，失败只记录日志
+func (t *campaignTargeting) finish(ctx context.Context, campaign *entity.Campaign, reason string) {
+	if err := t.campaignRepo.Finish(ctx, campaign.ID, reason); err != nil {
+		t.logger.Error("结束活动失败",
+			zap.Int("campaign_id", campaign.ID),
+			zap.Error(err),
+		)
+		return
+	}
+	campaign.Status = entity.CampaignStatusFinished
+	campaign.FinishReason = reason
+	t.logger.Info("活动已结束",
+		zap.Int("campaign_id", campaign.ID),
+		zap.String("name", campaign.Name),
+		zap.String("reason", reason),
+	)
+}
+
+// outgoingCount 已发送或即将发送的回复数，计入活动回复预算
+func outgoingCount(counts map[entity.ReplyStatus]int64) int64 {
+	var total int64
+	for _, status := range entity.OutgoingReplyStatuses {
+		total += counts[status]
+	}
+	return total
+}
+
+// campaignAdCopyQuery 活动只使用其拥有的、属于活动分类的文案
+func campaignAdCopyQuery(campaign *entity.Campaign, language string) repository.AdCopyQuery {
+	return repository.AdCopyQuery{
+		Categories: []string{campaign.Category},
+		CampaignID: campaign.ID,
+		Language:   language,
+	}
+}
+
+// detectCategory 推文检测使用的分类：生效活动的分类，没有活动时为默认分类
+func detectCategory(campaign *entity.Campaign) string {
+	if campaign != nil && campaign.Category != "" {
+		return campaign.Category
+	}
+	return defaultAdCategory
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/zhoubofsy/x-bot/internal/config"
+	"github.com/zhoubofsy/x-bot/internal/infrastructure/twitter"
+)
+
+// EligibilityRule 推文资格规则
+type EligibilityRule interface {
+	// Name 规则名称，记录在跳过原因中
+	Name() string
+
+	// Check 检查推文是否满足规则，不满足时返回原因
+	Check(tweet twitter.Tweet, now time.Time) (bool, string)
+}
+
+// recheckableRule 检查结果会随时间变化的规则（如互动数增长），未通过时不应记录为最终跳过
+type recheckableRule interface {
+	Recheckable() bool
+}
+
+type EligibilityChecker interface {
+	// Check 依次执行规则链，返回第一条未通过的规则名称和原因；全部通过时返回 ok=true
+	Check(tweet twitter.Tweet) (ok bool, rule string, reason string)
+
+	// Recheckable 判断指定规则未通过时之后是否可能通过，可能通过的推文不记录跳过日志，下次拉取时重新评估
+	Recheckable(rule string) bool
+}
+
+type eligibilityChecker struct {
+	rules []EligibilityRule
+}
+
+// NewEligibilityChecker 根据配置构建规则链，未配置的规则不会加入
+func NewEligibilityChecker(cfg *config.EligibilityConfig) EligibilityChecker {
+	var rules []EligibilityRule
+
+	if cfg.MaxTweetAge > 0 {
+		rules = append(rules, maxAgeRule{maxAge: cfg.MaxTweetAge})
+	}
+	if cfg.ExcludeRetweets {
+		rules = append(rules, tweetTypeRule{name: "exclude_retweet", refType: twitter.ReferencedTypeRetweeted, label: "转推"})
+	}
+	if cfg.ExcludeQuotes {
+		rules = append(rules, tweetTypeRule{name: "exclude_quote", refType: twitter.ReferencedTypeQuoted, label: "引用推文"})
+	}
+	if cfg.ExcludeReplies {
+		rules = append(rules, tweetTypeRule{name: "exclude_reply", refType: twitter.ReferencedTypeRepliedTo, label: "回复推文"})
+	}
+	if cfg.RequireOpenReplies {
+		rules = append(rules, openRepliesRule{})
+	}
+	if cfg.MinAuthorFollowers > 0 {
+		rules = append(rules, minFollowersRule{min: cfg.MinAuthorFollowers})
+	}
+	if cfg.RequireVerified {
+		rules = append(rules, verifiedAuthorRule{})
+	}
+	if cfg.MinEngagement > 0 {
+		rules = append(rules, minEngagementRule{min: cfg.MinEngagement})
+	}
+
+	return &eligibilityChecker{rules: rules}
+}
+
+func (c *eligibilityChecker) Check(tweet twitter.Tweet) (bool, string, string) {
+	now := time.Now()
+	for _, rule := range c.rules {
+		if ok, reason := rule.Check(tweet, now); !ok {
+			return false, rule.Name(), reason
+		}
+	}
+	return true, "", ""
+}
+
+func (c *eligibilityChecker) Recheckable(rule string) bool {
+	for _, r := range c.rules {
+		if r.Name() == rule {
+			recheckable, ok := r.(recheckableRule)
+			return ok && recheckable.Recheckable()
+		}
+	}
+	return false
+}
+
+// maxAgeRule 推文发布时间不能早于指定时长
+type maxAgeRule struct {
+	maxAge time.Duration
+}
+
+func (r maxAgeRule) Name() string { return "max_tweet_age" }
+
+func (r maxAgeRule) Check(tweet twitter.Tweet, now time.Time) (bool, string) {
+	if tweet.CreatedAt.IsZero() {
+		return true, ""
+	}
+	if age := now.Sub(tweet.CreatedAt); age > r.maxAge {
+		return false, fmt.Sprintf("推文发布于 %s 前，超过 %s", age.Round(time.Minute), r.maxAge)
+	}
+	return true, ""
+}
+
+// tweetTypeRule 排除转推、引用或回复
+type tweetTypeRule struct {
+	name    string
+	refType string
+	label   string
+}
+
+func (r tweetTypeRule) Name() string { return r.name }
+
+func (r tweetTypeRule) Check(tweet twitter.Tweet, _ time.Time) (bool, string) {
+	if tweet.HasReference(r.refType) {
+		return false, "推文是" + r.label
+	}
+	return true, ""
+}
+
+// openRepliesRule 作者限制了回复范围时无法回复
+type openRepliesRule struct{}
+
+func (r openRepliesRule) Name() string { return "reply_settings" }
+
+func (r openRepliesRule) Check(tweet twitter.Tweet, _ time.Time) (bool, string) {
+	if tweet.ReplySettings == "" || tweet.ReplySettings == "everyone" {
+		return true, ""
+	}
+	return false, "推文限制了回复范围: " + tweet.ReplySettings
+}
+
+// minFollowersRule 作者粉丝数下限
+type minFollowersRule struct {
+	min int
+}
+
+func (r minFollowersRule) Name() string { return "min_author_followers" }
+
+func (r minFollowersRule) Check(tweet twitter.Tweet, _ time.Time) (bool, string) {
+	if tweet.Author == nil || tweet.Author.PublicMetrics == nil {
+		return false, "缺少作者粉丝数据"
+	}
+	if followers := tweet.Author.PublicMetrics.FollowersCount; followers < r.min {
+		return false, fmt.Sprintf("作者粉丝数 %d 低于 %d", followers, r.min)
+	}
+	return true, ""
+}
+
+// verifiedAuthorRule 只回复认证账号
+type verifiedAuthorRule struct{}
+
+func (r verifiedAuthorRule) Name() string { return "require_verified" }
+
+func (r verifiedAuthorRule) Check(tweet twitter.Tweet, _ time.Time) (bool, string) {
+	if tweet.Author == nil || !tweet.Author.Verified {
+		return false, "作者不是认证账号"
+	}
+	return true, ""
+}
+
+// minEngagementRule 推文互动数下限
+type minEngagementRule struct {
+	min int
+}
+
+func (r minEngagementRule) Name() string { return "min_engagement" }
+
+// Recheckable 推文的互动数会继续增长
+func (r minEngagementRule) Recheckable() bool { return true }
+
+func (r minEngagementRule) Check(tweet twitter.Tweet, _ time.Time) (bool, string) {
+	if engagement := tweet.PublicMetrics.Engagement(); engagement < r.min {
+		return false, fmt.Sprintf("推文互动数 %d 低于 %d", engagement, r.min)
+	}
+	return true, ""
+}
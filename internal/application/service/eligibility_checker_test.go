@@ -0,0 +1,135 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zhoubofsy/x-bot/internal/config"
+	"github.com/zhoubofsy/x-bot/internal/infrastructure/twitter"
+)
+
+// eligibleTweet 满足全部规则的推文，测试用例在此基础上修改
+func eligibleTweet() twitter.Tweet {
+	return twitter.Tweet{
+		ID:             "1",
+		Text:           "Join our hackathon",
+		CreatedAt:      time.Now().Add(-10 * time.Minute),
+		ConversationID: "1",
+		ReplySettings:  "everyone",
+		PublicMetrics:  &twitter.TweetMetrics{LikeCount: 3, RetweetCount: 2},
+		Author: &twitter.TwitterUser{
+			ID:            "100",
+			Username:      "alice",
+			Verified:      true,
+			PublicMetrics: &twitter.UserMetrics{FollowersCount: 1000},
+		},
+	}
+}
+
+func strictEligibilityConfig() *config.EligibilityConfig {
+	return &config.EligibilityConfig{
+		MaxTweetAge:        time.Hour,
+		ExcludeRetweets:    true,
+		ExcludeQuotes:      true,
+		ExcludeReplies:     true,
+		MinAuthorFollowers: 500,
+		RequireVerified:    true,
+		MinEngagement:      5,
+		RequireOpenReplies: true,
+	}
+}
+
+func TestEligibilityCheckerRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(tweet *twitter.Tweet)
+		wantRule string
+	}{
+		{"eligible", func(tweet *twitter.Tweet) {}, ""},
+		{"too old", func(tweet *twitter.Tweet) { tweet.CreatedAt = time.Now().Add(-2 * time.Hour) }, "max_tweet_age"},
+		{"unknown creation time", func(tweet *twitter.Tweet) { tweet.CreatedAt = time.Time{} }, ""},
+		{"retweet", func(tweet *twitter.Tweet) {
+			tweet.ReferencedTweets = []twitter.ReferencedTweet{{Type: twitter.ReferencedTypeRetweeted, ID: "9"}}
+		}, "exclude_retweet"},
+		{"quote", func(tweet *twitter.Tweet) {
+			tweet.ReferencedTweets = []twitter.ReferencedTweet{{Type: twitter.ReferencedTypeQuoted, ID: "9"}}
+		}, "exclude_quote"},
+		{"reply in a conversation", func(tweet *twitter.Tweet) {
+			tweet.ConversationID = "9"
+			tweet.ReferencedTweets = []twitter.ReferencedTweet{{Type: twitter.ReferencedTypeRepliedTo, ID: "9"}}
+		}, "exclude_reply"},
+		{"replies limited to followers", func(tweet *twitter.Tweet) { tweet.ReplySettings = "following" }, "reply_settings"},
+		{"replies limited to mentioned users", func(tweet *twitter.Tweet) { tweet.ReplySettings = "mentionedUsers" }, "reply_settings"},
+		{"reply settings not returned", func(tweet *twitter.Tweet) { tweet.ReplySettings = "" }, ""},
+		{"author without metrics", func(tweet *twitter.Tweet) { tweet.Author.PublicMetrics = nil }, "min_author_followers"},
+		{"author not expanded", func(tweet *twitter.Tweet) { tweet.Author = nil }, "min_author_followers"},
+		{"too few followers", func(tweet *twitter.Tweet) { tweet.Author.PublicMetrics.FollowersCount = 499 }, "min_author_followers"},
+		{"followers at minimum", func(tweet *twitter.Tweet) { tweet.Author.PublicMetrics.FollowersCount = 500 }, ""},
+		{"unverified author", func(tweet *twitter.Tweet) { tweet.Author.Verified = false }, "require_verified"},
+		{"low engagement", func(tweet *twitter.Tweet) { tweet.PublicMetrics.LikeCount = 2 }, "min_engagement"},
+		{"metrics missing", func(tweet *twitter.Tweet) { tweet.PublicMetrics = nil }, "min_engagement"},
+		{"first failing rule wins", func(tweet *twitter.Tweet) {
+			tweet.Author.Verified = false
+			tweet.PublicMetrics = nil
+		}, "require_verified"},
+	}
+
+	checker := NewEligibilityChecker(strictEligibilityConfig())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tweet := eligibleTweet()
+			tt.modify(&tweet)
+			ok, rule, reason := checker.Check(tweet)
+			if ok != (tt.wantRule == "") || rule != tt.wantRule {
+				t.Errorf("Check() = (%v, %q, %q), want rule %q", ok, rule, reason, tt.wantRule)
+			}
+			if !ok && reason == "" {
+				t.Errorf("Check() rule %q returned an empty reason", rule)
+			}
+		})
+	}
+}
+
+func TestEligibilityCheckerUnconfigured(t *testing.T) {
+	// 未配置的规则不加入规则链
+	checker := NewEligibilityChecker(&config.EligibilityConfig{})
+	tweet := twitter.Tweet{
+		ID:               "1",
+		CreatedAt:        time.Now().Add(-30 * 24 * time.Hour),
+		ReplySettings:    "following",
+		ReferencedTweets: []twitter.ReferencedTweet{{Type: twitter.ReferencedTypeRetweeted, ID: "9"}},
+	}
+	if ok, rule, reason := checker.Check(tweet); !ok {
+		t.Errorf("Check() = (false, %q, %q), want ok with no rules configured", rule, reason)
+	}
+}
+
+func TestEligibilityCheckerRecheckable(t *testing.T) {
+	tests := []struct {
+		rule string
+		want bool
+	}{
+		{"min_engagement", true},
+		{"max_tweet_age", false},
+		{"exclude_retweet", false},
+		{"exclude_quote", false},
+		{"exclude_reply", false},
+		{"reply_settings", false},
+		{"min_author_followers", false},
+		{"require_verified", false},
+		{"unknown_rule", false},
+		{"", false},
+	}
+
+	checker := NewEligibilityChecker(strictEligibilityConfig())
+	for _, tt := range tests {
+		if got := checker.Recheckable(tt.rule); got != tt.want {
+			t.Errorf("Recheckable(%q) = %v, want %v", tt.rule, got, tt.want)
+		}
+	}
+
+	// 规则未配置时不可重新评估
+	if NewEligibilityChecker(&config.EligibilityConfig{}).Recheckable("min_engagement") {
+		t.Error("Recheckable(min_engagement) = true for an unconfigured rule, want false")
+	}
+}
@@ -2,86 +2,212 @@ package service
 
 import (
 	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/zhoubofsy/x-bot/internal/application/dto"
+	"github.com/zhoubofsy/x-bot/internal/config"
 	"github.com/zhoubofsy/x-bot/internal/domain/entity"
 	"github.com/zhoubofsy/x-bot/internal/domain/repository"
 	"github.com/zhoubofsy/x-bot/internal/infrastructure/twitter"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 type FollowerService interface {
-	// SyncFollowing 同步当前用户的关注列表到数据库
+	// SyncFollowing 同步当前用户的关注列表及配置的 X 列表成员到数据库
 	SyncFollowing(ctx context.Context) (*dto.SyncFollowingResult, error)
 
 	// GetAllActiveFollowers 获取所有活跃的关注用户
 	GetAllActiveFollowers(ctx context.Context) ([]*entity.FollowedUser, error)
+
+	// GetActiveFollower 获取活跃的监控用户（包含所属分组），不是监控用户或已停用时返回 nil
+	GetActiveFollower(ctx context.Context, twitterUserID string) (*entity.FollowedUser, error)
+
+	// RefreshProfiles 重新拉取所有活跃用户的资料（粉丝数、认证状态、最近发推时间等）
+	RefreshProfiles(ctx context.Context) (*dto.RefreshProfilesResult, error)
+
+	// LookupUsers 按用户名（可带 @）查询用户资料，按输入顺序返回每个用户名的查询结果
+	LookupUsers(ctx context.Context, handles []string) ([]*dto.UserLookupResult, error)
+
+	// LookupUsersByID 按用户ID查询用户资料，按输入顺序返回每个ID的查询结果（Handle 为用户ID）
+	LookupUsersByID(ctx context.Context, ids []string) ([]*dto.UserLookupResult, error)
 }
 
 type followerService struct {
 	userRepo      repository.UserRepository
 	twitterClient twitter.Client
+	listsCfg      *config.ListsConfig
 	logger        *zap.Logger
 }
 
 func NewFollowerService(
 	userRepo repository.UserRepository,
 	twitterClient twitter.Client,
+	listsCfg *config.ListsConfig,
 	logger *zap.Logger,
 ) FollowerService {
 	return &followerService{
 		userRepo:      userRepo,
 		twitterClient: twitterClient,
+		listsCfg:      listsCfg,
 		logger:        logger,
 	}
 }
 
 func (s *followerService) SyncFollowing(ctx context.Context) (*dto.SyncFollowingResult, error) {
-	result := &dto.SyncFollowingResult{}
+	result := &dto.SyncFollowingResult{SourceCounts: make(map[string]int)}
 
-	// 获取当前用户信息
-	me, err := s.twitterClient.GetMe(ctx)
-	if err != nil {
-		s.logger.Error("获取当前用户信息失败", zap.Error(err))
-		// 如果获取用户信息失败，尝试返回数据库中已有的用户
-		return s.fallbackToDatabase(ctx, result, err)
+	// 按来源收集用户：先关注列表，再依次是配置的 X 列表；同一用户保留最先出现的来源
+	remote := make([]*entity.FollowedUser, 0)
+	seen := make(map[string]bool)
+	collect := func(source string, members []twitter.TwitterUser) {
+		for i, m := range members {
+			if seen[m.ID] {
+				continue
+			}
+			seen[m.ID] = true
+			user := toFollowedUser(&members[i])
+			user.Source = source
+			remote = append(remote, user)
+			result.SourceCounts[source]++
+		}
 	}
 
-	// 获取关注列表
-	following, err := s.twitterClient.GetFollowing(ctx, me.ID)
-	if err != nil {
-		s.logger.Warn("获取关注列表失败，回退到数据库中的手动添加用户", zap.Error(err))
-		// 如果 API 失败（如 Free 套餐限制），返回数据库中已有的用户
-		return s.fallbackToDatabase(ctx, result, err)
+	// synced 记录本次成功拉取的来源，拉取失败的来源不做移除判断
+	synced := make(map[string]bool)
+
+	following, followingErr := s.fetchFollowing(ctx)
+	if followingErr != nil {
+		s.logger.Warn("获取关注列表失败", zap.Error(followingErr))
+		result.Errors = append(result.Errors, "关注列表: "+followingErr.Error())
+	} else {
+		collect(entity.UserSourceFollowing, following)
+		synced[entity.UserSourceFollowing] = true
 	}
 
-	result.TotalCount = len(following)
+	configured := make(map[string]bool)
+	for _, listID := range s.listsCfg.IDs {
+		configured[entity.ListSource(listID)] = true
+
+		members, err := s.twitterClient.GetListMembers(ctx, listID)
+		if err != nil {
+			s.logger.Warn("获取列表成员失败", zap.String("list_id", listID), zap.Error(err))
+			result.Errors = append(result.Errors, "列表 "+listID+": "+err.Error())
+			continue
+		}
+		collect(entity.ListSource(listID), members)
+		synced[entity.ListSource(listID)] = true
+	}
 
-	// 转换并保存到数据库
-	users := make([]*entity.FollowedUser, 0, len(following))
-	for _, f := range following {
-		users = append(users, &entity.FollowedUser{
-			TwitterUserID: f.ID,
-			Username:      f.Username,
-			DisplayName:   f.Name,
-			IsActive:      true,
-		})
+	// 所有来源都失败时（如 Free 套餐限制），回退到数据库中已有的用户
+	if len(synced) == 0 {
+		result.Errors = nil
+		return s.fallbackToDatabase(ctx, result, followingErr)
 	}
 
-	if err := s.userRepo.BatchSave(ctx, users); err != nil {
+	result.TotalCount = len(remote)
+
+	// 包含已删除的用户，避免同步时把已删除的用户统计为新增
+	existing, err := s.userRepo.GetAllWithDeleted(ctx)
+	if err != nil {
+		s.logger.Error("获取已有用户失败", zap.Error(err))
+		return result, err
+	}
+	changes := diffUsers(existing, remote, synced, configured)
+	result.NewCount = len(changes.added)
+	result.UpdatedCount = len(changes.updated)
+	result.RemovedCount = len(changes.removed)
+
+	// 全部保存以刷新资料字段，差异只用于统计和停用
+	if err := s.userRepo.BatchSave(ctx, remote); err != nil {
 		s.logger.Error("保存关注用户失败", zap.Error(err))
 		result.Errors = append(result.Errors, err.Error())
 		return result, err
 	}
 
+	if err := s.userRepo.DeactivateByTwitterIDs(ctx, changes.removed); err != nil {
+		s.logger.Error("停用已取消关注的用户失败", zap.Error(err))
+		result.Errors = append(result.Errors, err.Error())
+		return result, err
+	}
+
 	result.Source = "twitter_api"
 	s.logger.Info("同步关注列表完成",
 		zap.Int("total", result.TotalCount),
+		zap.Int("new", result.NewCount),
+		zap.Int("updated", result.UpdatedCount),
+		zap.Int("removed", result.RemovedCount),
+		zap.Any("sources", result.SourceCounts),
 	)
 
 	return result, nil
 }
 
+// userChanges 同步差异
+type userChanges struct {
+	added   []*entity.FollowedUser
+	updated []*entity.FollowedUser
+	removed []string // 需要停用的 Twitter 用户ID
+}
+
+// diffUsers 对比数据库与远端用户：
+// 新出现的用户为 added；用户名、显示名、来源变化或重新出现的已停用用户为 updated；
+// 来源已成功同步（或列表已从配置中移除）但不再出现的活跃用户为 removed，手动添加的用户不会被移除；
+// 已删除的用户同步时保持删除，不计入任何差异
+func diffUsers(existing, remote []*entity.FollowedUser, synced, configured map[string]bool) userChanges {
+	var changes userChanges
+
+	current := make(map[string]*entity.FollowedUser, len(existing))
+	for _, u := range existing {
+		current[u.TwitterUserID] = u
+	}
+
+	seen := make(map[string]bool, len(remote))
+	for _, u := range remote {
+		seen[u.TwitterUserID] = true
+
+		old, ok := current[u.TwitterUserID]
+		if !ok {
+			changes.added = append(changes.added, u)
+			continue
+		}
+		if old.DeletedAt.Valid {
+			continue
+		}
+
+		sourceChanged := old.Source != entity.UserSourceManual && old.Source != u.Source
+		// 手动停用或因休眠被自动停用的用户不会被同步重新启用
+		reactivated := !old.IsActive && old.DormantReason == "" && !old.ManuallyDisabled
+		if old.Username != u.Username || old.DisplayName != u.DisplayName || reactivated || sourceChanged {
+			changes.updated = append(changes.updated, u)
+		}
+	}
+
+	for _, u := range existing {
+		if !u.IsActive || u.DeletedAt.Valid || seen[u.TwitterUserID] || u.Source == entity.UserSourceManual || u.Source == "" {
+			continue
+		}
+		listRemoved := u.ListID() != "" && !configured[u.Source]
+		if synced[u.Source] || listRemoved {
+			changes.removed = append(changes.removed, u.TwitterUserID)
+		}
+	}
+
+	return changes
+}
+
+// fetchFollowing 获取当前账号的关注列表
+func (s *followerService) fetchFollowing(ctx context.Context) ([]twitter.TwitterUser, error) {
+	me, err := s.twitterClient.GetMe(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.twitterClient.GetFollowing(ctx, me.ID)
+}
+
 // fallbackToDatabase 当 Twitter API 失败时，回退到数据库中已有的用户
 func (s *followerService) fallbackToDatabase(ctx context.Context, result *dto.SyncFollowingResult, apiErr error) (*dto.SyncFollowingResult, error) {
 	users, err := s.userRepo.GetAllActiveUsers(ctx)
@@ -109,3 +235,203 @@ func (s *followerService) fallbackToDatabase(ctx context.Context, result *dto.Sy
 func (s *followerService) GetAllActiveFollowers(ctx context.Context) ([]*entity.FollowedUser, error) {
 	return s.userRepo.GetAllActiveUsers(ctx)
 }
+
+func (s *followerService) GetActiveFollower(ctx context.Context, twitterUserID string) (*entity.FollowedUser, error) {
+	user, err := s.userRepo.GetActiveByTwitterID(ctx, twitterUserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return user, err
+}
+
+func (s *followerService) RefreshProfiles(ctx context.Context) (*dto.RefreshProfilesResult, error) {
+	users, err := s.userRepo.GetAllActiveUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.RefreshProfilesResult{TotalCount: len(users)}
+	if len(users) == 0 {
+		return result, nil
+	}
+
+	ids := make([]string, 0, len(users))
+	for _, u := range users {
+		ids = append(ids, u.TwitterUserID)
+	}
+
+	profiles, lookupErrors, err := s.twitterClient.GetUsersByIDs(ctx, ids)
+	if err != nil {
+		s.logger.Error("刷新用户资料失败", zap.Error(err))
+		return nil, err
+	}
+
+	for i := range profiles {
+		if err := s.userRepo.UpdateProfile(ctx, toFollowedUser(&profiles[i])); err != nil {
+			result.Errors = append(result.Errors, profiles[i].ID+": "+err.Error())
+			continue
+		}
+		result.UpdatedCount++
+	}
+	// 已注销或被冻结的账号只记录，不自动停用
+	for _, e := range lookupErrors {
+		result.Errors = append(result.Errors, e.Value+": "+e.Detail)
+	}
+
+	s.logger.Info("刷新用户资料完成",
+		zap.Int("total", result.TotalCount),
+		zap.Int("updated", result.UpdatedCount),
+		zap.Int("errors", len(result.Errors)),
+	)
+
+	return result, nil
+}
+
+// usernamePattern X 用户名规则：1-15 位字母、数字或下划线
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9_]{1,15}$`)
+
+func (s *followerService) LookupUsers(ctx context.Context, handles []string) ([]*dto.UserLookupResult, error) {
+	results := make([]*dto.UserLookupResult, 0, len(handles))
+
+	var usernames []string
+	queued := make(map[string]bool)
+	for _, handle := range handles {
+		username := strings.TrimPrefix(strings.TrimSpace(handle), "@")
+		result := &dto.UserLookupResult{Handle: handle, Username: username}
+		results = append(results, result)
+
+		if !usernamePattern.MatchString(username) {
+			result.Error = "用户名格式无效"
+			continue
+		}
+		if key := strings.ToLower(username); !queued[key] {
+			queued[key] = true
+			usernames = append(usernames, username)
+		}
+	}
+
+	if len(usernames) == 0 {
+		return results, nil
+	}
+
+	users, lookupErrors, err := s.twitterClient.GetUsersByUsernames(ctx, usernames)
+	if err != nil {
+		s.logger.Error("查询用户资料失败", zap.Strings("usernames", usernames), zap.Error(err))
+		return nil, err
+	}
+
+	found := make(map[string]*twitter.TwitterUser, len(users))
+	for i := range users {
+		found[strings.ToLower(users[i].Username)] = &users[i]
+	}
+	failed := make(map[string]string, len(lookupErrors))
+	for _, e := range lookupErrors {
+		failed[strings.ToLower(e.Value)] = e.Detail
+	}
+
+	for _, result := range results {
+		if result.Error != "" {
+			continue
+		}
+		key := strings.ToLower(result.Username)
+		if u, ok := found[key]; ok {
+			result.Username = u.Username
+			result.User = toFollowedUser(u)
+			continue
+		}
+		if detail, ok := failed[key]; ok && detail != "" {
+			result.Error = detail
+		} else {
+			result.Error = "用户不存在"
+		}
+	}
+
+	return results, nil
+}
+
+// userIDPattern X 用户ID为纯数字
+var userIDPattern = regexp.MustCompile(`^[0-9]{1,20}$`)
+
+func (s *followerService) LookupUsersByID(ctx context.Context, ids []string) ([]*dto.UserLookupResult, error) {
+	results := make([]*dto.UserLookupResult, 0, len(ids))
+
+	var queries []string
+	queued := make(map[string]bool)
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		result := &dto.UserLookupResult{Handle: id}
+		results = append(results, result)
+
+		if !userIDPattern.MatchString(id) {
+			result.Error = "用户ID格式无效"
+			continue
+		}
+		if !queued[id] {
+			queued[id] = true
+			queries = append(queries, id)
+		}
+	}
+
+	if len(queries) == 0 {
+		return results, nil
+	}
+
+	users, lookupErrors, err := s.twitterClient.GetUsersByIDs(ctx, queries)
+	if err != nil {
+		s.logger.Error("查询用户资料失败", zap.Strings("ids", queries), zap.Error(err))
+		return nil, err
+	}
+
+	found := make(map[string]*twitter.TwitterUser, len(users))
+	for i := range users {
+		found[users[i].ID] = &users[i]
+	}
+	failed := make(map[string]string, len(lookupErrors))
+	for _, e := range lookupErrors {
+		failed[e.Value] = e.Detail
+	}
+
+	for _, result := range results {
+		if result.Error != "" {
+			continue
+		}
+		if u, ok := found[result.Handle]; ok {
+			result.Username = u.Username
+			result.User = toFollowedUser(u)
+			continue
+		}
+		if detail, ok := failed[result.Handle]; ok && detail != "" {
+			result.Error = detail
+		} else {
+			result.Error = "用户不存在"
+		}
+	}
+
+	return results, nil
+}
+
+// toFollowedUser 将 Twitter 用户资料转换为监控用户（来源由调用方设置）
+func toFollowedUser(u *twitter.TwitterUser) *entity.FollowedUser {
+	now := time.Now()
+	user := &entity.FollowedUser{
+		TwitterUserID:      u.ID,
+		Username:           u.Username,
+		DisplayName:        u.Name,
+		Description:        u.Description,
+		Protected:          u.Protected,
+		Verified:           u.Verified,
+		Location:           u.Location,
+		ProfileURL:         u.URL,
+		IsActive:           true,
+		ProfileRefreshedAt: &now,
+	}
+	if u.PublicMetrics != nil {
+		user.FollowersCount = u.PublicMetrics.FollowersCount
+		user.FollowingCount = u.PublicMetrics.FollowingCount
+		user.TweetCount = u.PublicMetrics.TweetCount
+	}
+	if t, ok := twitter.SnowflakeTime(u.MostRecentTweetID); ok {
+		user.LastTweetAt = &t
+	}
+	return user
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zhoubofsy/x-bot/internal/domain/entity"
+	"github.com/zhoubofsy/x-bot/internal/domain/repository"
+)
+
+// defaultAdCategory 没有分组或分组未限定类别时使用的广告类别
+const defaultAdCategory = "hackathon"
+
+type GroupTargeting interface {
+	// Resolve 返回用户生效的分组：启用的分组中优先级最高的一个，没有分组时返回 nil
+	Resolve(user *entity.FollowedUser) *entity.UserGroup
+
+	// Check 检查当前是否在分组的回复时段内，以及分组今日（按分组时区计算）回复数是否达到上限
+	Check(ctx context.Context, group *entity.UserGroup) (ok bool, reason string, err error)
+}
+
+type groupTargeting struct {
+	replyLogRepo repository.ReplyLogRepository
+}
+
+func NewGroupTargeting(replyLogRepo repository.ReplyLogRepository) GroupTargeting {
+	return &groupTargeting{replyLogRepo: replyLogRepo}
+}
+
+func (t *groupTargeting) Resolve(user *entity.FollowedUser) *entity.UserGroup {
+	if user == nil {
+		return nil
+	}
+
+	var selected *entity.UserGroup
+	for i := range user.Groups {
+		group := &user.Groups[i]
+		if !group.IsActive {
+			continue
+		}
+		if selected == nil || group.Priority > selected.Priority {
+			selected = group
+		}
+	}
+	return selected
+}
+
+func (t *groupTargeting) Check(ctx context.Context, group *entity.UserGroup) (bool, string, error) {
+	if group == nil {
+		return true, "", nil
+	}
+
+	now := time.Now()
+	if !group.InSchedule(now) {
+		return false, fmt.Sprintf("不在分组 %s 的回复时段内", group.Name), nil
+	}
+
+	if group.MaxDailyReplies > 0 {
+		count, err := t.replyLogRepo.CountRepliesByGroupSince(ctx, group.ID, group.DayStart(now))
+		if err != nil {
+			return false, "", err
+		}
+		if count >= int64(group.MaxDailyReplies) {
+			return false, fmt.Sprintf("分组 %s 今日已回复 %d 次，达到上限 %d", group.Name, count, group.MaxDailyReplies), nil
+		}
+	}
+
+	return true, "", nil
+}
+
+// adCopyQueryFor 根据分组限定的类别和文案构建选择条件
+func adCopyQueryFor(group *entity.UserGroup, language string) repository.AdCopyQuery {
+	query := repository.AdCopyQuery{
+		Categories: []string{defaultAdCategory},
+		Language:   language,
+	}
+	if group != nil {
+		if len(group.AdCategories) > 0 {
+			query.Categories = group.AdCategories
+		}
+		query.IDs = group.AdCopyIDs
+	}
+	return query
+}
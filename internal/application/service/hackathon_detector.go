@@ -2,15 +2,25 @@ package service
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/zhoubofsy/x-bot/internal/infrastructure/llm"
+	apperrors "github.com/zhoubofsy/x-bot/pkg/errors"
 	"go.uber.org/zap"
 )
 
+// detectableCategories 有对应 LLM 检测的推文分类，推广活动只能使用这些分类
+var detectableCategories = map[string]bool{defaultAdCategory: true}
+
+// DetectableCategory 判断推文分类是否有对应的检测器
+func DetectableCategory(category string) bool {
+	return detectableCategories[category]
+}
+
 type HackathonDetector interface {
-	// Detect 检测推文是否与黑客松相关
+	// Detect 检测推文是否属于指定分类（推广活动的分类，没有活动时为 hackathon）
 	// 返回：是否相关、LLM原始响应、错误
-	Detect(ctx context.Context, tweetContent string) (bool, string, error)
+	Detect(ctx context.Context, category string, tweetContent string) (bool, string, error)
 }
 
 type hackathonDetector struct {
@@ -28,7 +38,11 @@ func NewHackathonDetector(
 	}
 }
 
-func (d *hackathonDetector) Detect(ctx context.Context, tweetContent string) (bool, string, error) {
+func (d *hackathonDetector) Detect(ctx context.Context, category string, tweetContent string) (bool, string, error) {
+	if !DetectableCategory(category) {
+		return false, "", apperrors.Wrap(apperrors.ErrInvalidInput, "UNSUPPORTED_CATEGORY", fmt.Sprintf("不支持的推文分类: %s", category))
+	}
+
 	isRelated, rawResponse, err := d.llmClient.IsHackathonRelated(ctx, tweetContent)
 	if err != nil {
 		d.logger.Error("LLM检测失败", zap.Error(err))
@@ -36,6 +50,7 @@ func (d *hackathonDetector) Detect(ctx context.Context, tweetContent string) (bo
 	}
 
 	d.logger.Debug("黑客松检测结果",
+		zap.String("category", category),
 		zap.Bool("is_related", isRelated),
 		zap.String("response", rawResponse),
 	)
@@ -0,0 +1,255 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/zhoubofsy/x-bot/internal/config"
+	"github.com/zhoubofsy/x-bot/internal/domain/entity"
+	"github.com/zhoubofsy/x-bot/internal/domain/repository"
+	apperrors "github.com/zhoubofsy/x-bot/pkg/errors"
+	"github.com/zhoubofsy/x-bot/pkg/spintax"
+	"github.com/zhoubofsy/x-bot/pkg/tweettext"
+	"go.uber.org/zap"
+)
+
+const (
+	codeAlphabet      = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	defaultCodeLength = 8
+	maxCodeAttempts   = 3
+)
+
+type LinkTracker interface {
+	// Rewrite 在发送前把回复内容中文案自身的链接替换为短链接，短链接关联回复日志
+	// 文案自身的链接为推广链接（link_url）以及文案和各语言变体中直接写出的链接；模板变量渲染出的推文、活动链接保持不变
+	// 未开启追踪、文案没有链接、日志未保存或生成失败时保留原链接，不影响回复
+	Rewrite(ctx context.Context, log *entity.ReplyLog, adCopy *entity.AdCopy) string
+
+	// Click 记录一次点击并返回跳转地址，爬虫和去重窗口内同一访客的重复点击只跳转不计数
+	Click(ctx context.Context, code, clientIP, referrer, userAgent string) (string, error)
+}
+
+type linkTracker struct {
+	linkRepo repository.TrackedLinkRepository
+	cfg      config.LinkTrackingConfig
+	logger   *zap.Logger
+}
+
+func NewLinkTracker(linkRepo repository.TrackedLinkRepository, cfg config.LinkTrackingConfig, logger *zap.Logger) LinkTracker {
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	if cfg.CodeLength <= 0 {
+		cfg.CodeLength = defaultCodeLength
+	}
+	return &linkTracker{linkRepo: linkRepo, cfg: cfg, logger: logger}
+}
+
+func (t *linkTracker) Rewrite(ctx context.Context, log *entity.ReplyLog, adCopy *entity.AdCopy) string {
+	content := log.ReplyContent
+	if !t.cfg.Enabled || t.cfg.BaseURL == "" || log.ID == 0 || adCopy == nil {
+		return content
+	}
+	links := authoredLinks(adCopy)
+	if len(links) == 0 {
+		return content
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, loc := range tweettext.FindURLs(content) {
+		original := content[loc[0]:loc[1]]
+		if !links[normalizeLink(original)] {
+			continue
+		}
+		b.WriteString(content[last:loc[0]])
+		b.WriteString(t.shorten(ctx, original, log, adCopy))
+		last = loc[1]
+	}
+	b.WriteString(content[last:])
+	return b.String()
+}
+
+// authoredLinks 文案自身的链接：推广链接，以及文案和各语言变体的每个 spintax 候选项中直接写出的链接
+func authoredLinks(adCopy *entity.AdCopy) map[string]bool {
+	links := make(map[string]bool)
+	if adCopy.LinkURL != "" {
+		links[normalizeLink(adCopy.LinkURL)] = true
+	}
+
+	contents := []string{adCopy.Content}
+	for _, v := range adCopy.Variants {
+		contents = append(contents, v.Content)
+	}
+	for _, content := range contents {
+		texts := []string{content}
+		if parsed, err := spintax.Parse(content); err == nil {
+			texts = parsed.Cover()
+		}
+		for _, text := range texts {
+			for _, loc := range tweettext.FindURLs(text) {
+				links[normalizeLink(text[loc[0]:loc[1]])] = true
+			}
+		}
+	}
+	return links
+}
+
+// normalizeLink 去掉协议和末尾的斜杠，用于判断回复中的链接是否为文案自身的链接
+func normalizeLink(raw string) string {
+	lower := strings.ToLower(raw)
+	for _, scheme := range []string{"https://", "http://"} {
+		if strings.HasPrefix(lower, scheme) {
+			raw = raw[len(scheme):]
+			break
+		}
+	}
+	return strings.TrimRight(raw, "/")
+}
+
+// shorten 为单个链接生成短链接，已是短链接或失败时返回原链接
+func (t *linkTracker) shorten(ctx context.Context, original string, log *entity.ReplyLog, adCopy *entity.AdCopy) string {
+	if strings.HasPrefix(original, t.cfg.BaseURL+"/") {
+		return original
+	}
+
+	target, err := t.targetURL(original, adCopy)
+	if err != nil {
+		t.logger.Warn("解析回复链接失败，保留原链接", zap.String("url", original), zap.Error(err))
+		return original
+	}
+
+	link := &entity.TrackedLink{
+		OriginalURL: original,
+		TargetURL:   target,
+		TweetID:     log.TweetID,
+		ReplyLogID:  &log.ID,
+		AdCopyID:    &adCopy.ID,
+		CampaignID:  adCopy.CampaignID,
+	}
+
+	// 短码冲突时重新生成
+	for attempt := 1; attempt <= maxCodeAttempts; attempt++ {
+		link.ID = 0
+		link.Code, err = randomCode(t.cfg.CodeLength)
+		if err == nil {
+			err = t.linkRepo.Create(ctx, link)
+		}
+		if err == nil {
+			return t.cfg.BaseURL + "/r/" + link.Code
+		}
+	}
+
+	t.logger.Warn("生成短链接失败，保留原链接",
+		zap.Int("reply_log_id", log.ID),
+		zap.String("url", original),
+		zap.Error(err),
+	)
+	return original
+}
+
+// targetURL 补全协议并附加 UTM 参数，不覆盖链接中已有的参数
+func (t *linkTracker) targetURL(original string, adCopy *entity.AdCopy) (string, error) {
+	raw := original
+	if !strings.HasPrefix(strings.ToLower(raw), "http://") && !strings.HasPrefix(strings.ToLower(raw), "https://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", err
+	}
+
+	params := map[string]string{
+		"utm_source":   t.cfg.UTMSource,
+		"utm_medium":   t.cfg.UTMMedium,
+		"utm_campaign": t.cfg.UTMCampaign,
+	}
+	if adCopy != nil {
+		params["utm_content"] = fmt.Sprintf("ad_copy_%d", adCopy.ID)
+		if adCopy.CampaignID != nil {
+			params["utm_campaign"] = fmt.Sprintf("campaign_%d", *adCopy.CampaignID)
+		}
+	}
+
+	query := u.Query()
+	for key, value := range params {
+		if value != "" && query.Get(key) == "" {
+			query.Set(key, value)
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+func (t *linkTracker) Click(ctx context.Context, code, clientIP, referrer, userAgent string) (string, error) {
+	link, err := t.linkRepo.GetByCode(ctx, code)
+	if err != nil {
+		return "", apperrors.Wrap(apperrors.ErrNotFound, "LINK_NOT_FOUND", "短链接不存在")
+	}
+
+	// 链接预览等爬虫的请求不计入点击
+	if isCrawler(userAgent) {
+		return link.TargetURL, nil
+	}
+
+	now := time.Now()
+	click := &entity.LinkClick{
+		TrackedLinkID: link.ID,
+		Referrer:      referrer,
+		UserAgent:     userAgent,
+		VisitorHash:   visitorHash(clientIP, userAgent),
+		ClickedAt:     now,
+	}
+	var since time.Time
+	if t.cfg.ClickDedupeWindow > 0 {
+		since = now.Add(-t.cfg.ClickDedupeWindow)
+	}
+
+	// 点击记录失败不影响跳转
+	if _, err := t.linkRepo.RecordClick(ctx, click, since); err != nil {
+		t.logger.Error("记录短链接点击失败", zap.String("code", code), zap.Error(err))
+	}
+
+	return link.TargetURL, nil
+}
+
+// crawlerMarkers User-Agent 中出现即视为爬虫（Twitterbot、Googlebot、Slackbot 等链接预览和搜索引擎）
+var crawlerMarkers = []string{"bot/", "bot;", "crawler", "spider", "facebookexternalhit", "slackbot", "whatsapp", "preview"}
+
+func isCrawler(userAgent string) bool {
+	ua := strings.ToLower(userAgent)
+	if ua == "" {
+		return true
+	}
+	for _, marker := range crawlerMarkers {
+		if strings.Contains(ua, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// visitorHash 用 IP 和 User-Agent 的哈希标识访客，不保存原始 IP
+func visitorHash(clientIP, userAgent string) string {
+	sum := sha256.Sum256([]byte(clientIP + "|" + userAgent))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomCode 生成 base62 随机短码
+func randomCode(length int) (string, error) {
+	max := big.NewInt(int64(len(codeAlphabet)))
+	code := make([]byte, length)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = codeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/zhoubofsy/x-bot/internal/domain/entity"
+)
+
+func TestAuthoredLinks(t *testing.T) {
+	tests := []struct {
+		name   string
+		adCopy entity.AdCopy
+		want   []string
+	}{
+		{"no links", entity.AdCopy{Content: "Good luck {{.Author.Username}}!"}, []string{}},
+		{"link_url only", entity.AdCopy{Content: "Try it: {{.TrackingLink}}", LinkURL: "https://example.com/"}, []string{"example.com"}},
+		{"links in content", entity.AdCopy{
+			Content: "Docs at https://docs.example.com/start and example.org",
+			LinkURL: "https://example.com",
+		}, []string{"docs.example.com/start", "example.com", "example.org"}},
+		{"links in every spintax choice", entity.AdCopy{
+			Content: "{See https://a.example.com|Read {http://b.example.com/|c.example.com/x}}",
+		}, []string{"a.example.com", "b.example.com", "c.example.com/x"}},
+		{"links in variants", entity.AdCopy{
+			Content:  "Good luck!",
+			Variants: []entity.AdCopyVariant{{Language: "zh", Content: "文档 https://example.cn/zh"}},
+		}, []string{"example.cn/zh"}},
+		// 模板变量渲染出的推文、活动链接不属于文案
+		{"template links excluded", entity.AdCopy{Content: "{{.Event.URL}} {{.Tweet.URL}}"}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := slices.Sorted(maps.Keys(authoredLinks(&tt.adCopy)))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("authoredLinks() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/zhoubofsy/x-bot/internal/application/dto"
+	"github.com/zhoubofsy/x-bot/internal/config"
+	"github.com/zhoubofsy/x-bot/internal/domain/repository"
+	"go.uber.org/zap"
+)
+
+type PurgeService interface {
+	// PurgeDeleted 永久删除超过保留时长、且没有被回复日志引用的已删除文案和用户
+	PurgeDeleted(ctx context.Context) (*dto.PurgeResult, error)
+}
+
+type purgeService struct {
+	adCopyRepo repository.AdCopyRepository
+	userRepo   repository.UserRepository
+	cfg        *config.PurgeConfig
+	logger     *zap.Logger
+}
+
+func NewPurgeService(
+	adCopyRepo repository.AdCopyRepository,
+	userRepo repository.UserRepository,
+	cfg *config.PurgeConfig,
+	logger *zap.Logger,
+) PurgeService {
+	return &purgeService{
+		adCopyRepo: adCopyRepo,
+		userRepo:   userRepo,
+		cfg:        cfg,
+		logger:     logger,
+	}
+}
+
+func (s *purgeService) PurgeDeleted(ctx context.Context) (*dto.PurgeResult, error) {
+	before := time.Now().Add(-s.cfg.RetainFor)
+	result := &dto.PurgeResult{}
+
+	var err error
+	if result.AdCopies, err = s.adCopyRepo.PurgeDeleted(ctx, before); err != nil {
+		return result, err
+	}
+	if result.Users, err = s.userRepo.PurgeDeleted(ctx, before); err != nil {
+		return result, err
+	}
+
+	if result.AdCopies > 0 || result.Users > 0 {
+		s.logger.Info("已清理删除的数据",
+			zap.Int64("ad_copies", result.AdCopies),
+			zap.Int64("users", result.Users),
+		)
+	}
+	return result, nil
+}
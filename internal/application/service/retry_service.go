@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/zhoubofsy/x-bot/internal/application/dto"
+	"github.com/zhoubofsy/x-bot/internal/config"
+	"github.com/zhoubofsy/x-bot/internal/domain/entity"
+	"github.com/zhoubofsy/x-bot/internal/domain/repository"
+	"github.com/zhoubofsy/x-bot/internal/infrastructure/twitter"
+	apperrors "github.com/zhoubofsy/x-bot/pkg/errors"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type RetryService interface {
+	// ProcessDue 重试已到时间的失败回复，遵守每日回复上限
+	ProcessDue(ctx context.Context, limit int) ([]*dto.RetryResult, error)
+
+	// Retry 手动重试失败的回复（忽略重试次数上限）
+	Retry(ctx context.Context, id int) (*entity.ReplyLog, error)
+}
+
+type retryService struct {
+	replyLogRepo   repository.ReplyLogRepository
+	userRepo       repository.UserRepository
+	adReplyService AdReplyService
+	linkTracker    LinkTracker
+	cfg            *config.WorkflowConfig
+	logger         *zap.Logger
+}
+
+func NewRetryService(
+	replyLogRepo repository.ReplyLogRepository,
+	userRepo repository.UserRepository,
+	adReplyService AdReplyService,
+	linkTracker LinkTracker,
+	cfg *config.WorkflowConfig,
+	logger *zap.Logger,
+) RetryService {
+	return &retryService{
+		replyLogRepo:   replyLogRepo,
+		userRepo:       userRepo,
+		adReplyService: adReplyService,
+		linkTracker:    linkTracker,
+		cfg:            cfg,
+		logger:         logger,
+	}
+}
+
+func (s *retryService) ProcessDue(ctx context.Context, limit int) ([]*dto.RetryResult, error) {
+	logs, err := s.replyLogRepo.GetDueRetries(ctx, time.Now(), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*dto.RetryResult, 0, len(logs))
+	for _, log := range logs {
+		todayCount, err := s.replyLogRepo.GetTodaySuccessCount(ctx)
+		if err != nil {
+			return results, err
+		}
+		if todayCount >= int64(s.cfg.MaxDailyReplies) {
+			s.logger.Debug("已达到今日回复上限，暂停重试")
+			break
+		}
+
+		claimed, err := s.attempt(ctx, log)
+		if err != nil {
+			return results, err
+		}
+		if !claimed {
+			continue
+		}
+		results = append(results, &dto.RetryResult{
+			ReplyLogID:    log.ID,
+			TweetID:       log.TweetID,
+			Status:        log.Status,
+			FailureReason: log.FailureReason,
+			RetryCount:    log.RetryCount,
+		})
+	}
+
+	return results, nil
+}
+
+func (s *retryService) Retry(ctx context.Context, id int) (*entity.ReplyLog, error) {
+	log, err := s.replyLogRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.ErrNotFound, "REPLY_LOG_NOT_FOUND", "回复记录不存在")
+	}
+
+	if log.Status != entity.ReplyStatusFailed && log.Status != entity.ReplyStatusRetryScheduled {
+		return nil, apperrors.Wrap(apperrors.ErrInvalidInput, "INVALID_STATUS", "只能重试失败的回复")
+	}
+	if log.ReplyContent == "" && log.AdCopy == nil {
+		return nil, apperrors.Wrap(apperrors.ErrInvalidInput, "NO_REPLY_CONTENT", "回复记录缺少回复内容，无法重试")
+	}
+
+	claimed, err := s.attempt(ctx, log)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, apperrors.Wrap(apperrors.ErrInvalidInput, "REPLY_IN_PROGRESS", "回复正在发送或已被其他任务处理")
+	}
+	return log, nil
+}
+
+// attempt 领取回复并重新发送一次、保存结果；回复已被其他任务领取时返回 false
+func (s *retryService) attempt(ctx context.Context, log *entity.ReplyLog) (bool, error) {
+	// 先将回复领取为 sending 再发送，定时重试和手动重试同时处理同一条回复时只有一个能领取成功，避免重复发送
+	previous := log.Status
+	claimed, err := s.replyLogRepo.UpdateStatusIf(ctx, log.ID, previous, entity.ReplyStatusSending)
+	if err != nil {
+		return false, err
+	}
+	if !claimed {
+		s.logger.Debug("重试回复已被其他任务领取", zap.Int("reply_log_id", log.ID))
+		return false, nil
+	}
+	log.Status = entity.ReplyStatusSending
+
+	if err := s.prepare(ctx, log); err != nil {
+		// 重新生成失败时释放领取，保持原状态以便之后重试
+		if _, releaseErr := s.replyLogRepo.UpdateStatusIf(ctx, log.ID, entity.ReplyStatusSending, previous); releaseErr != nil {
+			s.logger.Error("恢复重试回复状态失败", zap.Int("reply_log_id", log.ID), zap.Error(releaseErr))
+		}
+		log.Status = previous
+		return true, err
+	}
+
+	log.RetryCount++
+	reply, err := s.adReplyService.ReplyWithContent(ctx, log.TweetID, log.ReplyContent, log.AdCopyID)
+	applyReplyOutcome(log, reply, err, s.cfg.Retry, time.Now())
+
+	s.logger.Info("重试回复完成",
+		zap.Int("reply_log_id", log.ID),
+		zap.String("status", string(log.Status)),
+		zap.String("failure_reason", log.FailureReason),
+		zap.Int("retry_count", log.RetryCount),
+	)
+
+	if err := s.replyLogRepo.Update(ctx, log); err != nil {
+		s.logger.Error("更新重试结果失败", zap.Int("reply_log_id", log.ID), zap.Error(err))
+		return true, err
+	}
+	return true, nil
+}
+
+// prepare 准备要发送的内容：没有回复内容或上次因重复内容被拒绝时重新生成变体，并替换推广链接
+func (s *retryService) prepare(ctx context.Context, log *entity.ReplyLog) error {
+	// 没有回复内容，或上次因重复内容被拒绝时，重新生成与最近回复不同的变体
+	regenerate := log.ReplyContent == "" || log.FailureReason == string(twitter.FailureDuplicateContent)
+	if regenerate && log.AdCopy != nil {
+		tweet := twitter.Tweet{ID: log.TweetID, AuthorID: log.TweetAuthorID, Text: log.TweetContent, Lang: log.TweetLang}
+		// 回复日志只保存作者ID，作者是监控用户时用其用户名和显示名渲染 {{.Author}}
+		user, err := s.userRepo.GetByTwitterID(ctx, log.TweetAuthorID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		rendered, err := s.adReplyService.RenderVariant(ctx, log.AdCopy, log.TweetLang, buildTemplateData(tweet, user, log.LLMResponse))
+		if err != nil {
+			return err
+		}
+		log.ReplyContent = rendered
+		log.AdCopyRevision = log.AdCopy.Revision
+	}
+
+	// 发送前替换推广链接，保存的回复内容与实际发送的一致
+	log.ReplyContent = s.linkTracker.Rewrite(ctx, log, log.AdCopy)
+	return nil
+}
+
+// applyReplyOutcome 根据发送结果更新回复日志
+// 成功时标记 success；失败时按原因分类，临时性失败且未超过重试次数的安排下一次重试，其余标记为最终失败
+func applyReplyOutcome(log *entity.ReplyLog, reply *twitter.Tweet, err error, cfg config.RetryConfig, now time.Time) {
+	if err == nil {
+		log.Status = entity.ReplyStatusSuccess
+		log.ReplyTweetID = reply.ID
+		log.SentAt = &now
+		log.ErrorMessage = ""
+		log.FailureReason = ""
+		log.NextRetryAt = nil
+		return
+	}
+
+	reason := twitter.ClassifyError(err)
+	log.ErrorMessage = err.Error()
+	log.FailureReason = string(reason)
+
+	// MaxAttempts 为包括首次发送在内的总发送次数，本次是第 RetryCount+1 次
+	if !reason.IsTransient() || log.RetryCount+1 >= cfg.MaxAttempts {
+		log.Status = entity.ReplyStatusFailed
+		log.NextRetryAt = nil
+		return
+	}
+
+	next := now.Add(retryDelay(cfg, log.RetryCount, err))
+	log.Status = entity.ReplyStatusRetryScheduled
+	log.NextRetryAt = &next
+}
+
+// retryDelay 指数退避：base * 2^retryCount，不超过 max；限流时至少等到限流重置
+func retryDelay(cfg config.RetryConfig, retryCount int, err error) time.Duration {
+	delay := cfg.BaseDelay
+	if delay <= 0 {
+		delay = time.Minute
+	}
+	for i := 0; i < retryCount; i++ {
+		delay *= 2
+		if cfg.MaxDelay > 0 && delay >= cfg.MaxDelay {
+			delay = cfg.MaxDelay
+			break
+		}
+	}
+
+	var apiErr *twitter.Error
+	if apperrors.As(err, &apiErr) && !apiErr.RateLimitReset.IsZero() {
+		if wait := time.Until(apiErr.RateLimitReset); wait > delay {
+			delay = wait
+		}
+	}
+	return delay
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/zhoubofsy/x-bot/internal/application/dto"
+	"github.com/zhoubofsy/x-bot/internal/config"
+	"github.com/zhoubofsy/x-bot/internal/infrastructure/llm"
+	"go.uber.org/zap"
+)
+
+// 安全审核风险类别
+const (
+	SafetyCategorySensitiveTopic    = "sensitive_topic"
+	SafetyCategoryNegativeSentiment = "negative_sentiment"
+	SafetyCategoryControversy       = "controversy"
+	SafetyCategoryScam              = "scam"
+)
+
+type SafetyChecker interface {
+	// Check 在回复前审核推文，判断是否适合在其下方回复推广内容
+	Check(ctx context.Context, tweetContent string) (*dto.SafetyCheckResult, error)
+}
+
+type safetyChecker struct {
+	llmClient llm.Client
+	cfg       *config.SafetyConfig
+	logger    *zap.Logger
+}
+
+func NewSafetyChecker(
+	llmClient llm.Client,
+	cfg *config.SafetyConfig,
+	logger *zap.Logger,
+) SafetyChecker {
+	return &safetyChecker{
+		llmClient: llmClient,
+		cfg:       cfg,
+		logger:    logger,
+	}
+}
+
+func (c *safetyChecker) Check(ctx context.Context, tweetContent string) (*dto.SafetyCheckResult, error) {
+	result := &dto.SafetyCheckResult{Safe: true}
+	if !c.cfg.Enabled {
+		return result, nil
+	}
+
+	// 关键词规则：命中即拦截，无需再调用 LLM
+	if category, keyword := c.matchRules(tweetContent); category != "" {
+		result.Safe = false
+		result.Category = category
+		result.Reason = fmt.Sprintf("命中关键词 %q", keyword)
+		c.logBlocked(result)
+		return result, nil
+	}
+
+	if !c.cfg.UseLLM {
+		return result, nil
+	}
+
+	check, rawResponse, err := c.llmClient.CheckSafety(ctx, tweetContent)
+	if err != nil {
+		c.logger.Error("LLM安全审核失败", zap.Error(err))
+		return nil, err
+	}
+	result.RawResponse = rawResponse
+
+	// 只有已启用的类别才会拦截
+	for _, category := range check.Categories {
+		if c.categoryEnabled(category) {
+			result.Safe = false
+			result.Category = category
+			result.Reason = check.Reason
+			c.logBlocked(result)
+			return result, nil
+		}
+	}
+
+	// LLM 判定不安全但给出的类别都未知或未启用（或未给出类别）时，按敏感话题拦截，避免漏放
+	if !check.IsSafe && c.categoryEnabled(SafetyCategorySensitiveTopic) {
+		result.Safe = false
+		result.Category = SafetyCategorySensitiveTopic
+		result.Reason = check.Reason
+		if len(check.Categories) > 0 {
+			result.Reason = fmt.Sprintf("%s (LLM 类别: %s)", check.Reason, strings.Join(check.Categories, ", "))
+		}
+		c.logBlocked(result)
+	}
+
+	return result, nil
+}
+
+// matchRules 按类别名称顺序匹配关键词，返回命中的类别和关键词
+func (c *safetyChecker) matchRules(tweetContent string) (string, string) {
+	text := strings.ToLower(tweetContent)
+
+	categories := make([]string, 0, len(c.cfg.Categories))
+	for category := range c.cfg.Categories {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	for _, category := range categories {
+		rule := c.cfg.Categories[category]
+		if !rule.Enabled {
+			continue
+		}
+		for _, keyword := range rule.Keywords {
+			if containsKeyword(text, strings.ToLower(keyword)) {
+				return category, keyword
+			}
+		}
+	}
+	return "", ""
+}
+
+func (c *safetyChecker) categoryEnabled(category string) bool {
+	rule, ok := c.cfg.Categories[strings.ToLower(category)]
+	return ok && rule.Enabled
+}
+
+func (c *safetyChecker) logBlocked(result *dto.SafetyCheckResult) {
+	c.logger.Info("推文未通过安全审核",
+		zap.String("category", result.Category),
+		zap.String("reason", result.Reason),
+	)
+}
+
+// containsKeyword 判断文本是否包含关键词
+// 英文关键词按单词边界匹配（避免 "rip" 命中 "trip"），其他语言按子串匹配
+func containsKeyword(text, keyword string) bool {
+	if keyword == "" {
+		return false
+	}
+	for start := 0; ; {
+		i := strings.Index(text[start:], keyword)
+		if i < 0 {
+			return false
+		}
+		i += start
+		end := i + len(keyword)
+		if isWordBoundary(text, i, end) {
+			return true
+		}
+		start = i + 1
+	}
+}
+
+func isWordBoundary(text string, start, end int) bool {
+	isASCIIWord := func(b byte) bool {
+		return b < unicode.MaxASCII && (unicode.IsLetter(rune(b)) || unicode.IsDigit(rune(b)))
+	}
+	if isASCIIWord(text[start]) && start > 0 && isASCIIWord(text[start-1]) {
+		return false
+	}
+	if isASCIIWord(text[end-1]) && end < len(text) && isASCIIWord(text[end]) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/zhoubofsy/x-bot/internal/config"
+)
+
+func TestContainsKeyword(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		keyword string
+		want    bool
+	}{
+		{"whole word", "rip to the old api", "rip", true},
+		{"inside a word", "planning a trip to the hackathon", "rip", false},
+		{"prefix of a word", "ripple effect", "rip", false},
+		{"later whole word after a partial match", "trip then rip", "rip", true},
+		{"punctuation boundary", "so sad... rip.", "rip", true},
+		{"digits are word characters", "scam2024 giveaway", "scam", false},
+		{"phrase", "classic pump and dump scheme", "pump and dump", true},
+		{"phrase inside words", "pumpkin and dumpling", "pump and dump", false},
+		{"hashtag", "#scam alert", "scam", true},
+		{"cjk substring", "这是一个诈骗项目", "诈骗", true},
+		{"cjk next to ascii", "airdrop诈骗", "诈骗", true},
+		{"ascii keyword next to cjk", "这个scam太明显", "scam", true},
+		{"empty keyword", "anything", "", false},
+		{"empty text", "", "scam", false},
+		// matchRules 已统一转换为小写，containsKeyword 本身区分大小写
+		{"case sensitive", "Scam", "scam", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := containsKeyword(tt.text, tt.keyword); got != tt.want {
+				t.Errorf("containsKeyword(%q, %q) = %v, want %v", tt.text, tt.keyword, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchRules(t *testing.T) {
+	rules := map[string]config.SafetyCategoryConfig{
+		SafetyCategoryScam:              {Enabled: true, Keywords: []string{"Giveaway", "airdrop"}},
+		SafetyCategorySensitiveTopic:    {Enabled: true, Keywords: []string{"rip", "war"}},
+		SafetyCategoryNegativeSentiment: {Enabled: false, Keywords: []string{"hate"}},
+		SafetyCategoryControversy:       {Enabled: true},
+	}
+
+	tests := []struct {
+		name         string
+		categories   map[string]config.SafetyCategoryConfig
+		text         string
+		wantCategory string
+		wantKeyword  string
+	}{
+		{"no match", rules, "Excited for the hackathon!", "", ""},
+		{"case folded text", rules, "FREE AIRDROP for everyone", SafetyCategoryScam, "airdrop"},
+		{"case folded keyword", rules, "join the giveaway", SafetyCategoryScam, "Giveaway"},
+		{"word boundary", rules, "what a trip, software is hard", "", ""},
+		{"disabled category", rules, "I hate deadlines", "", ""},
+		{"category without keywords", rules, "controversy everywhere", "", ""},
+		// 按类别名称顺序匹配：scam 在 sensitive_topic 之前
+		{"categories in name order", rules, "rip, the airdrop was fake", SafetyCategoryScam, "airdrop"},
+		{"keywords in rule order", rules, "giveaway and airdrop", SafetyCategoryScam, "Giveaway"},
+		{"empty rule set", map[string]config.SafetyCategoryConfig{}, "free airdrop", "", ""},
+		{"nil rule set", nil, "free airdrop", "", ""},
+		{"empty text", rules, "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := &safetyChecker{cfg: &config.SafetyConfig{Enabled: true, Categories: tt.categories}}
+			category, keyword := checker.matchRules(tt.text)
+			if category != tt.wantCategory || keyword != tt.wantKeyword {
+				t.Errorf("matchRules(%q) = (%q, %q), want (%q, %q)", tt.text, category, keyword, tt.wantCategory, tt.wantKeyword)
+			}
+		})
+	}
+}
@@ -0,0 +1,336 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/zhoubofsy/x-bot/internal/application/dto"
+	"github.com/zhoubofsy/x-bot/internal/config"
+	"github.com/zhoubofsy/x-bot/internal/infrastructure/twitter"
+	apperrors "github.com/zhoubofsy/x-bot/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// stableConnection 连接保持超过该时长后视为稳定，重置退避时间
+const stableConnection = time.Minute
+
+type StreamService interface {
+	// Start 启动后台消费（同步配置中的规则后连接 filtered stream，断开后自动重连）
+	Start() error
+
+	// Stop 停止消费并等待正在处理的推文完成
+	Stop()
+
+	// Status 获取当前消费状态
+	Status() *dto.StreamStatus
+
+	// ListRules 获取当前生效的流规则
+	ListRules(ctx context.Context) ([]twitter.StreamRule, error)
+
+	// AddRule 添加流规则，立即对已建立的连接生效
+	AddRule(ctx context.Context, value, tag string) (*twitter.StreamRule, error)
+
+	// DeleteRule 删除流规则
+	DeleteRule(ctx context.Context, id string) error
+}
+
+type streamService struct {
+	streamClient    twitter.StreamClient
+	workflowService WorkflowService
+	cfg             *config.StreamConfig
+	logger          *zap.Logger
+
+	mu      sync.Mutex
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	status  dto.StreamStatus
+	tweets  chan twitter.Tweet
+	counter struct {
+		received  atomic.Int64
+		processed atomic.Int64
+		dropped   atomic.Int64
+	}
+}
+
+func NewStreamService(
+	streamClient twitter.StreamClient,
+	workflowService WorkflowService,
+	cfg *config.StreamConfig,
+	logger *zap.Logger,
+) StreamService {
+	return &streamService{
+		streamClient:    streamClient,
+		workflowService: workflowService,
+		cfg:             cfg,
+		logger:          logger,
+	}
+}
+
+func (s *streamService) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel != nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	if err := s.syncRules(ctx); err != nil {
+		cancel()
+		return err
+	}
+
+	queueSize := s.cfg.QueueSize
+	if queueSize <= 0 {
+		queueSize = 100
+	}
+	s.tweets = make(chan twitter.Tweet, queueSize)
+	s.cancel = cancel
+	s.status = dto.StreamStatus{Running: true}
+
+	s.wg.Add(2)
+	go s.consume(ctx)
+	go s.process(ctx)
+
+	s.logger.Info("filtered stream 消费已启动", zap.Int("queue_size", queueSize))
+	return nil
+}
+
+func (s *streamService) Stop() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.cancel = nil
+	s.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+
+	cancel()
+	s.wg.Wait()
+
+	s.mu.Lock()
+	s.status.Running = false
+	s.status.Connected = false
+	s.mu.Unlock()
+
+	s.logger.Info("filtered stream 消费已停止")
+}
+
+func (s *streamService) Status() *dto.StreamStatus {
+	s.mu.Lock()
+	status := s.status
+	s.mu.Unlock()
+
+	status.ReceivedTweets = s.counter.received.Load()
+	status.ProcessedTweets = s.counter.processed.Load()
+	status.DroppedTweets = s.counter.dropped.Load()
+	return &status
+}
+
+func (s *streamService) ListRules(ctx context.Context) ([]twitter.StreamRule, error) {
+	return s.streamClient.GetRules(ctx)
+}
+
+func (s *streamService) AddRule(ctx context.Context, value, tag string) (*twitter.StreamRule, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, apperrors.Wrap(apperrors.ErrInvalidInput, "EMPTY_RULE", "规则内容不能为空")
+	}
+
+	created, err := s.streamClient.AddRules(ctx, []twitter.StreamRule{{Value: value, Tag: tag}})
+	if err != nil {
+		return nil, err
+	}
+	if len(created) == 0 {
+		return nil, apperrors.Wrap(apperrors.ErrAlreadyExists, "RULE_EXISTS", "规则已存在")
+	}
+	return &created[0], nil
+}
+
+func (s *streamService) DeleteRule(ctx context.Context, id string) error {
+	return s.streamClient.DeleteRules(ctx, []string{id})
+}
+
+// syncRules 确保配置中的规则都已生效；开启 prune_rules 时删除配置之外的规则
+func (s *streamService) syncRules(ctx context.Context) error {
+	existing, err := s.streamClient.GetRules(ctx)
+	if err != nil {
+		return err
+	}
+
+	wanted := make(map[string]bool, len(s.cfg.Rules))
+	for _, rule := range s.cfg.Rules {
+		wanted[rule.Value] = true
+	}
+
+	have := make(map[string]bool, len(existing))
+	var stale []string
+	for _, rule := range existing {
+		have[rule.Value] = true
+		if !wanted[rule.Value] {
+			stale = append(stale, rule.ID)
+		}
+	}
+
+	var missing []twitter.StreamRule
+	for _, rule := range s.cfg.Rules {
+		if !have[rule.Value] {
+			missing = append(missing, twitter.StreamRule{Value: rule.Value, Tag: rule.Tag})
+		}
+	}
+
+	if len(missing) > 0 {
+		if _, err := s.streamClient.AddRules(ctx, missing); err != nil {
+			return err
+		}
+	}
+	pruned := 0
+	if s.cfg.PruneRules && len(stale) > 0 {
+		if err := s.streamClient.DeleteRules(ctx, stale); err != nil {
+			return err
+		}
+		pruned = len(stale)
+	}
+
+	s.logger.Info("流规则同步完成",
+		zap.Int("existing", len(existing)),
+		zap.Int("added", len(missing)),
+		zap.Int("pruned", pruned),
+	)
+	return nil
+}
+
+// consume 保持与 filtered stream 的连接，断开后按退避时间重连
+func (s *streamService) consume(ctx context.Context) {
+	defer s.wg.Done()
+	defer close(s.tweets)
+
+	heartbeatTimeout := s.cfg.HeartbeatTimeout
+	if heartbeatTimeout <= 0 {
+		heartbeatTimeout = 30 * time.Second
+	}
+
+	attempt := 0
+	for {
+		started := time.Now()
+		err := s.streamClient.Connect(ctx, heartbeatTimeout, s.onConnected, s.enqueue)
+		if ctx.Err() != nil {
+			return
+		}
+
+		s.mu.Lock()
+		s.status.Connected = false
+		s.status.ConnectedAt = nil
+		s.status.Reconnects++
+		if err != nil {
+			s.status.LastError = err.Error()
+		}
+		s.mu.Unlock()
+
+		if time.Since(started) >= stableConnection {
+			attempt = 0
+		}
+		delay := s.backoff(attempt, err)
+		attempt++
+
+		s.logger.Warn("filtered stream 连接断开，准备重连",
+			zap.Error(err),
+			zap.Duration("backoff", delay),
+		)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// backoff 指数退避：initial * 2^attempt，不超过 max；心跳超时立即重连，限流时至少等待 1 分钟或到限流重置
+func (s *streamService) backoff(attempt int, err error) time.Duration {
+	if apperrors.Is(err, twitter.ErrStreamStalled) && attempt == 0 {
+		return 0
+	}
+
+	delay := s.cfg.InitialBackoff
+	if delay <= 0 {
+		delay = 5 * time.Second
+	}
+	for i := 0; i < attempt; i++ {
+		delay *= 2
+		if s.cfg.MaxBackoff > 0 && delay >= s.cfg.MaxBackoff {
+			delay = s.cfg.MaxBackoff
+			break
+		}
+	}
+
+	if twitter.ClassifyError(err) == twitter.FailureRateLimited {
+		if delay < time.Minute {
+			delay = time.Minute
+		}
+		var apiErr *twitter.Error
+		if apperrors.As(err, &apiErr) && !apiErr.RateLimitReset.IsZero() {
+			if wait := time.Until(apiErr.RateLimitReset); wait > delay {
+				delay = wait
+			}
+		}
+	}
+	return delay
+}
+
+func (s *streamService) onConnected() {
+	now := time.Now()
+
+	s.mu.Lock()
+	s.status.Connected = true
+	s.status.ConnectedAt = &now
+	s.status.LastError = ""
+	s.mu.Unlock()
+
+	s.logger.Info("filtered stream 已连接")
+}
+
+// enqueue 将推文放入处理队列；队列已满时丢弃，避免阻塞读取导致心跳超时
+func (s *streamService) enqueue(st twitter.StreamTweet) {
+	s.counter.received.Add(1)
+
+	select {
+	case s.tweets <- st.Tweet:
+	default:
+		s.counter.dropped.Add(1)
+		s.logger.Warn("处理队列已满，丢弃推文",
+			zap.String("tweet_id", st.Tweet.ID),
+			zap.Strings("matching_tags", st.MatchingTags),
+		)
+	}
+}
+
+// process 逐条将推文交给工作流处理
+func (s *streamService) process(ctx context.Context) {
+	defer s.wg.Done()
+
+	for tweet := range s.tweets {
+		if ctx.Err() != nil {
+			return
+		}
+
+		pr := s.workflowService.ProcessTweet(ctx, tweet, s.cfg.DryRun)
+		s.counter.processed.Add(1)
+
+		if pr.Error != nil {
+			s.logger.Error("处理流推文失败", zap.String("tweet_id", tweet.ID), zap.Error(pr.Error))
+			continue
+		}
+		s.logger.Debug("流推文处理完成",
+			zap.String("tweet_id", tweet.ID),
+			zap.Bool("is_hackathon", pr.IsHackathon),
+			zap.Bool("success", pr.Success),
+			zap.Bool("skipped", pr.Skipped),
+		)
+	}
+}
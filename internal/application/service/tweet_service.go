@@ -4,12 +4,19 @@ import (
 	"context"
 
 	"github.com/zhoubofsy/x-bot/internal/infrastructure/twitter"
+	"github.com/zhoubofsy/x-bot/pkg/langdetect"
 	"go.uber.org/zap"
 )
 
 type TweetService interface {
-	// GetUserTweets 获取指定用户的最新推文
+	// GetUserTweets 获取指定用户的最新推文（已填充语言字段）
 	GetUserTweets(ctx context.Context, userID string, count int) ([]twitter.Tweet, error)
+
+	// SearchRecentTweets 按搜索条件获取 sinceID 之后的新推文（已填充语言字段）
+	SearchRecentTweets(ctx context.Context, query string, sinceID string, count int) (*twitter.SearchResult, error)
+
+	// GetListTweets 获取列表时间线的最新推文（已填充语言字段）
+	GetListTweets(ctx context.Context, listID string, count int) ([]twitter.Tweet, error)
 }
 
 type tweetService struct {
@@ -37,6 +44,10 @@ func (s *tweetService) GetUserTweets(ctx context.Context, userID string, count i
 		return nil, err
 	}
 
+	for i := range tweets {
+		tweets[i].Lang = langdetect.Resolve(tweets[i].Lang, tweets[i].Text)
+	}
+
 	s.logger.Debug("获取用户推文成功",
 		zap.String("user_id", userID),
 		zap.Int("count", len(tweets)),
@@ -45,3 +56,46 @@ func (s *tweetService) GetUserTweets(ctx context.Context, userID string, count i
 	return tweets, nil
 }
 
+func (s *tweetService) SearchRecentTweets(ctx context.Context, query string, sinceID string, count int) (*twitter.SearchResult, error) {
+	result, err := s.twitterClient.SearchRecent(ctx, query, sinceID, count)
+	if err != nil {
+		s.logger.Error("搜索推文失败",
+			zap.String("query", query),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	for i := range result.Tweets {
+		result.Tweets[i].Lang = langdetect.Resolve(result.Tweets[i].Lang, result.Tweets[i].Text)
+	}
+
+	s.logger.Debug("搜索推文成功",
+		zap.String("query", query),
+		zap.Int("count", len(result.Tweets)),
+	)
+
+	return result, nil
+}
+
+func (s *tweetService) GetListTweets(ctx context.Context, listID string, count int) ([]twitter.Tweet, error) {
+	tweets, err := s.twitterClient.GetListTweets(ctx, listID, count)
+	if err != nil {
+		s.logger.Error("获取列表推文失败",
+			zap.String("list_id", listID),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	for i := range tweets {
+		tweets[i].Lang = langdetect.Resolve(tweets[i].Lang, tweets[i].Text)
+	}
+
+	s.logger.Debug("获取列表推文成功",
+		zap.String("list_id", listID),
+		zap.Int("count", len(tweets)),
+	)
+
+	return tweets, nil
+}
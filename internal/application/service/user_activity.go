@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zhoubofsy/x-bot/internal/application/dto"
+	"github.com/zhoubofsy/x-bot/internal/config"
+	"github.com/zhoubofsy/x-bot/internal/domain/entity"
+	"github.com/zhoubofsy/x-bot/internal/domain/repository"
+	"github.com/zhoubofsy/x-bot/internal/infrastructure/twitter"
+	"go.uber.org/zap"
+)
+
+// 休眠或低价值的原因类型，保存在 dormant_reason 的冒号之前
+const (
+	dormantKindDormant   = "dormant"
+	dormantKindLowSignal = "low_signal"
+)
+
+// 休眠或低价值用户的处理方式
+const (
+	activityActionDeactivate   = "deactivate"
+	activityActionDeprioritize = "deprioritize"
+)
+
+type UserActivityService interface {
+	// Due 判断是否到了用户的下次拉取时间
+	Due(user *entity.FollowedUser, now time.Time) bool
+
+	// Record 记录一次拉取中新出现的推文，以及其中经过黑客松检测的推文数和命中数，并计算下次拉取时间
+	// 被资格规则、频率限制等提前跳过的推文只推进游标，不计入命中率
+	Record(ctx context.Context, user *entity.FollowedUser, newTweets []twitter.Tweet, detected, hits int) error
+
+	// Evaluate 按配置阈值停用或降低休眠、低价值用户的拉取频率，不再满足条件的用户恢复正常
+	Evaluate(ctx context.Context) (*dto.ActivityEvaluationResult, error)
+}
+
+type userActivityService struct {
+	userRepo repository.UserRepository
+	cfg      *config.ActivityConfig
+	logger   *zap.Logger
+}
+
+func NewUserActivityService(
+	userRepo repository.UserRepository,
+	cfg *config.ActivityConfig,
+	logger *zap.Logger,
+) UserActivityService {
+	return &userActivityService{
+		userRepo: userRepo,
+		cfg:      cfg,
+		logger:   logger,
+	}
+}
+
+func (s *userActivityService) Due(user *entity.FollowedUser, now time.Time) bool {
+	if s.cfg.MaxPollInterval <= 0 || user.NextCheckAt == nil {
+		return true
+	}
+	return !now.Before(*user.NextCheckAt)
+}
+
+func (s *userActivityService) Record(ctx context.Context, user *entity.FollowedUser, newTweets []twitter.Tweet, detected, hits int) error {
+	now := time.Now()
+	check := repository.UserCheck{
+		TweetsSeen:    detected,
+		HackathonHits: hits,
+		CheckedAt:     now,
+	}
+	for _, tweet := range newTweets {
+		if isNewerTweetID(tweet.ID, check.LastSeenTweetID) {
+			check.LastSeenTweetID = tweet.ID
+		}
+		if !tweet.CreatedAt.IsZero() && (check.LastTweetAt == nil || tweet.CreatedAt.After(*check.LastTweetAt)) {
+			createdAt := tweet.CreatedAt
+			check.LastTweetAt = &createdAt
+		}
+	}
+
+	user.TweetsSeen += check.TweetsSeen
+	user.HackathonHits += check.HackathonHits
+	user.LastCheckedAt = &now
+	if check.LastSeenTweetID != "" {
+		user.LastSeenTweetID = check.LastSeenTweetID
+	}
+
+	if s.cfg.MaxPollInterval > 0 {
+		next := now.Add(s.pollInterval(user))
+		check.NextCheckAt = &next
+	}
+	user.NextCheckAt = check.NextCheckAt
+
+	return s.userRepo.RecordCheck(ctx, user.TwitterUserID, check)
+}
+
+// pollInterval 自适应拉取间隔：样本不足或命中率达到目标时使用最短间隔，
+// 被标记为休眠/低价值时使用最长间隔，其余按命中率在两者之间线性插值
+func (s *userActivityService) pollInterval(user *entity.FollowedUser) time.Duration {
+	minInterval, maxInterval := s.cfg.MinPollInterval, s.cfg.MaxPollInterval
+	if maxInterval <= minInterval {
+		return minInterval
+	}
+	if user.DormantReason != "" {
+		return maxInterval
+	}
+	if user.TweetsSeen < s.cfg.MinTweetsSeen || s.cfg.TargetHitRate <= 0 {
+		return minInterval
+	}
+
+	rate := user.HitRate()
+	if rate >= s.cfg.TargetHitRate {
+		return minInterval
+	}
+	return maxInterval - time.Duration(float64(maxInterval-minInterval)*rate/s.cfg.TargetHitRate)
+}
+
+func (s *userActivityService) Evaluate(ctx context.Context) (*dto.ActivityEvaluationResult, error) {
+	users, err := s.userRepo.GetAllActiveUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deactivate := s.cfg.Action != activityActionDeprioritize
+	result := &dto.ActivityEvaluationResult{}
+	now := time.Now()
+
+	for _, user := range users {
+		if s.cfg.ExemptManual && user.Source == entity.UserSourceManual {
+			continue
+		}
+		result.TotalCount++
+
+		// 只在原因类型变化时更新，原因中的天数、命中率每次评估都会变化
+		reason := s.dormantReason(user, now)
+		if dormantKind(reason) == dormantKind(user.DormantReason) {
+			continue
+		}
+
+		if err := s.userRepo.MarkDormant(ctx, user.TwitterUserID, reason, deactivate && reason != ""); err != nil {
+			s.logger.Error("更新用户活跃度状态失败",
+				zap.String("user_id", user.TwitterUserID),
+				zap.Error(err),
+			)
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", user.TwitterUserID, err))
+			continue
+		}
+
+		switch {
+		case reason == "":
+			result.RestoredCount++
+		case deactivate:
+			result.DeactivatedCount++
+		default:
+			result.DeprioritizedCount++
+		}
+		s.logger.Info("用户活跃度状态变更",
+			zap.String("user_id", user.TwitterUserID),
+			zap.String("username", user.Username),
+			zap.String("reason", reason),
+			zap.Bool("deactivated", deactivate && reason != ""),
+		)
+	}
+
+	s.logger.Info("用户活跃度评估完成",
+		zap.Int("total", result.TotalCount),
+		zap.Int("deactivated", result.DeactivatedCount),
+		zap.Int("deprioritized", result.DeprioritizedCount),
+		zap.Int("restored", result.RestoredCount),
+	)
+
+	return result, nil
+}
+
+// dormantReason 判断用户是否休眠或低价值，返回原因，正常时返回空
+func (s *userActivityService) dormantReason(user *entity.FollowedUser, now time.Time) string {
+	if s.cfg.DormantAfter > 0 {
+		// 从未见过推文时从添加时间起算，给新用户留出观察期
+		last := user.CreatedAt
+		if user.LastTweetAt != nil {
+			last = *user.LastTweetAt
+		}
+		if idle := now.Sub(last); idle > s.cfg.DormantAfter {
+			return fmt.Sprintf("%s: %d 天未发推", dormantKindDormant, int(idle.Hours()/24))
+		}
+	}
+
+	if s.cfg.MinHitRate > 0 && s.cfg.MinTweetsSeen > 0 && user.TweetsSeen >= s.cfg.MinTweetsSeen {
+		if rate := user.HitRate(); rate < s.cfg.MinHitRate {
+			return fmt.Sprintf("%s: 命中率 %.2f%%（%d/%d）", dormantKindLowSignal, rate*100, user.HackathonHits, user.TweetsSeen)
+		}
+	}
+
+	return ""
+}
+
+// dormantKind 返回休眠原因的类型（冒号之前的部分），正常时返回空
+func dormantKind(reason string) string {
+	kind, _, _ := strings.Cut(reason, ":")
+	return kind
+}
+
+// isNewerTweetID 比较两个推文ID（snowflake，位数多的更新，位数相同时按字典序）
+func isNewerTweetID(id, than string) bool {
+	if len(id) != len(than) {
+		return len(id) > len(than)
+	}
+	return id > than
+}
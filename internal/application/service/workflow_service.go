@@ -2,47 +2,78 @@ package service
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/zhoubofsy/x-bot/internal/application/dto"
 	"github.com/zhoubofsy/x-bot/internal/config"
 	"github.com/zhoubofsy/x-bot/internal/domain/entity"
 	"github.com/zhoubofsy/x-bot/internal/domain/repository"
 	"github.com/zhoubofsy/x-bot/internal/infrastructure/twitter"
+	"github.com/zhoubofsy/x-bot/pkg/langdetect"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 type WorkflowService interface {
 	// Execute 执行完整工作流
 	Execute(ctx context.Context, params dto.WorkflowParams) (*dto.WorkflowResult, error)
+
+	// ProcessTweet 处理单条外部推送的推文（如 filtered stream），走与工作流相同的处理流程
+	ProcessTweet(ctx context.Context, tweet twitter.Tweet, dryRun bool) dto.ProcessResult
 }
 
 type workflowService struct {
-	followerService   FollowerService
-	tweetService      TweetService
-	hackathonDetector HackathonDetector
-	adReplyService    AdReplyService
-	replyLogRepo      repository.ReplyLogRepository
-	cfg               *config.WorkflowConfig
-	logger            *zap.Logger
+	followerService    FollowerService
+	userActivity       UserActivityService
+	tweetService       TweetService
+	eligibilityChecker EligibilityChecker
+	authorPolicy       AuthorPolicyChecker
+	groupTargeting     GroupTargeting
+	campaignTargeting  CampaignTargeting
+	hackathonDetector  HackathonDetector
+	safetyChecker      SafetyChecker
+	adReplyService     AdReplyService
+	linkTracker        LinkTracker
+	replyLogRepo       repository.ReplyLogRepository
+	searchQueryRepo    repository.SearchQueryRepository
+	cfg                *config.WorkflowConfig
+	logger             *zap.Logger
 }
 
 func NewWorkflowService(
 	followerService FollowerService,
+	userActivity UserActivityService,
 	tweetService TweetService,
+	eligibilityChecker EligibilityChecker,
+	authorPolicy AuthorPolicyChecker,
+	groupTargeting GroupTargeting,
+	campaignTargeting CampaignTargeting,
 	hackathonDetector HackathonDetector,
+	safetyChecker SafetyChecker,
 	adReplyService AdReplyService,
+	linkTracker LinkTracker,
 	replyLogRepo repository.ReplyLogRepository,
+	searchQueryRepo repository.SearchQueryRepository,
 	cfg *config.WorkflowConfig,
 	logger *zap.Logger,
 ) WorkflowService {
 	return &workflowService{
-		followerService:   followerService,
-		tweetService:      tweetService,
-		hackathonDetector: hackathonDetector,
-		adReplyService:    adReplyService,
-		replyLogRepo:      replyLogRepo,
-		cfg:               cfg,
-		logger:            logger,
+		followerService:    followerService,
+		userActivity:       userActivity,
+		tweetService:       tweetService,
+		eligibilityChecker: eligibilityChecker,
+		authorPolicy:       authorPolicy,
+		groupTargeting:     groupTargeting,
+		campaignTargeting:  campaignTargeting,
+		hackathonDetector:  hackathonDetector,
+		safetyChecker:      safetyChecker,
+		adReplyService:     adReplyService,
+		linkTracker:        linkTracker,
+		replyLogRepo:       replyLogRepo,
+		searchQueryRepo:    searchQueryRepo,
+		cfg:                cfg,
+		logger:             logger,
 	}
 }
 
@@ -69,32 +100,134 @@ func (s *workflowService) Execute(ctx context.Context, params dto.WorkflowParams
 
 	s.logger.Info("获取关注用户完成", zap.Int("count", len(users)))
 
-	// Step 2 & 3 & 4: 遍历用户并处理推文
+	// 保存的搜索条件，发现未关注账号的推文
+	queries, err := s.searchQueryRepo.GetActive(ctx)
+	if err != nil {
+		s.logger.Error("获取搜索条件失败", zap.Error(err))
+		result.Errors = append(result.Errors, err.Error())
+	}
+	result.TotalQueries = len(queries)
+
+	// 搜索结果的作者是监控用户时，同样按该用户的回复策略、分组和活动处理
+	monitored := make(map[string]*entity.FollowedUser, len(users))
 	for _, user := range users {
-		if err := s.processUserTweets(ctx, user, params, result); err != nil {
-			s.logger.Error("处理用户推文失败",
-				zap.String("user_id", user.TwitterUserID),
-				zap.Error(err),
-			)
-			result.Errors = append(result.Errors, err.Error())
+		monitored[user.TwitterUserID] = user
+	}
+
+	// 投放中的推广活动：推文作者所属分组或来源搜索条件被活动投放时，使用活动的分类、文案和预算
+	campaigns, err := s.campaignTargeting.Running(ctx)
+	if err != nil {
+		s.logger.Error("获取推广活动失败", zap.Error(err))
+		result.Errors = append(result.Errors, err.Error())
+	}
+	result.ActiveCampaigns = len(campaigns)
+
+	// 开启列表时间线后，来源为 X 列表的用户改为按列表统一拉取
+	listMembers := make(map[string]*entity.FollowedUser)
+	handledUsers := make(map[string]bool, len(users))
+	handledQueries := make(map[int]bool, len(queries))
+
+	// Step 2: 按优先级遍历投放中的活动，处理活动投放的分组成员和搜索条件；
+	// 同时被多个活动投放的用户和搜索条件只由优先级最高的活动处理
+	for _, campaign := range campaigns {
+		s.logger.Info("处理推广活动",
+			zap.Int("campaign_id", campaign.ID),
+			zap.String("name", campaign.Name),
+			zap.String("category", campaign.Category),
+		)
+		for _, user := range users {
+			if handledUsers[user.TwitterUserID] || !campaign.TargetsUser(user) {
+				continue
+			}
+			handledUsers[user.TwitterUserID] = true
+			s.processUser(ctx, user, campaign, listMembers, params, result)
+		}
+		for _, query := range queries {
+			if handledQueries[query.ID] || !campaign.TargetsSearchQuery(query.ID) {
+				continue
+			}
+			handledQueries[query.ID] = true
+			s.processQuery(ctx, query, monitored, campaign, campaigns, params, result)
+		}
+	}
+
+	// Step 3 & 4: 遍历不属于任何活动的用户并处理推文
+	for _, user := range users {
+		if !handledUsers[user.TwitterUserID] {
+			s.processUser(ctx, user, nil, listMembers, params, result)
+		}
+	}
+
+	// 列表时间线中的推文按作者所属分组确定活动
+	if len(listMembers) > 0 {
+		for _, listID := range s.cfg.Lists.IDs {
+			result.TotalLists++
+			if err := s.processListTimeline(ctx, listID, listMembers, campaigns, params, result); err != nil {
+				s.logger.Error("处理列表时间线失败",
+					zap.String("list_id", listID),
+					zap.Error(err),
+				)
+				result.Errors = append(result.Errors, err.Error())
+			}
+		}
+	}
+
+	// Step 5: 执行不属于任何活动的搜索条件
+	for _, query := range queries {
+		if !handledQueries[query.ID] {
+			s.processQuery(ctx, query, monitored, nil, campaigns, params, result)
 		}
 	}
 
 	s.logger.Info("工作流执行完成",
 		zap.Int("total_users", result.TotalUsers),
+		zap.Int("deferred_users", result.DeferredUsers),
+		zap.Int("total_queries", result.TotalQueries),
+		zap.Int("total_lists", result.TotalLists),
+		zap.Int("active_campaigns", result.ActiveCampaigns),
 		zap.Int("total_tweets", result.TotalTweets),
 		zap.Int("hackathon_tweets", result.HackathonTweets),
 		zap.Int("successful_replies", result.SuccessfulReplies),
 		zap.Int("failed_replies", result.FailedReplies),
 		zap.Int("skipped_tweets", result.SkippedTweets),
+		zap.Int("blocked_tweets", result.BlockedTweets),
+		zap.Int("pending_approvals", result.PendingApprovals),
 	)
 
 	return result, nil
 }
 
+// processUser 处理单个监控用户的推文，campaign 为投放到该用户的活动（没有时为 nil）
+// 来源为 X 列表的用户留给列表时间线处理；自适应拉取：低命中率用户未到下次拉取时间时跳过
+func (s *workflowService) processUser(
+	ctx context.Context,
+	user *entity.FollowedUser,
+	campaign *entity.Campaign,
+	listMembers map[string]*entity.FollowedUser,
+	params dto.WorkflowParams,
+	result *dto.WorkflowResult,
+) {
+	if s.cfg.Lists.UseTimeline && user.ListID() != "" {
+		listMembers[user.TwitterUserID] = user
+		return
+	}
+	if !s.userActivity.Due(user, time.Now()) {
+		result.DeferredUsers++
+		return
+	}
+	if err := s.processUserTweets(ctx, user, campaign, params, result); err != nil {
+		s.logger.Error("处理用户推文失败",
+			zap.String("user_id", user.TwitterUserID),
+			zap.Error(err),
+		)
+		result.Errors = append(result.Errors, err.Error())
+	}
+}
+
 func (s *workflowService) processUserTweets(
 	ctx context.Context,
 	user *entity.FollowedUser,
+	campaign *entity.Campaign,
 	params dto.WorkflowParams,
 	result *dto.WorkflowResult,
 ) error {
@@ -106,22 +239,184 @@ func (s *workflowService) processUserTweets(
 	result.TotalTweets += len(tweets)
 
 	// 处理每条推文
+	var newTweets []twitter.Tweet
+	detected, hits := 0, 0
 	for _, tweet := range tweets {
-		processResult := s.processSingleTweet(ctx, tweet, params.DryRun)
+		processResult := s.processSingleTweet(ctx, tweet, user, campaign, params.DryRun)
 		s.updateResult(result, processResult)
 
+		// 只统计上次拉取之后的新推文；命中率只按经过黑客松检测的推文计算
+		if isNewerTweetID(tweet.ID, user.LastSeenTweetID) {
+			newTweets = append(newTweets, tweet)
+			if processResult.Detected {
+				detected++
+			}
+			if processResult.IsHackathon {
+				hits++
+			}
+		}
+
 		// 回复间隔
 		// if processResult.Success && !params.DryRun {
 		// 	time.Sleep(s.cfg.ReplyInterval)
 		// }
 	}
 
+	s.recordActivity(ctx, user, newTweets, detected, hits, params.DryRun)
+
+	return nil
+}
+
+// recordActivity 更新用户推文统计（dry run 不更新），失败只记录日志
+func (s *workflowService) recordActivity(ctx context.Context, user *entity.FollowedUser, newTweets []twitter.Tweet, detected, hits int, dryRun bool) {
+	if dryRun {
+		return
+	}
+	if err := s.userActivity.Record(ctx, user, newTweets, detected, hits); err != nil {
+		s.logger.Error("更新用户推文统计失败",
+			zap.String("user_id", user.TwitterUserID),
+			zap.Error(err),
+		)
+	}
+}
+
+// processListTimeline 拉取列表时间线，只处理活跃的列表来源用户发布的推文
+func (s *workflowService) processListTimeline(
+	ctx context.Context,
+	listID string,
+	members map[string]*entity.FollowedUser,
+	campaigns []*entity.Campaign,
+	params dto.WorkflowParams,
+	result *dto.WorkflowResult,
+) error {
+	count := s.cfg.Lists.TimelineCount
+	if count <= 0 {
+		count = 100
+	}
+
+	tweets, err := s.tweetService.GetListTweets(ctx, listID, count)
+	if err != nil {
+		return err
+	}
+
+	newTweets := make(map[string][]twitter.Tweet)
+	detected := make(map[string]int)
+	hits := make(map[string]int)
+	for _, tweet := range tweets {
+		user, ok := members[tweet.AuthorID]
+		if !ok {
+			continue
+		}
+		result.TotalTweets++
+
+		campaign := s.campaignTargeting.Resolve(campaigns, user, 0)
+		processResult := s.processSingleTweet(ctx, tweet, user, campaign, params.DryRun)
+		s.updateResult(result, processResult)
+
+		if isNewerTweetID(tweet.ID, user.LastSeenTweetID) {
+			newTweets[user.TwitterUserID] = append(newTweets[user.TwitterUserID], tweet)
+			if processResult.Detected {
+				detected[user.TwitterUserID]++
+			}
+			if processResult.IsHackathon {
+				hits[user.TwitterUserID]++
+			}
+		}
+	}
+
+	for userID, seen := range newTweets {
+		s.recordActivity(ctx, members[userID], seen, detected[userID], hits[userID], params.DryRun)
+	}
+
 	return nil
 }
 
+// processQuery 执行搜索条件，失败只记录到结果
+func (s *workflowService) processQuery(
+	ctx context.Context,
+	query *entity.SearchQuery,
+	monitored map[string]*entity.FollowedUser,
+	campaign *entity.Campaign,
+	campaigns []*entity.Campaign,
+	params dto.WorkflowParams,
+	result *dto.WorkflowResult,
+) {
+	if err := s.processSearchQuery(ctx, query, monitored, campaign, campaigns, params, result); err != nil {
+		s.logger.Error("处理搜索条件失败",
+			zap.Int("query_id", query.ID),
+			zap.String("query", query.Query),
+			zap.Error(err),
+		)
+		result.Errors = append(result.Errors, err.Error())
+	}
+}
+
+// processSearchQuery 执行搜索条件并处理结果，全部推文处理成功后才推进 since_id 游标
+// campaign 为投放到该搜索条件的活动；为 nil 时按推文作者所属分组确定活动
+func (s *workflowService) processSearchQuery(
+	ctx context.Context,
+	query *entity.SearchQuery,
+	monitored map[string]*entity.FollowedUser,
+	campaign *entity.Campaign,
+	campaigns []*entity.Campaign,
+	params dto.WorkflowParams,
+	result *dto.WorkflowResult,
+) error {
+	count := query.MaxResults
+	if count <= 0 {
+		count = params.TweetCount
+	}
+
+	searchResult, err := s.tweetService.SearchRecentTweets(ctx, query.Query, query.SinceID, count)
+	if err != nil {
+		return err
+	}
+	result.TotalTweets += len(searchResult.Tweets)
+
+	failed := false
+	for _, tweet := range searchResult.Tweets {
+		author := monitored[tweet.AuthorID]
+		tweetCampaign := campaign
+		if tweetCampaign == nil {
+			tweetCampaign = s.campaignTargeting.Resolve(campaigns, author, 0)
+		}
+		processResult := s.processSingleTweet(ctx, tweet, author, tweetCampaign, params.DryRun)
+		s.updateResult(result, processResult)
+		if processResult.Error != nil {
+			failed = true
+		}
+	}
+
+	// 有推文处理失败时保留游标，下次重新拉取（已处理的推文会被去重跳过）
+	if params.DryRun || failed {
+		return nil
+	}
+	return s.searchQueryRepo.UpdateCursor(ctx, query.ID, searchResult.NewestID, time.Now())
+}
+
+func (s *workflowService) ProcessTweet(ctx context.Context, tweet twitter.Tweet, dryRun bool) dto.ProcessResult {
+	tweet.Lang = langdetect.Resolve(tweet.Lang, tweet.Text)
+
+	// 推文作者是监控用户时，与时间线推文一样按该用户的回复策略、分组和活动处理
+	user, err := s.followerService.GetActiveFollower(ctx, tweet.AuthorID)
+	if err != nil {
+		return dto.ProcessResult{TweetID: tweet.ID, Error: err}
+	}
+	campaigns, err := s.campaignTargeting.Running(ctx)
+	if err != nil {
+		return dto.ProcessResult{TweetID: tweet.ID, Error: err}
+	}
+	campaign := s.campaignTargeting.Resolve(campaigns, user, 0)
+	return s.processSingleTweet(ctx, tweet, user, campaign, dryRun)
+}
+
+// processSingleTweet 处理单条推文，user 为推文作者对应的监控用户（非监控来源时为 nil），
+// campaign 为推文生效的推广活动（没有时为 nil）
 func (s *workflowService) processSingleTweet(
 	ctx context.Context,
 	tweet twitter.Tweet,
+	user *entity.FollowedUser,
+	campaign *entity.Campaign,
 	dryRun bool,
 ) dto.ProcessResult {
 	pr := dto.ProcessResult{TweetID: tweet.ID}
@@ -141,14 +436,104 @@ func (s *workflowService) processSingleTweet(
 		return pr
 	}
 
-	// LLM 检测
-	isHackathon, llmResponse, err := s.hackathonDetector.Detect(ctx, tweet.Text)
+	// 资格规则：过滤过旧、转推/回复、低互动等推文，避免浪费 LLM 调用
+	if ok, rule, reason := s.eligibilityChecker.Check(tweet); !ok {
+		pr.Skipped = true
+		// 互动数不足等之后可能满足的规则不记录日志，下次拉取时重新评估
+		if s.eligibilityChecker.Recheckable(rule) {
+			s.logger.Debug("推文暂不满足资格规则",
+				zap.String("tweet_id", tweet.ID),
+				zap.String("rule", rule),
+				zap.String("reason", reason),
+			)
+			return pr
+		}
+		s.saveReplyLog(ctx, tweet, &entity.ReplyLog{
+			Status:     entity.ReplyStatusSkipped,
+			SkipReason: rule + ": " + reason,
+		})
+		return pr
+	}
+
+	// 会话级去重：同一线程只回复一次
+	if replied, err := s.repliedInConversation(ctx, tweet); err != nil {
+		pr.Error = err
+		return pr
+	} else if replied {
+		pr.Skipped = true
+		s.saveReplyLog(ctx, tweet, &entity.ReplyLog{
+			Status:     entity.ReplyStatusSkipped,
+			SkipReason: "conversation: 已回复过该会话 " + tweet.ConversationID,
+		})
+		return pr
+	}
+
+	// 作者频率限制：冷却期内或超过每周上限时暂不处理，不记录日志以便之后重新评估
+	allowed, reason, err := s.authorPolicy.Check(ctx, tweet.AuthorID, user)
+	if err != nil {
+		pr.Error = err
+		return pr
+	}
+	if !allowed {
+		s.logger.Debug("作者回复频率受限",
+			zap.String("tweet_id", tweet.ID),
+			zap.String("author_id", tweet.AuthorID),
+			zap.String("reason", reason),
+		)
+		pr.Skipped = true
+		return pr
+	}
+
+	// 分组投放：按作者所属分组限定回复时段、每日上限和可用文案，不满足时暂不处理
+	group := s.groupTargeting.Resolve(user)
+	allowed, reason, err = s.groupTargeting.Check(ctx, group)
+	if err != nil {
+		pr.Error = err
+		return pr
+	}
+	if !allowed {
+		s.logger.Debug("分组投放受限",
+			zap.String("tweet_id", tweet.ID),
+			zap.String("author_id", tweet.AuthorID),
+			zap.String("reason", reason),
+		)
+		pr.Skipped = true
+		return pr
+	}
+	var groupID *int
+	if group != nil {
+		groupID = &group.ID
+	}
+
+	// 推广活动预算：回复数或 LLM 花费用完时活动自动结束，推文暂不处理
+	allowed, reason, err = s.campaignTargeting.Check(ctx, campaign)
+	if err != nil {
+		pr.Error = err
+		return pr
+	}
+	if !allowed {
+		s.logger.Debug("推广活动预算受限",
+			zap.String("tweet_id", tweet.ID),
+			zap.String("reason", reason),
+		)
+		pr.Skipped = true
+		return pr
+	}
+	var campaignID *int
+	if campaign != nil {
+		campaignID = &campaign.ID
+	}
+
+	// LLM 检测：按生效活动的分类检测
+	isHackathon, llmResponse, err := s.hackathonDetector.Detect(ctx, detectCategory(campaign), tweet.Text)
 	if err != nil {
 		pr.Error = err
 		// s.saveReplyLog(ctx, tweet, "", nil, entity.ReplyStatusFailed, llmResponse, false, err.Error())
 		return pr
 	}
+	s.campaignTargeting.RecordLLMCalls(ctx, campaign, 1)
 
+	pr.Detected = true
 	pr.IsHackathon = isHackathon
 
 	if !isHackathon {
@@ -157,60 +542,155 @@ func (s *workflowService) processSingleTweet(
 		return pr
 	}
 
+	// 安全审核：敏感话题、负面情绪、争议内容下不回复广告
+	safety, err := s.safetyChecker.Check(ctx, tweet.Text)
+	if err != nil {
+		pr.Error = err
+		return pr
+	}
+	if safety.RawResponse != "" {
+		s.campaignTargeting.RecordLLMCalls(ctx, campaign, 1)
+	}
+	if !safety.Safe {
+		pr.Blocked = true
+		s.saveReplyLog(ctx, tweet, &entity.ReplyLog{
+			Status:      entity.ReplyStatusBlockedBySafety,
+			LLMResponse: llmResponse,
+			IsHackathon: true,
+			SkipReason:  safety.Category + ": " + safety.Reason,
+			CampaignID:  campaignID,
+		})
+		return pr
+	}
+
 	if dryRun {
-		s.saveReplyLog(ctx, tweet, "", nil, entity.ReplyStatusDryRun, llmResponse, true, "")
+		s.saveReplyLog(ctx, tweet, &entity.ReplyLog{
+			Status:      entity.ReplyStatusDryRun,
+			LLMResponse: llmResponse,
+			IsHackathon: true,
+			UserGroupID: groupID,
+			CampaignID:  campaignID,
+		})
 		return pr
 	}
 
-	// 获取广告并回复
-	adCopy, err := s.adReplyService.GetNextAdCopy(ctx, "hackathon")
+	// 获取广告并回复：活动只使用其拥有的文案，否则按分组限定的类别和文案选择
+	query := adCopyQueryFor(group, tweet.Lang)
+	if campaign != nil {
+		query = campaignAdCopyQuery(campaign, tweet.Lang)
+	}
+	adCopy, err := s.adReplyService.GetNextAdCopy(ctx, query)
 	if err != nil {
 		pr.Error = err
 		//s.saveReplyLog(ctx, tweet, "", nil, entity.ReplyStatusFailed, llmResponse, true, err.Error())
 		return pr
 	}
 
-	replyTweet, err := s.adReplyService.ReplyWithAd(ctx, tweet.ID, adCopy)
+	content, err := s.adReplyService.RenderVariant(ctx, adCopy, tweet.Lang, buildTemplateData(tweet, user, llmResponse))
+	if err != nil {
+		pr.Error = err
+		return pr
+	}
+
+	// 人工审核模式：仅保存待审核记录，批准后由后台发送
+	if s.cfg.RequireApproval {
+		err := s.saveReplyLog(ctx, tweet, &entity.ReplyLog{
+			ReplyContent:   content,
+			AdCopyID:       &adCopy.ID,
+			AdCopyRevision: adCopy.Revision,
+			Status:         entity.ReplyStatusPending,
+			LLMResponse:    llmResponse,
+			IsHackathon:    true,
+			UserGroupID:    groupID,
+			CampaignID:     campaignID,
+		})
+		switch {
+		case errors.Is(err, gorm.ErrDuplicatedKey):
+			pr.Skipped = true
+		case err != nil:
+			pr.Error = err
+		default:
+			pr.Pending = true
+		}
+		return pr
+	}
+
+	// 先保存为 sending 领取推文，保存失败时不发送：同一推文已被其他任务领取时跳过；短链接也需要关联回复日志
+	log := &entity.ReplyLog{
+		ReplyContent:   content,
+		AdCopyID:       &adCopy.ID,
+		AdCopyRevision: adCopy.Revision,
+		Status:         entity.ReplyStatusSending,
+		LLMResponse:    llmResponse,
+		IsHackathon:    true,
+		UserGroupID:    groupID,
+		CampaignID:     campaignID,
+	}
+	if err := s.saveReplyLog(ctx, tweet, log); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			pr.Skipped = true
+			return pr
+		}
+		pr.Error = err
+		return pr
+	}
+	log.ReplyContent = s.linkTracker.Rewrite(ctx, log, adCopy)
+
+	replyTweet, err := s.adReplyService.ReplyWithContent(ctx, tweet.ID, log.ReplyContent, &adCopy.ID)
+
+	// 失败时同样记录日志：临时性失败进入重试队列，永久性失败标记为 failed
+	applyReplyOutcome(log, replyTweet, err, s.cfg.Retry, time.Now())
+	if updateErr := s.replyLogRepo.Update(ctx, log); updateErr != nil {
+		s.logger.Error("更新回复日志失败", zap.Int("reply_log_id", log.ID), zap.Error(updateErr))
+	}
+
 	if err != nil {
 		pr.Error = err
-		//s.saveReplyLog(ctx, tweet, "", &adCopy.ID, entity.ReplyStatusFailed, llmResponse, true, err.Error())
 		return pr
 	}
 
 	pr.Success = true
-	s.saveReplyLog(ctx, tweet, replyTweet.ID, &adCopy.ID, entity.ReplyStatusSuccess, llmResponse, true, "")
 
 	return pr
 }
 
-func (s *workflowService) saveReplyLog(
-	ctx context.Context,
-	tweet twitter.Tweet,
-	replyTweetID string,
-	adCopyID *int,
-	status entity.ReplyStatus,
-	llmResponse string,
-	isHackathon bool,
-	errorMsg string,
-) {
-	log := &entity.ReplyLog{
-		TweetID:       tweet.ID,
-		TweetAuthorID: tweet.AuthorID,
-		TweetContent:  tweet.Text,
-		ReplyTweetID:  replyTweetID,
-		AdCopyID:      adCopyID,
-		Status:        status,
-		LLMResponse:   llmResponse,
-		IsHackathon:   isHackathon,
-		ErrorMessage:  errorMsg,
-	}
-
-	if err := s.replyLogRepo.Save(ctx, log); err != nil {
+// repliedInConversation 检查窗口期内是否已回复过推文所在会话（可选包括被引用的推文）
+func (s *workflowService) repliedInConversation(ctx context.Context, tweet twitter.Tweet) (bool, error) {
+	dedupe := s.cfg.ConversationDedupe
+	if dedupe.Window <= 0 {
+		return false, nil
+	}
+
+	quotedID := ""
+	if dedupe.IncludeQuote {
+		quotedID = tweet.ReferencedID(twitter.ReferencedTypeQuoted)
+	}
+	return s.replyLogRepo.ExistsReplyInConversation(ctx, tweet.ConversationID, quotedID, time.Now().Add(-dedupe.Window))
+}
+
+// saveReplyLog 填充推文信息并保存回复日志，失败时记录日志并返回错误
+// 同一推文已有回复日志（如 filtered stream 与定时工作流同时处理）时返回 gorm.ErrDuplicatedKey
+func (s *workflowService) saveReplyLog(ctx context.Context, tweet twitter.Tweet, log *entity.ReplyLog) error {
+	log.TweetID = tweet.ID
+	log.TweetAuthorID = tweet.AuthorID
+	log.TweetContent = tweet.Text
+	log.TweetLang = tweet.Lang
+	log.ConversationID = tweet.ConversationID
+	log.QuotedTweetID = tweet.ReferencedID(twitter.ReferencedTypeQuoted)
+
+	err := s.replyLogRepo.Save(ctx, log)
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		s.logger.Debug("推文已有回复日志，已被其他任务处理", zap.String("tweet_id", tweet.ID))
+		return err
+	}
+	if err != nil {
 		s.logger.Error("保存回复日志失败",
 			zap.String("tweet_id", tweet.ID),
 			zap.Error(err),
 		)
+		return err
 	}
+	return nil
 }
 
 func (s *workflowService) updateResult(result *dto.WorkflowResult, pr dto.ProcessResult) {
@@ -223,6 +703,12 @@ func (s *workflowService) updateResult(result *dto.WorkflowResult, pr dto.Proces
 	if pr.Skipped {
 		result.SkippedTweets++
 	}
+	if pr.Blocked {
+		result.BlockedTweets++
+	}
+	if pr.Pending {
+		result.PendingApprovals++
+	}
 	if pr.Error != nil {
 		result.FailedReplies++
 		result.Errors = append(result.Errors, pr.Error.Error())
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"strings"
 	"time"
@@ -14,6 +15,8 @@ type Config struct {
 	Twitter  TwitterConfig  `mapstructure:"twitter"`
 	LLM      LLMConfig      `mapstructure:"llm"`
 	Workflow WorkflowConfig `mapstructure:"workflow"`
+	Safety   SafetyConfig   `mapstructure:"safety"`
+	Stream   StreamConfig   `mapstructure:"stream"`
 	Log      LogConfig      `mapstructure:"log"`
 }
 
@@ -45,20 +48,154 @@ type TwitterConfig struct {
 }
 
 type LLMConfig struct {
-	Provider   string        `mapstructure:"provider"`
-	APIKey     string        `mapstructure:"api_key"`
-	Model      string        `mapstructure:"model"`
-	BaseURL    string        `mapstructure:"base_url"`
-	Timeout    time.Duration `mapstructure:"timeout"`
-	MaxRetries int           `mapstructure:"max_retries"`
+	Provider    string        `mapstructure:"provider"`
+	APIKey      string        `mapstructure:"api_key"`
+	Model       string        `mapstructure:"model"`
+	BaseURL     string        `mapstructure:"base_url"`
+	Timeout     time.Duration `mapstructure:"timeout"`
+	MaxRetries  int           `mapstructure:"max_retries"`
+	CostPerCall float64       `mapstructure:"cost_per_call"` // 单次调用的估算费用（美元），用于统计推广活动的 LLM 花费
 }
 
 type WorkflowConfig struct {
-	DefaultTweetCount int           `mapstructure:"default_tweet_count"`
-	ReplyInterval     time.Duration `mapstructure:"reply_interval"`
-	MaxDailyReplies   int           `mapstructure:"max_daily_replies"`
-	EnableScheduler   bool          `mapstructure:"enable_scheduler"`
-	Schedule          string        `mapstructure:"schedule"`
+	DefaultTweetCount  int                      `mapstructure:"default_tweet_count"`
+	ReplyInterval      time.Duration            `mapstructure:"reply_interval"`
+	MaxDailyReplies    int                      `mapstructure:"max_daily_replies"`
+	EnableScheduler    bool                     `mapstructure:"enable_scheduler"`
+	Schedule           string                   `mapstructure:"schedule"`
+	RequireApproval    bool                     `mapstructure:"require_approval"`
+	Retry              RetryConfig              `mapstructure:"retry"`
+	Eligibility        EligibilityConfig        `mapstructure:"eligibility"`
+	AuthorPolicy       AuthorPolicyConfig       `mapstructure:"author_policy"`
+	ConversationDedupe ConversationDedupeConfig `mapstructure:"conversation_dedupe"`
+	Lists              ListsConfig              `mapstructure:"lists"`
+	ProfileRefresh     time.Duration            `mapstructure:"profile_refresh"` // 定期刷新监控用户资料的间隔，0 表示不刷新
+	Activity           ActivityConfig           `mapstructure:"activity"`
+	AdSelection        AdSelectionConfig        `mapstructure:"ad_selection"`
+	Variation          VariationConfig          `mapstructure:"variation"`
+	AdCopyExpireCheck  time.Duration            `mapstructure:"ad_copy_expire_check"` // 定期停用已过结束时间的广告文案的间隔，0 表示不检查
+	LinkTracking       LinkTrackingConfig       `mapstructure:"link_tracking"`
+	Purge              PurgeConfig              `mapstructure:"purge"`
+}
+
+// PurgeConfig 定期永久删除已软删除、且没有被回复日志引用的广告文案和监控用户
+type PurgeConfig struct {
+	Interval  time.Duration `mapstructure:"interval"`   // 清理间隔，0 表示不清理
+	RetainFor time.Duration `mapstructure:"retain_for"` // 删除后至少保留该时长，期间可恢复
+}
+
+// LinkTrackingConfig 回复链接追踪：发送时把回复中文案的推广链接替换为短链接，统计点击并附加 UTM 参数
+type LinkTrackingConfig struct {
+	Enabled     bool   `mapstructure:"enabled"`
+	BaseURL     string `mapstructure:"base_url"`    // 短链接的对外地址，如 https://go.example.com，短链接为 base_url/r/:code
+	CodeLength  int    `mapstructure:"code_length"` // 短码长度，默认 8
+	UTMSource   string `mapstructure:"utm_source"`
+	UTMMedium   string `mapstructure:"utm_medium"`
+	UTMCampaign string `mapstructure:"utm_campaign"` // 文案属于推广活动时使用 campaign_<id>
+
+	ClickDedupeWindow time.Duration `mapstructure:"click_dedupe_window"` // 同一访客（IP + User-Agent）在窗口内重复点击只计一次，0 表示不去重
+}
+
+// VariationConfig 回复内容去重：生成文案变体（spintax、emoji、话题标签）时与最近的回复保持足够差异，
+// 避免 Twitter 因重复内容拒绝或限流
+type VariationConfig struct {
+	History     int `mapstructure:"history"`      // 与最近 N 条已发送或即将发送的回复比较，0 表示不比较
+	MinDistance int `mapstructure:"min_distance"` // 与每条回复的最小编辑距离（字符数，不计链接），0 表示不比较
+	MaxAttempts int `mapstructure:"max_attempts"` // 最多生成的变体数，都不满足时使用差异最大的一个，默认 10
+}
+
+// AdSelectionConfig 广告文案选择策略，可按类别指定
+type AdSelectionConfig struct {
+	Strategy        string            `mapstructure:"strategy"`         // round_robin、weighted_random、epsilon_greedy、thompson
+	Categories      map[string]string `mapstructure:"categories"`       // 类别 -> 策略，未配置的类别使用 strategy
+	Epsilon         float64           `mapstructure:"epsilon"`          // epsilon_greedy 的探索概率
+	MetricsInterval time.Duration     `mapstructure:"metrics_interval"` // 定期拉取回复互动数据的间隔，0 表示不拉取
+	MetricsWindow   time.Duration     `mapstructure:"metrics_window"`   // 只更新该时长内发送的回复
+	MetricsBatch    int               `mapstructure:"metrics_batch"`    // 每次最多更新的回复数
+}
+
+// ActivityConfig 监控用户活跃度：按命中率自适应调整拉取频率，定期处理休眠或低价值用户
+type ActivityConfig struct {
+	EvaluateInterval time.Duration `mapstructure:"evaluate_interval"` // 定期评估的间隔，0 表示不评估
+	DormantAfter     time.Duration `mapstructure:"dormant_after"`     // 超过该时长未发推视为休眠，0 表示不检查
+	MinTweetsSeen    int           `mapstructure:"min_tweets_seen"`   // 累计推文数达到该值后才按命中率评估
+	MinHitRate       float64       `mapstructure:"min_hit_rate"`      // 命中率低于该值视为低价值，0 表示不检查
+	TargetHitRate    float64       `mapstructure:"target_hit_rate"`   // 命中率达到该值的用户按最短间隔拉取
+	Action           string        `mapstructure:"action"`            // deactivate（停用）或 deprioritize（按最长间隔拉取）
+	ExemptManual     bool          `mapstructure:"exempt_manual"`     // 手动添加的用户不参与评估
+	MinPollInterval  time.Duration `mapstructure:"min_poll_interval"`
+	MaxPollInterval  time.Duration `mapstructure:"max_poll_interval"` // 0 表示关闭自适应拉取，每次工作流都拉取；action 为 deprioritize 时必须设置
+}
+
+// ListsConfig 作为监控用户来源的 X 列表
+type ListsConfig struct {
+	IDs           []string `mapstructure:"ids"`
+	UseTimeline   bool     `mapstructure:"use_timeline"`   // 直接拉取列表时间线，代替逐个拉取成员时间线
+	TimelineCount int      `mapstructure:"timeline_count"` // 每个列表时间线拉取的推文数
+}
+
+// ConversationDedupeConfig 会话级去重：同一会话（线程）在时间窗口内最多回复一次
+type ConversationDedupeConfig struct {
+	Window       time.Duration `mapstructure:"window"`
+	IncludeQuote bool          `mapstructure:"include_quote"`
+}
+
+// AuthorPolicyConfig 针对同一作者的默认回复频率限制，可被用户级设置覆盖
+type AuthorPolicyConfig struct {
+	Cooldown          time.Duration `mapstructure:"cooldown"`
+	MaxRepliesPerWeek int           `mapstructure:"max_replies_per_week"`
+}
+
+// EligibilityConfig 推文资格规则，在调用 LLM 之前过滤不值得回复的推文
+type EligibilityConfig struct {
+	MaxTweetAge        time.Duration `mapstructure:"max_tweet_age"`
+	ExcludeRetweets    bool          `mapstructure:"exclude_retweets"`
+	ExcludeQuotes      bool          `mapstructure:"exclude_quotes"`
+	ExcludeReplies     bool          `mapstructure:"exclude_replies"`
+	MinAuthorFollowers int           `mapstructure:"min_author_followers"`
+	RequireVerified    bool          `mapstructure:"require_verified"`
+	MinEngagement      int           `mapstructure:"min_engagement"`
+	RequireOpenReplies bool          `mapstructure:"require_open_replies"`
+}
+
+// RetryConfig 回复失败重试配置
+type RetryConfig struct {
+	MaxAttempts   int           `mapstructure:"max_attempts"` // 包括首次发送在内的最多发送次数，1 表示不重试
+	BaseDelay     time.Duration `mapstructure:"base_delay"`
+	MaxDelay      time.Duration `mapstructure:"max_delay"`
+	CheckInterval time.Duration `mapstructure:"check_interval"`
+}
+
+// SafetyConfig 回复前的内容安全审核配置
+type SafetyConfig struct {
+	Enabled    bool                            `mapstructure:"enabled"`
+	UseLLM     bool                            `mapstructure:"use_llm"`
+	Categories map[string]SafetyCategoryConfig `mapstructure:"categories"`
+}
+
+// SafetyCategoryConfig 单个风险类别的审核配置
+type SafetyCategoryConfig struct {
+	Enabled  bool     `mapstructure:"enabled"`
+	Keywords []string `mapstructure:"keywords"`
+}
+
+// StreamConfig filtered stream 实时消费配置
+type StreamConfig struct {
+	Enabled          bool               `mapstructure:"enabled"`
+	Endpoint         string             `mapstructure:"endpoint"` // 为空时使用官方 API，可指向本地桩服务
+	Rules            []StreamRuleConfig `mapstructure:"rules"`
+	PruneRules       bool               `mapstructure:"prune_rules"` // 启动时删除配置中不存在的规则
+	HeartbeatTimeout time.Duration      `mapstructure:"heartbeat_timeout"`
+	InitialBackoff   time.Duration      `mapstructure:"initial_backoff"`
+	MaxBackoff       time.Duration      `mapstructure:"max_backoff"`
+	QueueSize        int                `mapstructure:"queue_size"`
+	DryRun           bool               `mapstructure:"dry_run"`
+}
+
+// StreamRuleConfig 单条流过滤规则
+type StreamRuleConfig struct {
+	Value string `mapstructure:"value"`
+	Tag   string `mapstructure:"tag"`
 }
 
 type LogConfig struct {
@@ -86,9 +223,23 @@ func Load(configPath string) (*Config, error) {
 	// 处理环境变量替换
 	cfg.resolveEnvVars()
 
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
 	return &cfg, nil
 }
 
+// validate 检查相互依赖的配置项
+func (c *Config) validate() error {
+	activity := c.Workflow.Activity
+	// deprioritize 依赖最长拉取间隔降低频率，为 0 时休眠用户仍每次都拉取，等于不处理
+	if activity.Action == "deprioritize" && activity.MaxPollInterval <= 0 {
+		return fmt.Errorf("workflow.activity.action 为 deprioritize 时必须设置 max_poll_interval")
+	}
+	return nil
+}
+
 func (c *Config) resolveEnvVars() {
 	c.Database.Password = resolveEnv(c.Database.Password)
 	c.Twitter.APIKey = resolveEnv(c.Twitter.APIKey)
@@ -106,4 +257,3 @@ func resolveEnv(value string) string {
 	}
 	return value
 }
-
@@ -1,36 +1,196 @@
 package entity
 
-import "time"
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type AdCopy struct {
-	ID         int        `json:"id" gorm:"primaryKey"`
-	Name       string     `json:"name" gorm:"size:128;not null"`
-	Content    string     `json:"content" gorm:"type:text;not null"`
-	Category   string     `json:"category" gorm:"size:64;default:hackathon;index"`
-	Priority   int        `json:"priority" gorm:"default:0"`
-	IsActive   bool       `json:"is_active" gorm:"default:true;index"`
-	UseCount   int        `json:"use_count" gorm:"default:0"`
-	LastUsedAt *time.Time `json:"last_used_at"`
-	CreatedAt  time.Time  `json:"created_at"`
-	UpdatedAt  time.Time  `json:"updated_at"`
+	ID         int             `json:"id" gorm:"primaryKey"`
+	Name       string          `json:"name" gorm:"size:128;not null"`
+	Content    string          `json:"content" gorm:"type:text;not null"`        // 支持 text/template 变量，如 {{.Author.Username}}
+	LinkURL    string          `json:"link_url" gorm:"column:link_url;size:512"` // 推广链接，模板中以 {{.TrackingLink}} 引用
+	Language   string          `json:"language" gorm:"size:16;default:''"`
+	Category   string          `json:"category" gorm:"size:64;default:hackathon;index"`
+	CampaignID *int            `json:"campaign_id" gorm:"column:campaign_id;index"` // 所属推广活动，只在该活动中使用
+	Priority   int             `json:"priority" gorm:"default:0"`
+	IsActive   bool            `json:"is_active" gorm:"default:true;index"`
+	UseCount   int             `json:"use_count" gorm:"default:0"`
+	Revision   int             `json:"revision" gorm:"default:0"` // 当前内容的版本号，见 AdCopyRevision
+	LastUsedAt *time.Time      `json:"last_used_at"`
+	Variants   []AdCopyVariant `json:"variants,omitempty" gorm:"foreignKey:AdCopyID"`
+	Emojis     []string        `json:"emojis,omitempty" gorm:"type:text;serializer:json"`   // 模板中以 {{.Emoji}} 引用，每次回复随机选择一个
+	Hashtags   []string        `json:"hashtags,omitempty" gorm:"type:text;serializer:json"` // 模板中以 {{.Hashtag}} 引用，每次回复随机选择一个
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt  `json:"deleted_at" gorm:"index"` // 软删除，回复日志仍可引用
+
+	// 投放时间与次数限制
+	StartsAt     *time.Time `json:"starts_at"`
+	EndsAt       *time.Time `json:"ends_at"`                                   // 到期后自动停用
+	MaxDailyUses int        `json:"max_daily_uses" gorm:"default:0"`           // 每日最多回复次数（含待审核、待发送），0 表示不限制
+	MaxTotalUses int        `json:"max_total_uses" gorm:"default:0"`           // 累计最多使用次数（use_count 加上待审核、待发送的回复），0 表示不限制
+	Weekdays     []int      `json:"weekdays" gorm:"type:text;serializer:json"` // 允许使用的星期（0=周日），为空时不限制
+	StartHour    *int       `json:"start_hour"`                                // 允许使用的时段 [start_hour, end_hour)，可跨零点
+	EndHour      *int       `json:"end_hour"`
+	Timezone     string     `json:"timezone" gorm:"size:64"` // 时段和每日上限使用的时区，为空时使用服务器时区
+
+	WeightedLength int          `json:"weighted_length" gorm:"-"`  // 以示例数据渲染后的推文加权长度，不入库
+	UsedToday      int64        `json:"used_today" gorm:"-"`       // 今日已回复次数，不入库
+	QueuedUses     int64        `json:"queued_uses" gorm:"-"`      // 待审核、待发送的回复数，不入库
+	Status         AdCopyStatus `json:"status,omitempty" gorm:"-"` // 当前实际可用状态，不入库
 }
 
 func (AdCopy) TableName() string {
 	return "ad_copies"
 }
 
-type CreateAdCopyInput struct {
-	Name     string `json:"name" binding:"required"`
+// AdCopyStatus 广告文案的实际可用状态
+type AdCopyStatus string
+
+const (
+	AdCopyStatusAvailable   AdCopyStatus = "available"
+	AdCopyStatusInactive    AdCopyStatus = "inactive"      // 已停用
+	AdCopyStatusScheduled   AdCopyStatus = "scheduled"     // 未到开始时间
+	AdCopyStatusExpired     AdCopyStatus = "expired"       // 已过结束时间
+	AdCopyStatusExhausted   AdCopyStatus = "exhausted"     // 达到累计使用上限
+	AdCopyStatusDailyCapped AdCopyStatus = "daily_capped"  // 达到今日使用上限
+	AdCopyStatusOutOfWindow AdCopyStatus = "out_of_window" // 不在允许使用的时段内
+)
+
+// AdCopyUsage 文案的回复统计，用于判断使用上限
+type AdCopyUsage struct {
+	Today  int64 // 文案时区内今日的回复（含待审核、待发送）次数
+	Queued int64 // 待审核、待发送的回复数，尚未计入 use_count
+}
+
+// Availability 根据投放时间、使用上限和时段计算文案在指定时间的可用状态
+func (a *AdCopy) Availability(now time.Time, usage AdCopyUsage) AdCopyStatus {
+	switch {
+	case a.EndsAt != nil && !now.Before(*a.EndsAt):
+		return AdCopyStatusExpired
+	case a.MaxTotalUses > 0 && int64(a.UseCount)+usage.Queued >= int64(a.MaxTotalUses):
+		return AdCopyStatusExhausted
+	case !a.IsActive:
+		return AdCopyStatusInactive
+	case a.StartsAt != nil && now.Before(*a.StartsAt):
+		return AdCopyStatusScheduled
+	case a.MaxDailyUses > 0 && usage.Today >= int64(a.MaxDailyUses):
+		return AdCopyStatusDailyCapped
+	case !inWeeklyWindow(now, a.Timezone, a.Weekdays, a.StartHour, a.EndHour):
+		return AdCopyStatusOutOfWindow
+	}
+	return AdCopyStatusAvailable
+}
+
+// DayStart 返回文案时区内当天的零点，用于统计每日使用次数
+func (a *AdCopy) DayStart(now time.Time) time.Time {
+	return startOfDay(now, a.Timezone)
+}
+
+// ContentFor 返回指定语言的文案内容，没有对应语言的变体时返回默认内容
+func (a *AdCopy) ContentFor(language string) string {
+	if language == "" || strings.EqualFold(a.Language, language) {
+		return a.Content
+	}
+	for _, v := range a.Variants {
+		if strings.EqualFold(v.Language, language) {
+			return v.Content
+		}
+	}
+	return a.Content
+}
+
+// HasLanguage 判断文案本身或其变体是否有指定语言的内容
+func (a *AdCopy) HasLanguage(language string) bool {
+	if strings.EqualFold(a.Language, language) {
+		return true
+	}
+	for _, v := range a.Variants {
+		if strings.EqualFold(v.Language, language) {
+			return true
+		}
+	}
+	return false
+}
+
+// AdCopyVariant 广告文案的多语言变体
+type AdCopyVariant struct {
+	ID        int       `json:"id" gorm:"primaryKey"`
+	AdCopyID  int       `json:"ad_copy_id" gorm:"column:ad_copy_id;not null;uniqueIndex:idx_ad_copy_variants_copy_lang"`
+	Language  string    `json:"language" gorm:"size:16;not null;uniqueIndex:idx_ad_copy_variants_copy_lang"`
+	Content   string    `json:"content" gorm:"type:text;not null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	WeightedLength int `json:"weighted_length" gorm:"-"`
+}
+
+func (AdCopyVariant) TableName() string {
+	return "ad_copy_variants"
+}
+
+type AdCopyVariantInput struct {
+	Language string `json:"language" binding:"required"`
 	Content  string `json:"content" binding:"required"`
-	Category string `json:"category"`
-	Priority int    `json:"priority"`
+}
+
+type CreateAdCopyInput struct {
+	Name         string               `json:"name" binding:"required"`
+	Content      string               `json:"content" binding:"required"`
+	LinkURL      string               `json:"link_url"`
+	Language     string               `json:"language"`
+	Category     string               `json:"category"`
+	Priority     int                  `json:"priority"`
+	Variants     []AdCopyVariantInput `json:"variants"`
+	Emojis       []string             `json:"emojis"`
+	Hashtags     []string             `json:"hashtags"`
+	CampaignID   *int                 `json:"campaign_id"`
+	StartsAt     *time.Time           `json:"starts_at"`
+	EndsAt       *time.Time           `json:"ends_at"`
+	MaxDailyUses int                  `json:"max_daily_uses" binding:"omitempty,min=0"`
+	MaxTotalUses int                  `json:"max_total_uses" binding:"omitempty,min=0"`
+	Weekdays     []int                `json:"weekdays" binding:"omitempty,dive,min=0,max=6"`
+	StartHour    *int                 `json:"start_hour" binding:"omitempty,min=0,max=23"`
+	EndHour      *int                 `json:"end_hour" binding:"omitempty,min=0,max=23"`
+	Timezone     string               `json:"timezone"`
 }
 
 type UpdateAdCopyInput struct {
-	Name     *string `json:"name"`
-	Content  *string `json:"content"`
-	Category *string `json:"category"`
-	Priority *int    `json:"priority"`
-	IsActive *bool   `json:"is_active"`
+	Name     *string               `json:"name"`
+	Content  *string               `json:"content"`
+	LinkURL  *string               `json:"link_url"`
+	Language *string               `json:"language"`
+	Category *string               `json:"category"`
+	Priority *int                  `json:"priority"`
+	IsActive *bool                 `json:"is_active"`
+	Variants *[]AdCopyVariantInput `json:"variants"`
+	Emojis   *[]string             `json:"emojis"`
+	Hashtags *[]string             `json:"hashtags"`
+
+	CampaignID    *int       `json:"campaign_id"` // 0 表示解除与活动的关联
+	StartsAt      *time.Time `json:"starts_at"`
+	EndsAt        *time.Time `json:"ends_at"`
+	MaxDailyUses  *int       `json:"max_daily_uses" binding:"omitempty,min=0"`
+	MaxTotalUses  *int       `json:"max_total_uses" binding:"omitempty,min=0"`
+	Weekdays      *[]int     `json:"weekdays" binding:"omitempty,dive,min=0,max=6"`
+	StartHour     *int       `json:"start_hour" binding:"omitempty,min=0,max=23"`
+	EndHour       *int       `json:"end_hour" binding:"omitempty,min=0,max=23"`
+	ClearSchedule bool       `json:"clear_schedule"` // 清除开始/结束时间和时段限制
+	Timezone      *string    `json:"timezone"`
+}
+
+// PreviewAdCopyInput 预览文案模板，未提供的示例字段使用默认示例数据
+type PreviewAdCopyInput struct {
+	Content        string   `json:"content" binding:"required"`
+	LinkURL        string   `json:"link_url"`
+	Emojis         []string `json:"emojis"`
+	Hashtags       []string `json:"hashtags"`
+	TweetText      string   `json:"tweet_text"`
+	AuthorUsername string   `json:"author_username"`
+	EventName      string   `json:"event_name"`
+	Deadline       string   `json:"deadline"`
 }
 
@@ -0,0 +1,75 @@
+package entity
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AdCopyRevision 广告文案内容的不可变快照，文案内容（含语言变体、推广链接、emoji 和话题标签集合）每次变化时生成新版本
+// 回复日志通过 ad_copy_id + ad_copy_revision 引用实际使用的版本
+type AdCopyRevision struct {
+	ID        int               `json:"id" gorm:"primaryKey"`
+	AdCopyID  int               `json:"ad_copy_id" gorm:"column:ad_copy_id;not null;uniqueIndex:idx_ad_copy_revisions_copy_rev"`
+	Revision  int               `json:"revision" gorm:"not null;uniqueIndex:idx_ad_copy_revisions_copy_rev"`
+	Name      string            `json:"name" gorm:"size:128"`
+	Content   string            `json:"content" gorm:"type:text;not null"`
+	LinkURL   string            `json:"link_url" gorm:"column:link_url;size:512"`
+	Language  string            `json:"language" gorm:"size:16"`
+	Variants  []RevisionVariant `json:"variants" gorm:"type:text;serializer:json"`
+	Emojis    []string          `json:"emojis,omitempty" gorm:"type:text;serializer:json"`
+	Hashtags  []string          `json:"hashtags,omitempty" gorm:"type:text;serializer:json"`
+	Note      string            `json:"note" gorm:"size:255"` // created、updated、rollback to #n
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+func (AdCopyRevision) TableName() string {
+	return "ad_copy_revisions"
+}
+
+// RevisionVariant 版本中保存的语言变体
+type RevisionVariant struct {
+	Language string `json:"language"`
+	Content  string `json:"content"`
+}
+
+// NewAdCopyRevision 根据文案当前内容生成快照（不含版本号）
+func NewAdCopyRevision(adCopy *AdCopy, note string) *AdCopyRevision {
+	variants := make([]RevisionVariant, 0, len(adCopy.Variants))
+	for _, v := range adCopy.Variants {
+		variants = append(variants, RevisionVariant{Language: v.Language, Content: v.Content})
+	}
+	sort.Slice(variants, func(i, j int) bool { return variants[i].Language < variants[j].Language })
+
+	return &AdCopyRevision{
+		AdCopyID: adCopy.ID,
+		Name:     adCopy.Name,
+		Content:  adCopy.Content,
+		LinkURL:  adCopy.LinkURL,
+		Language: adCopy.Language,
+		Variants: variants,
+		Emojis:   adCopy.Emojis,
+		Hashtags: adCopy.Hashtags,
+		Note:     note,
+	}
+}
+
+// SameContent 判断两个快照的回复内容是否相同（不比较名称）
+func (r *AdCopyRevision) SameContent(other *AdCopyRevision) bool {
+	if r.Content != other.Content || r.LinkURL != other.LinkURL || !strings.EqualFold(r.Language, other.Language) {
+		return false
+	}
+	return slices.Equal(r.Variants, other.Variants) &&
+		slices.Equal(r.Emojis, other.Emojis) &&
+		slices.Equal(r.Hashtags, other.Hashtags)
+}
+
+// VariantEntities 转换为文案的语言变体
+func (r *AdCopyRevision) VariantEntities() []AdCopyVariant {
+	variants := make([]AdCopyVariant, 0, len(r.Variants))
+	for _, v := range r.Variants {
+		variants = append(variants, AdCopyVariant{Language: v.Language, Content: v.Content})
+	}
+	return variants
+}
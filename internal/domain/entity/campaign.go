@@ -0,0 +1,119 @@
+package entity
+
+import "time"
+
+type CampaignStatus string
+
+const (
+	CampaignStatusDraft    CampaignStatus = "draft"
+	CampaignStatusActive   CampaignStatus = "active"
+	CampaignStatusPaused   CampaignStatus = "paused"
+	CampaignStatusFinished CampaignStatus = "finished" // 到期或预算用完后自动结束，也可手动结束
+)
+
+// ValidCampaignStatus 判断状态是否有效
+func ValidCampaignStatus(status CampaignStatus) bool {
+	switch status {
+	case CampaignStatusDraft, CampaignStatusActive, CampaignStatusPaused, CampaignStatusFinished:
+		return true
+	}
+	return false
+}
+
+// Campaign 推广活动：拥有一组广告文案，投放到指定的用户分组和搜索条件，并限制回复数和 LLM 花费
+type Campaign struct {
+	ID             int            `json:"id" gorm:"primaryKey"`
+	Name           string         `json:"name" gorm:"size:128;not null;unique"`
+	Description    string         `json:"description" gorm:"type:text"`
+	Category       string         `json:"category" gorm:"size:64;default:hackathon"` // 检测推文使用的分类，活动只使用该分类的文案
+	Status         CampaignStatus `json:"status" gorm:"size:16;default:draft;index"`
+	FinishReason   string         `json:"finish_reason,omitempty" gorm:"size:255"`
+	UserGroupIDs   []int          `json:"user_group_ids" gorm:"column:user_group_ids;type:text;serializer:json"`
+	SearchQueryIDs []int          `json:"search_query_ids" gorm:"column:search_query_ids;type:text;serializer:json"`
+	MaxReplies     int            `json:"max_replies" gorm:"default:0"`                        // 回复数预算（含待审核、待发送），0 表示不限制
+	MaxLLMSpend    float64        `json:"max_llm_spend" gorm:"column:max_llm_spend;default:0"` // LLM 花费预算（美元），0 表示不限制
+	LLMCalls       int            `json:"llm_calls" gorm:"column:llm_calls;default:0"`
+	LLMSpend       float64        `json:"llm_spend" gorm:"column:llm_spend;default:0"`
+	StartsAt       *time.Time     `json:"starts_at"`
+	EndsAt         *time.Time     `json:"ends_at"`
+	Priority       int            `json:"priority" gorm:"default:0"` // 推文同时命中多个活动时，优先级高的活动生效
+	AdCopies       []AdCopy       `json:"ad_copies,omitempty" gorm:"foreignKey:CampaignID"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+func (Campaign) TableName() string {
+	return "campaigns"
+}
+
+// Running 判断活动在指定时间是否处于投放中
+func (c *Campaign) Running(now time.Time) bool {
+	if c.Status != CampaignStatusActive {
+		return false
+	}
+	if c.StartsAt != nil && now.Before(*c.StartsAt) {
+		return false
+	}
+	return c.EndsAt == nil || now.Before(*c.EndsAt)
+}
+
+// TargetsGroup 判断活动是否投放到指定分组
+func (c *Campaign) TargetsGroup(groupID int) bool {
+	return containsInt(c.UserGroupIDs, groupID)
+}
+
+// TargetsUser 判断活动是否投放到用户所属的任一启用分组
+func (c *Campaign) TargetsUser(user *FollowedUser) bool {
+	if user == nil {
+		return false
+	}
+	for _, group := range user.Groups {
+		if group.IsActive && c.TargetsGroup(group.ID) {
+			return true
+		}
+	}
+	return false
+}
+
+// TargetsSearchQuery 判断活动是否投放到指定搜索条件
+func (c *Campaign) TargetsSearchQuery(queryID int) bool {
+	return containsInt(c.SearchQueryIDs, queryID)
+}
+
+func containsInt(values []int, target int) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
+
+type CreateCampaignInput struct {
+	Name           string     `json:"name" binding:"required"`
+	Description    string     `json:"description"`
+	Category       string     `json:"category"`
+	Status         string     `json:"status"` // 默认 draft
+	UserGroupIDs   []int      `json:"user_group_ids"`
+	SearchQueryIDs []int      `json:"search_query_ids"`
+	MaxReplies     int        `json:"max_replies" binding:"omitempty,min=0"`
+	MaxLLMSpend    float64    `json:"max_llm_spend" binding:"omitempty,min=0"`
+	StartsAt       *time.Time `json:"starts_at"`
+	EndsAt         *time.Time `json:"ends_at"`
+	Priority       int        `json:"priority"`
+}
+
+type UpdateCampaignInput struct {
+	Name           *string    `json:"name"`
+	Description    *string    `json:"description"`
+	Category       *string    `json:"category"`
+	Status         *string    `json:"status"`
+	UserGroupIDs   *[]int     `json:"user_group_ids"`
+	SearchQueryIDs *[]int     `json:"search_query_ids"`
+	MaxReplies     *int       `json:"max_replies" binding:"omitempty,min=0"`
+	MaxLLMSpend    *float64   `json:"max_llm_spend" binding:"omitempty,min=0"`
+	StartsAt       *time.Time `json:"starts_at"`
+	EndsAt         *time.Time `json:"ends_at"`
+	ClearSchedule  bool       `json:"clear_schedule"` // 清除开始/结束时间
+	Priority       *int       `json:"priority"`
+}
@@ -10,21 +10,71 @@ const (
 	ReplyStatusFailed  ReplyStatus = "failed"
 	ReplyStatusSkipped ReplyStatus = "skipped"
 	ReplyStatusDryRun  ReplyStatus = "dry_run"
+
+	ReplyStatusBlockedBySafety ReplyStatus = "blocked_by_safety"
+
+	// 人工审核流程：pending 待审核 -> approved 已批准待发送 / rejected 已拒绝
+	ReplyStatusApproved ReplyStatus = "approved"
+	ReplyStatusRejected ReplyStatus = "rejected"
+
+	// 已批准的回复被发送任务领取、正在发送，避免并发的发送任务重复发送
+	ReplyStatusSending ReplyStatus = "sending"
+
+	// 临时性失败（限流、服务端错误等）等待重试；超过重试次数或永久性失败则为 failed
+	ReplyStatusRetryScheduled ReplyStatus = "retry_scheduled"
 )
 
+// OutgoingReplyStatuses 已发送或即将发送的回复状态，用于频率限制和去重统计
+var OutgoingReplyStatuses = []ReplyStatus{
+	ReplyStatusSuccess,
+	ReplyStatusPending,
+	ReplyStatusApproved,
+	ReplyStatusSending,
+	ReplyStatusRetryScheduled,
+}
+
+// QueuedReplyStatuses 已生成但尚未发送成功的回复状态，计入文案的累计使用上限
+var QueuedReplyStatuses = []ReplyStatus{
+	ReplyStatusPending,
+	ReplyStatusApproved,
+	ReplyStatusSending,
+	ReplyStatusRetryScheduled,
+}
+
 type ReplyLog struct {
-	ID            int         `json:"id" gorm:"primaryKey"`
-	TweetID       string      `json:"tweet_id" gorm:"column:tweet_id;uniqueIndex;size:64;not null"`
-	TweetAuthorID string      `json:"tweet_author_id" gorm:"column:tweet_author_id;size:64;not null"`
-	TweetContent  string      `json:"tweet_content" gorm:"type:text"`
-	ReplyTweetID  string      `json:"reply_tweet_id" gorm:"column:reply_tweet_id;size:64"`
-	AdCopyID      *int        `json:"ad_copy_id" gorm:"column:ad_copy_id"`
-	AdCopy        *AdCopy     `json:"ad_copy,omitempty" gorm:"foreignKey:AdCopyID"`
-	Status        ReplyStatus `json:"status" gorm:"size:32;default:pending;index"`
-	ErrorMessage  string      `json:"error_message" gorm:"type:text"`
-	LLMResponse   string      `json:"llm_response" gorm:"column:llm_response;type:text"`
-	IsHackathon   bool        `json:"is_hackathon" gorm:"column:is_hackathon"`
-	CreatedAt     time.Time   `json:"created_at" gorm:"index"`
+	ID             int         `json:"id" gorm:"primaryKey"`
+	TweetID        string      `json:"tweet_id" gorm:"column:tweet_id;uniqueIndex;size:64;not null"`
+	TweetAuthorID  string      `json:"tweet_author_id" gorm:"column:tweet_author_id;size:64;not null"`
+	TweetContent   string      `json:"tweet_content" gorm:"type:text"`
+	TweetLang      string      `json:"tweet_lang" gorm:"column:tweet_lang;size:16"`
+	ConversationID string      `json:"conversation_id,omitempty" gorm:"column:conversation_id;size:64;index"`
+	QuotedTweetID  string      `json:"quoted_tweet_id,omitempty" gorm:"column:quoted_tweet_id;size:64;index"`
+	ReplyTweetID   string      `json:"reply_tweet_id" gorm:"column:reply_tweet_id;size:64"`
+	ReplyContent   string      `json:"reply_content" gorm:"column:reply_content;type:text"`
+	AdCopyID       *int        `json:"ad_copy_id" gorm:"column:ad_copy_id"`
+	AdCopy         *AdCopy     `json:"ad_copy,omitempty" gorm:"foreignKey:AdCopyID"`
+	AdCopyRevision int         `json:"ad_copy_revision,omitempty" gorm:"column:ad_copy_revision;default:0"` // 实际使用的文案版本，0 表示未知
+	UserGroupID    *int        `json:"user_group_id,omitempty" gorm:"column:user_group_id;index"`
+	CampaignID     *int        `json:"campaign_id,omitempty" gorm:"column:campaign_id;index"`
+	Status         ReplyStatus `json:"status" gorm:"size:32;default:pending;index"`
+	ErrorMessage   string      `json:"error_message" gorm:"type:text"`
+	FailureReason  string      `json:"failure_reason,omitempty" gorm:"column:failure_reason;size:32"`
+	RetryCount     int         `json:"retry_count" gorm:"column:retry_count;default:0"`
+	NextRetryAt    *time.Time  `json:"next_retry_at,omitempty" gorm:"column:next_retry_at;index"`
+	SkipReason     string      `json:"skip_reason,omitempty" gorm:"column:skip_reason;type:text"`
+	LLMResponse    string      `json:"llm_response" gorm:"column:llm_response;type:text"`
+	IsHackathon    bool        `json:"is_hackathon" gorm:"column:is_hackathon"`
+	ReviewedAt     *time.Time  `json:"reviewed_at,omitempty" gorm:"column:reviewed_at"`
+	SentAt         *time.Time  `json:"sent_at,omitempty" gorm:"column:sent_at;index"` // 回复成功发送的时间，每日回复上限按此统计
+
+	// 回复推文的互动数据，定期拉取，用于评估广告文案效果
+	ReplyLikeCount    int        `json:"reply_like_count" gorm:"column:reply_like_count;default:0"`
+	ReplyReplyCount   int        `json:"reply_reply_count" gorm:"column:reply_reply_count;default:0"`
+	ReplyRetweetCount int        `json:"reply_retweet_count" gorm:"column:reply_retweet_count;default:0"`
+	ReplyQuoteCount   int        `json:"reply_quote_count" gorm:"column:reply_quote_count;default:0"`
+	MetricsUpdatedAt  *time.Time `json:"metrics_updated_at,omitempty" gorm:"column:metrics_updated_at"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
 }
 
 func (ReplyLog) TableName() string {
@@ -0,0 +1,50 @@
+package entity
+
+import "time"
+
+// inWeeklyWindow 判断时间是否在按星期和小时限定的时段内
+// weekdays 为空时不限制星期（0=周日）；小时区间为 [startHour, endHour)，可跨零点，未设置或相等时不限制；
+// timezone 为空或无效时使用服务器时区
+func inWeeklyWindow(now time.Time, timezone string, weekdays []int, startHour, endHour *int) bool {
+	now = inTimezone(now, timezone)
+
+	if len(weekdays) > 0 {
+		allowed := false
+		for _, d := range weekdays {
+			if time.Weekday(d) == now.Weekday() {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return false
+		}
+	}
+
+	if startHour == nil || endHour == nil || *startHour == *endHour {
+		return true
+	}
+	hour := now.Hour()
+	if *startHour < *endHour {
+		return hour >= *startHour && hour < *endHour
+	}
+	// 跨零点，如 22-6
+	return hour >= *startHour || hour < *endHour
+}
+
+// inTimezone 将时间转换到指定时区，timezone 为空或无效时使用服务器时区
+func inTimezone(now time.Time, timezone string) time.Time {
+	if timezone != "" {
+		if loc, err := time.LoadLocation(timezone); err == nil {
+			return now.In(loc)
+		}
+	}
+	return now.Local()
+}
+
+// startOfDay 返回时间在指定时区当天的零点
+func startOfDay(now time.Time, timezone string) time.Time {
+	local := inTimezone(now, timezone)
+	year, month, day := local.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, local.Location())
+}
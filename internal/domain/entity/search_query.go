@@ -0,0 +1,34 @@
+package entity
+
+import "time"
+
+// SearchQuery 保存的推文搜索条件，用于发现未关注账号发布的推文
+type SearchQuery struct {
+	ID         int        `json:"id" gorm:"primaryKey"`
+	Name       string     `json:"name" gorm:"size:128;not null"`
+	Query      string     `json:"query" gorm:"type:text;not null"`
+	MaxResults int        `json:"max_results" gorm:"default:10"`
+	IsActive   bool       `json:"is_active" gorm:"default:true;index"`
+	SinceID    string     `json:"since_id" gorm:"column:since_id;size:64"`
+	LastRunAt  *time.Time `json:"last_run_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+func (SearchQuery) TableName() string {
+	return "search_queries"
+}
+
+type CreateSearchQueryInput struct {
+	Name       string `json:"name" binding:"required"`
+	Query      string `json:"query" binding:"required,max=512"`
+	MaxResults int    `json:"max_results" binding:"omitempty,min=10,max=100"`
+}
+
+type UpdateSearchQueryInput struct {
+	Name       *string `json:"name"`
+	Query      *string `json:"query" binding:"omitempty,max=512"`
+	MaxResults *int    `json:"max_results" binding:"omitempty,min=10,max=100"`
+	IsActive   *bool   `json:"is_active"`
+	ResetSince bool    `json:"reset_since"` // 清空 since_id 游标，从头开始搜索
+}
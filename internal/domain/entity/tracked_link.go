@@ -0,0 +1,37 @@
+package entity
+
+import "time"
+
+// TrackedLink 回复中文案推广链接替换成的短链接（/r/:code），发送时生成，每条回复唯一
+// 通过 ReplyLogID 关联回复日志
+type TrackedLink struct {
+	ID            int        `json:"id" gorm:"primaryKey"`
+	Code          string     `json:"code" gorm:"size:16;not null;uniqueIndex"`
+	OriginalURL   string     `json:"original_url" gorm:"column:original_url;type:text;not null"`
+	TargetURL     string     `json:"target_url" gorm:"column:target_url;type:text;not null"` // 附加 UTM 参数后的跳转地址
+	TweetID       string     `json:"tweet_id" gorm:"column:tweet_id;size:64;not null;index"` // 被回复的推文
+	ReplyLogID    *int       `json:"reply_log_id" gorm:"column:reply_log_id;index"`
+	AdCopyID      *int       `json:"ad_copy_id" gorm:"column:ad_copy_id;index"`
+	CampaignID    *int       `json:"campaign_id,omitempty" gorm:"column:campaign_id"`
+	ClickCount    int        `json:"click_count" gorm:"default:0"`
+	LastClickedAt *time.Time `json:"last_clicked_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+func (TrackedLink) TableName() string {
+	return "tracked_links"
+}
+
+// LinkClick 短链接的一次点击
+type LinkClick struct {
+	ID            int       `json:"id" gorm:"primaryKey"`
+	TrackedLinkID int       `json:"tracked_link_id" gorm:"column:tracked_link_id;not null;index"`
+	Referrer      string    `json:"referrer" gorm:"type:text"`
+	UserAgent     string    `json:"user_agent" gorm:"column:user_agent;type:text"`
+	VisitorHash   string    `json:"-" gorm:"column:visitor_hash;size:64"` // IP 和 User-Agent 的哈希，用于点击去重
+	ClickedAt     time.Time `json:"clicked_at" gorm:"not null;index"`
+}
+
+func (LinkClick) TableName() string {
+	return "link_clicks"
+}
@@ -8,5 +8,6 @@ type Tweet struct {
 	AuthorID  string    `json:"author_id"`
 	Text      string    `json:"text"`
 	CreatedAt time.Time `json:"created_at"`
+	Lang      string    `json:"lang"`
 }
 
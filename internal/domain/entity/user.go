@@ -1,17 +1,72 @@
 package entity
 
-import "time"
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 监控用户来源
+const (
+	UserSourceManual     = "manual"    // 通过 API 手动添加
+	UserSourceFollowing  = "following" // 同步自账号的关注列表
+	userSourceListPrefix = "list:"     // 同步自 X 列表，格式 list:<list_id>
+)
+
+// ListSource 返回列表来源标识
+func ListSource(listID string) string {
+	return userSourceListPrefix + listID
+}
 
 type FollowedUser struct {
-	ID            int       `json:"id" gorm:"primaryKey"`
-	TwitterUserID string    `json:"twitter_user_id" gorm:"column:twitter_user_id;type:varchar(64);unique"`
-	Username      string    `json:"username" gorm:"type:varchar(128)"`
-	DisplayName   string    `json:"display_name" gorm:"type:varchar(256)"`
-	IsActive      bool      `json:"is_active" gorm:"default:true"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID                   int            `json:"id" gorm:"primaryKey"`
+	TwitterUserID        string         `json:"twitter_user_id" gorm:"column:twitter_user_id;type:varchar(64);unique"`
+	Username             string         `json:"username" gorm:"type:varchar(128)"`
+	DisplayName          string         `json:"display_name" gorm:"type:varchar(256)"`
+	Description          string         `json:"description" gorm:"type:text"`
+	FollowersCount       int            `json:"followers_count" gorm:"default:0"`
+	FollowingCount       int            `json:"following_count" gorm:"default:0"`
+	TweetCount           int            `json:"tweet_count" gorm:"default:0"`
+	Protected            bool           `json:"protected" gorm:"default:false"`
+	Verified             bool           `json:"verified" gorm:"default:false"`
+	Location             string         `json:"location" gorm:"type:varchar(256)"`
+	ProfileURL           string         `json:"profile_url" gorm:"column:profile_url;type:varchar(512)"`
+	LastTweetAt          *time.Time     `json:"last_tweet_at"`
+	ProfileRefreshedAt   *time.Time     `json:"profile_refreshed_at"`
+	Groups               []UserGroup    `json:"groups,omitempty" gorm:"many2many:user_group_members"`
+	TweetsSeen           int            `json:"tweets_seen" gorm:"default:0"`    // 工作流拉取到并经过黑客松检测的新推文数
+	HackathonHits        int            `json:"hackathon_hits" gorm:"default:0"` // 其中被检测为黑客松推文的数量
+	LastSeenTweetID      string         `json:"last_seen_tweet_id" gorm:"type:varchar(64)"`
+	LastCheckedAt        *time.Time     `json:"last_checked_at"`
+	NextCheckAt          *time.Time     `json:"next_check_at"`                                     // 自适应拉取：到该时间前跳过该用户
+	DormantReason        string         `json:"dormant_reason,omitempty" gorm:"type:varchar(256)"` // 被判定为休眠或低价值的原因，为空表示正常
+	IsActive             bool           `json:"is_active" gorm:"default:true"`
+	ManuallyDisabled     bool           `json:"manually_disabled" gorm:"default:false"` // 手动停用，同步不会重新启用
+	Source               string         `json:"source" gorm:"type:varchar(64);default:'manual'"`
+	ReplyCooldownSeconds *int           `json:"reply_cooldown_seconds" gorm:"column:reply_cooldown_seconds"`
+	MaxRepliesPerWeek    *int           `json:"max_replies_per_week" gorm:"column:max_replies_per_week"`
+	CreatedAt            time.Time      `json:"created_at"`
+	UpdatedAt            time.Time      `json:"updated_at"`
+	DeletedAt            gorm.DeletedAt `json:"deleted_at" gorm:"index"` // 软删除，保留监控历史
 }
 
 func (FollowedUser) TableName() string {
 	return "followed_users"
 }
+
+// HitRate 黑客松推文命中率
+func (u *FollowedUser) HitRate() float64 {
+	if u.TweetsSeen == 0 {
+		return 0
+	}
+	return float64(u.HackathonHits) / float64(u.TweetsSeen)
+}
+
+// ListID 来源为 X 列表时返回列表ID，否则返回空
+func (u *FollowedUser) ListID() string {
+	if strings.HasPrefix(u.Source, userSourceListPrefix) {
+		return strings.TrimPrefix(u.Source, userSourceListPrefix)
+	}
+	return ""
+}
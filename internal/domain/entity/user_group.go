@@ -0,0 +1,79 @@
+package entity
+
+import "time"
+
+// UserGroup 监控用户分组（标签），可为不同受众设置不同的投放范围、每日上限和时段
+type UserGroup struct {
+	ID              int       `json:"id" gorm:"primaryKey"`
+	Name            string    `json:"name" gorm:"size:128;not null;unique"`
+	Description     string    `json:"description" gorm:"type:text"`
+	AdCategories    []string  `json:"ad_categories" gorm:"type:text;serializer:json"` // 允许的广告类别，为空时使用默认类别
+	AdCopyIDs       []int     `json:"ad_copy_ids" gorm:"column:ad_copy_ids;type:text;serializer:json"`
+	MaxDailyReplies int       `json:"max_daily_replies" gorm:"default:0"`        // 0 表示只受全局上限限制
+	Weekdays        []int     `json:"weekdays" gorm:"type:text;serializer:json"` // 允许回复的星期（0=周日），为空时不限制
+	StartHour       *int      `json:"start_hour"`                                // 允许回复的时段 [start_hour, end_hour)，可跨零点
+	EndHour         *int      `json:"end_hour"`
+	Timezone        string    `json:"timezone" gorm:"size:64"`   // 时段使用的时区，如 Asia/Shanghai，为空时使用服务器时区
+	Priority        int       `json:"priority" gorm:"default:0"` // 用户属于多个分组时，优先级高的分组生效
+	IsActive        bool      `json:"is_active" gorm:"default:true"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+func (UserGroup) TableName() string {
+	return "user_groups"
+}
+
+// InSchedule 判断指定时间是否在分组允许回复的时段内
+func (g *UserGroup) InSchedule(now time.Time) bool {
+	return inWeeklyWindow(now, g.Timezone, g.Weekdays, g.StartHour, g.EndHour)
+}
+
+// DayStart 返回分组时区内当天的零点，用于统计分组每日回复数
+func (g *UserGroup) DayStart(now time.Time) time.Time {
+	return startOfDay(now, g.Timezone)
+}
+
+// UserGroupMember 分组成员关系
+type UserGroupMember struct {
+	UserGroupID    int       `json:"user_group_id" gorm:"primaryKey"`
+	FollowedUserID int       `json:"followed_user_id" gorm:"primaryKey"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+func (UserGroupMember) TableName() string {
+	return "user_group_members"
+}
+
+type CreateUserGroupInput struct {
+	Name            string   `json:"name" binding:"required"`
+	Description     string   `json:"description"`
+	AdCategories    []string `json:"ad_categories"`
+	AdCopyIDs       []int    `json:"ad_copy_ids"`
+	MaxDailyReplies int      `json:"max_daily_replies" binding:"omitempty,min=0"`
+	Weekdays        []int    `json:"weekdays" binding:"omitempty,dive,min=0,max=6"`
+	StartHour       *int     `json:"start_hour" binding:"omitempty,min=0,max=23"`
+	EndHour         *int     `json:"end_hour" binding:"omitempty,min=0,max=23"`
+	Timezone        string   `json:"timezone"`
+	Priority        int      `json:"priority"`
+}
+
+type UpdateUserGroupInput struct {
+	Name            *string   `json:"name"`
+	Description     *string   `json:"description"`
+	AdCategories    *[]string `json:"ad_categories"`
+	AdCopyIDs       *[]int    `json:"ad_copy_ids"`
+	MaxDailyReplies *int      `json:"max_daily_replies" binding:"omitempty,min=0"`
+	Weekdays        *[]int    `json:"weekdays" binding:"omitempty,dive,min=0,max=6"`
+	StartHour       *int      `json:"start_hour" binding:"omitempty,min=0,max=23"`
+	EndHour         *int      `json:"end_hour" binding:"omitempty,min=0,max=23"`
+	ClearSchedule   bool      `json:"clear_schedule"` // 清除时段限制
+	Timezone        *string   `json:"timezone"`
+	Priority        *int      `json:"priority"`
+	IsActive        *bool     `json:"is_active"`
+}
+
+// GroupMembersInput 添加分组成员请求
+type GroupMembersInput struct {
+	TwitterUserIDs []string `json:"twitter_user_ids" binding:"required,min=1"`
+}
@@ -2,10 +2,19 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/zhoubofsy/x-bot/internal/domain/entity"
 )
 
+// AdCopyQuery 广告文案选择条件
+type AdCopyQuery struct {
+	Categories []string // 允许的类别
+	Language   string   // 优先匹配的语言
+	IDs        []int    // 限定的文案ID，为空时不限制
+	CampaignID int      // 限定活动的文案；为 0 且未限定文案ID时只使用不属于任何活动的文案
+}
+
 type AdCopyRepository interface {
 	// GetAll 获取所有广告文案
 	GetAll(ctx context.Context) ([]*entity.AdCopy, error)
@@ -13,11 +22,21 @@ type AdCopyRepository interface {
 	// GetByID 根据ID获取广告文案
 	GetByID(ctx context.Context, id int) (*entity.AdCopy, error)
 
+	// GetByName 获取指定名称的广告文案（名称不唯一，可能返回多个）
+	GetByName(ctx context.Context, name string) ([]*entity.AdCopy, error)
+
 	// GetActiveByCategory 获取指定类别的活跃广告文案
 	GetActiveByCategory(ctx context.Context, category string) ([]*entity.AdCopy, error)
 
-	// GetNextAvailable 获取下一个可用的广告文案（按优先级和使用次数）
-	GetNextAvailable(ctx context.Context, category string) (*entity.AdCopy, error)
+	// GetCandidates 获取符合条件且当前可用（在投放时间和时段内、未达使用上限）的广告文案；
+	// 有对应语言内容的文案时只返回这些文案
+	GetCandidates(ctx context.Context, query AdCopyQuery) ([]*entity.AdCopy, error)
+
+	// GetUsage 统计文案在各自时区内今日的回复（含待审核、待发送）次数，以及待审核、待发送的回复数
+	GetUsage(ctx context.Context, adCopies []*entity.AdCopy, now time.Time) (map[int]entity.AdCopyUsage, error)
+
+	// DeactivateExpired 停用结束时间已过的文案，返回停用的数量
+	DeactivateExpired(ctx context.Context, now time.Time) (int64, error)
 
 	// IncrementUseCount 增加使用次数
 	IncrementUseCount(ctx context.Context, id int) error
@@ -28,10 +47,34 @@ type AdCopyRepository interface {
 	// Update 更新广告文案
 	Update(ctx context.Context, adCopy *entity.AdCopy) error
 
-	// Delete 删除广告文案
+	// ReplaceVariants 替换广告文案的全部语言变体
+	ReplaceVariants(ctx context.Context, adCopyID int, variants []entity.AdCopyVariant) error
+
+	// CreateRevision 以文案当前内容生成新版本（版本号递增）并更新文案的当前版本号
+	CreateRevision(ctx context.Context, adCopy *entity.AdCopy, note string) (*entity.AdCopyRevision, error)
+
+	// GetRevisions 获取文案的全部版本，按版本号倒序
+	GetRevisions(ctx context.Context, adCopyID int) ([]*entity.AdCopyRevision, error)
+
+	// GetRevision 获取文案的指定版本
+	GetRevision(ctx context.Context, adCopyID, revision int) (*entity.AdCopyRevision, error)
+
+	// Delete 软删除广告文案，保留语言变体和历史版本
 	Delete(ctx context.Context, id int) error
 
+	// GetDeleted 获取已删除的广告文案
+	GetDeleted(ctx context.Context) ([]*entity.AdCopy, error)
+
+	// Restore 恢复已删除的广告文案，该文案不存在或未被删除时返回错误
+	Restore(ctx context.Context, id int) error
+
+	// PurgeDeleted 永久删除在 before 之前删除、且没有被回复日志或短链接引用的文案，返回删除的数量
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+
 	// Count 获取广告文案总数
 	Count(ctx context.Context) (int64, error)
+
+	// Transaction 在同一个事务中执行 fn，fn 返回错误时回滚
+	Transaction(ctx context.Context, fn func(repo AdCopyRepository) error) error
 }
 
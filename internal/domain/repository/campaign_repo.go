@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/zhoubofsy/x-bot/internal/domain/entity"
+)
+
+type CampaignRepository interface {
+	// GetAll 获取所有活动
+	GetAll(ctx context.Context) ([]*entity.Campaign, error)
+
+	// GetByID 根据ID获取活动及其广告文案
+	GetByID(ctx context.Context, id int) (*entity.Campaign, error)
+
+	// GetByStatus 获取指定状态的活动，按优先级降序
+	GetByStatus(ctx context.Context, status entity.CampaignStatus) ([]*entity.Campaign, error)
+
+	// Save 保存活动
+	Save(ctx context.Context, campaign *entity.Campaign) error
+
+	// Update 更新活动
+	Update(ctx context.Context, campaign *entity.Campaign) error
+
+	// Finish 结束活动并记录原因
+	Finish(ctx context.Context, id int, reason string) error
+
+	// AddLLMUsage 累加活动的 LLM 调用次数和花费
+	AddLLMUsage(ctx context.Context, id int, calls int, cost float64) error
+
+	// Delete 删除活动，其广告文案保留并解除关联
+	Delete(ctx context.Context, id int) error
+}
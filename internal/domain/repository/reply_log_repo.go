@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/zhoubofsy/x-bot/internal/domain/entity"
 )
@@ -10,35 +11,109 @@ type ReplyLogRepository interface {
 	// Save 保存回复日志
 	Save(ctx context.Context, log *entity.ReplyLog) error
 
+	// Update 更新回复日志
+	Update(ctx context.Context, log *entity.ReplyLog) error
+
+	// UpdateStatusIf 仅当回复日志当前状态为 from 时将其改为 to，返回是否更新成功（用于领取任务）
+	UpdateStatusIf(ctx context.Context, id int, from, to entity.ReplyStatus) (bool, error)
+
+	// GetByID 根据ID获取回复日志
+	GetByID(ctx context.Context, id int) (*entity.ReplyLog, error)
+
 	// GetByTweetID 根据推文ID获取回复日志
 	GetByTweetID(ctx context.Context, tweetID string) (*entity.ReplyLog, error)
 
 	// ExistsByTweetID 检查推文是否已处理过
 	ExistsByTweetID(ctx context.Context, tweetID string) (bool, error)
 
-	// GetTodayReplyCount 获取今日回复数量
+	// GetTodayReplyCount 获取今日（服务器时区）创建的回复数量
 	GetTodayReplyCount(ctx context.Context) (int64, error)
 
-	// GetTodaySuccessCount 获取今日成功回复数量
+	// GetTodaySuccessCount 获取今日（服务器时区）发送成功的回复数量，按 sent_at 统计
 	GetTodaySuccessCount(ctx context.Context) (int64, error)
 
 	// GetRecentLogs 获取最近的回复日志
 	GetRecentLogs(ctx context.Context, limit int) ([]*entity.ReplyLog, error)
 
+	// GetRecentReplyContents 获取最近已发送或即将发送的回复内容，按时间倒序
+	GetRecentReplyContents(ctx context.Context, limit int) ([]string, error)
+
 	// GetLogsByStatus 根据状态获取回复日志
 	GetLogsByStatus(ctx context.Context, status entity.ReplyStatus, limit int) ([]*entity.ReplyLog, error)
 
+	// GetOldestByStatus 按创建时间正序获取指定状态的回复日志（用于队列消费）
+	GetOldestByStatus(ctx context.Context, status entity.ReplyStatus, limit int) ([]*entity.ReplyLog, error)
+
+	// GetDueRetries 获取已到重试时间的回复日志
+	GetDueRetries(ctx context.Context, now time.Time, limit int) ([]*entity.ReplyLog, error)
+
+	// GetLastReplyTimeByAuthor 获取最近一次回复（含待发送）该作者的时间，没有时返回 nil
+	GetLastReplyTimeByAuthor(ctx context.Context, authorID string) (*time.Time, error)
+
+	// CountRepliesByGroupSince 统计指定时间之后分组内的回复（含待发送）次数
+	CountRepliesByGroupSince(ctx context.Context, groupID int, since time.Time) (int64, error)
+
+	// CountRepliesByAuthorSince 统计指定时间之后回复（含待发送）该作者的次数
+	CountRepliesByAuthorSince(ctx context.Context, authorID string, since time.Time) (int64, error)
+
+	// ExistsReplyInConversation 检查指定时间之后是否已回复（含待发送）过该会话
+	// quotedTweetID 不为空时，回复过被引用的推文、其所在会话或其他引用它的推文也算
+	ExistsReplyInConversation(ctx context.Context, conversationID string, quotedTweetID string, since time.Time) (bool, error)
+
+	// GetRepliesForMetrics 获取指定时间之后发送成功、需要更新互动数据的回复，最久未更新的优先
+	GetRepliesForMetrics(ctx context.Context, since time.Time, limit int) ([]*entity.ReplyLog, error)
+
+	// UpdateReplyMetrics 更新回复推文的互动数据
+	UpdateReplyMetrics(ctx context.Context, id int, metrics ReplyMetrics) error
+
+	// GetAdCopyEngagement 按广告文案汇总已拉取互动数据或带短链接的回复（点击计入互动），adCopyIDs 为空时统计全部文案
+	GetAdCopyEngagement(ctx context.Context, adCopyIDs []int) (map[int]*AdCopyEngagement, error)
+
+	// CountByCampaignStatus 按状态统计活动的回复日志数量
+	CountByCampaignStatus(ctx context.Context, campaignID int) (map[entity.ReplyStatus]int64, error)
+
 	// GetStats 获取统计信息
 	GetStats(ctx context.Context) (*ReplyStats, error)
 }
 
+// ReplyMetrics 回复推文的互动数据
+type ReplyMetrics struct {
+	LikeCount    int
+	ReplyCount   int
+	RetweetCount int
+	QuoteCount   int
+	UpdatedAt    time.Time
+}
+
+// AdCopyEngagement 单个广告文案的互动汇总
+type AdCopyEngagement struct {
+	AdCopyID int   `json:"ad_copy_id"`
+	Replies  int64 `json:"replies"` // 已拉取互动数据或带短链接的回复数
+	Engaged  int64 `json:"engaged"` // 其中获得任意互动（含短链接点击）的回复数
+	Likes    int64 `json:"likes"`
+	Comments int64 `json:"comments"`
+	Retweets int64 `json:"retweets"`
+	Quotes   int64 `json:"quotes"`
+}
+
+// EngagementRate 获得互动的回复占比
+func (e *AdCopyEngagement) EngagementRate() float64 {
+	if e == nil || e.Replies == 0 {
+		return 0
+	}
+	return float64(e.Engaged) / float64(e.Replies)
+}
+
 type ReplyStats struct {
-	TotalCount       int64 `json:"total_count"`
-	SuccessCount     int64 `json:"success_count"`
-	FailedCount      int64 `json:"failed_count"`
-	SkippedCount     int64 `json:"skipped_count"`
-	TodayCount       int64 `json:"today_count"`
+	TotalCount        int64 `json:"total_count"`
+	SuccessCount      int64 `json:"success_count"`
+	FailedCount       int64 `json:"failed_count"`
+	SkippedCount      int64 `json:"skipped_count"`
+	BlockedCount      int64 `json:"blocked_count"`
+	PendingCount      int64 `json:"pending_count"`
+	RetryCount        int64 `json:"retry_count"`
+	TodayCount        int64 `json:"today_count"`
 	TodaySuccessCount int64 `json:"today_success_count"`
-	HackathonCount   int64 `json:"hackathon_count"`
+	HackathonCount    int64 `json:"hackathon_count"`
 }
 
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/zhoubofsy/x-bot/internal/domain/entity"
+)
+
+type SearchQueryRepository interface {
+	// GetAll 获取所有搜索条件
+	GetAll(ctx context.Context) ([]*entity.SearchQuery, error)
+
+	// GetActive 获取所有启用的搜索条件
+	GetActive(ctx context.Context) ([]*entity.SearchQuery, error)
+
+	// GetByID 根据ID获取搜索条件
+	GetByID(ctx context.Context, id int) (*entity.SearchQuery, error)
+
+	// Save 保存搜索条件
+	Save(ctx context.Context, query *entity.SearchQuery) error
+
+	// Update 更新搜索条件
+	Update(ctx context.Context, query *entity.SearchQuery) error
+
+	// UpdateCursor 更新搜索游标（since_id）和最后执行时间
+	UpdateCursor(ctx context.Context, id int, sinceID string, lastRunAt time.Time) error
+
+	// Delete 删除搜索条件
+	Delete(ctx context.Context, id int) error
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/zhoubofsy/x-bot/internal/domain/entity"
+)
+
+// TrackedLinkFilter 短链接列表筛选条件，零值字段表示不限制
+type TrackedLinkFilter struct {
+	TweetID    string
+	ReplyLogID int
+	AdCopyID   int
+	Limit      int
+}
+
+// AdCopyClicks 单个广告文案的短链接点击汇总，只统计发送成功的回复
+type AdCopyClicks struct {
+	AdCopyID       int   `json:"ad_copy_id"`
+	TrackedReplies int64 `json:"tracked_replies"` // 带短链接的回复数
+	ClickedReplies int64 `json:"clicked_replies"` // 其中链接被点击过的回复数
+	Clicks         int64 `json:"clicks"`
+}
+
+// CTR 链接被点击过的回复占比
+func (c *AdCopyClicks) CTR() float64 {
+	if c == nil || c.TrackedReplies == 0 {
+		return 0
+	}
+	return float64(c.ClickedReplies) / float64(c.TrackedReplies)
+}
+
+type TrackedLinkRepository interface {
+	// Create 保存短链接，code 冲突时返回错误
+	Create(ctx context.Context, link *entity.TrackedLink) error
+
+	// GetByCode 根据短码获取短链接
+	GetByCode(ctx context.Context, code string) (*entity.TrackedLink, error)
+
+	// RecordClick 保存点击记录并累加短链接的点击次数，返回是否计入
+	// since 非零时，同一访客在 since 之后点击过该短链接则不再记录
+	RecordClick(ctx context.Context, click *entity.LinkClick, since time.Time) (bool, error)
+
+	// List 按条件筛选短链接，按创建时间倒序
+	List(ctx context.Context, filter TrackedLinkFilter) ([]*entity.TrackedLink, error)
+
+	// GetAdCopyClicks 按广告文案汇总短链接点击
+	GetAdCopyClicks(ctx context.Context) (map[int]*AdCopyClicks, error)
+}
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/zhoubofsy/x-bot/internal/domain/entity"
+)
+
+type UserGroupRepository interface {
+	// GetAll 获取所有分组
+	GetAll(ctx context.Context) ([]*entity.UserGroup, error)
+
+	// GetByID 根据ID获取分组
+	GetByID(ctx context.Context, id int) (*entity.UserGroup, error)
+
+	// Save 保存分组
+	Save(ctx context.Context, group *entity.UserGroup) error
+
+	// Update 更新分组
+	Update(ctx context.Context, group *entity.UserGroup) error
+
+	// Delete 删除分组及其成员关系
+	Delete(ctx context.Context, id int) error
+
+	// GetMembers 获取分组成员
+	GetMembers(ctx context.Context, groupID int) ([]*entity.FollowedUser, error)
+
+	// AddMembers 添加分组成员，已存在的成员忽略
+	AddMembers(ctx context.Context, groupID int, userIDs []int) error
+
+	// RemoveMember 移除分组成员
+	RemoveMember(ctx context.Context, groupID int, userID int) error
+}
@@ -2,30 +2,94 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/zhoubofsy/x-bot/internal/domain/entity"
 )
 
+// UserFilter 用户列表筛选条件，零值字段表示不限制
+type UserFilter struct {
+	IsActive     *bool
+	Source       string // manual、following 或 list:<id>
+	Verified     *bool
+	MinFollowers int
+	MaxFollowers int
+	GroupID      int
+	Keyword      string // 匹配用户名或显示名
+	Dormant      *bool  // 是否被判定为休眠或低价值
+	OrderBy      string // followers、last_tweet、hit_rate，默认按 ID
+	Deleted      bool   // 只返回已删除的用户
+}
+
+// UserCheck 一次拉取用户推文的统计结果
+type UserCheck struct {
+	TweetsSeen      int // 本次新增且经过黑客松检测的推文数
+	HackathonHits   int // 其中黑客松推文数
+	LastSeenTweetID string
+	LastTweetAt     *time.Time
+	CheckedAt       time.Time
+	NextCheckAt     *time.Time
+}
+
 type UserRepository interface {
 	// GetAllActiveUsers 获取所有活跃的关注用户
 	GetAllActiveUsers(ctx context.Context) ([]*entity.FollowedUser, error)
 
+	// List 按条件筛选用户
+	List(ctx context.Context, filter UserFilter) ([]*entity.FollowedUser, error)
+
+	// GetAll 获取所有用户（包括已停用的）
+	GetAll(ctx context.Context) ([]*entity.FollowedUser, error)
+
+	// GetAllWithDeleted 获取所有用户（包括已停用和已删除的），用于同步时判断差异
+	GetAllWithDeleted(ctx context.Context) ([]*entity.FollowedUser, error)
+
 	// GetByTwitterID 根据Twitter用户ID获取用户
 	GetByTwitterID(ctx context.Context, twitterID string) (*entity.FollowedUser, error)
 
+	// GetActiveByTwitterID 根据Twitter用户ID获取活跃用户（包含所属分组）
+	GetActiveByTwitterID(ctx context.Context, twitterID string) (*entity.FollowedUser, error)
+
 	// Save 保存用户
 	Save(ctx context.Context, user *entity.FollowedUser) error
 
 	// BatchSave 批量保存用户
 	BatchSave(ctx context.Context, users []*entity.FollowedUser) error
 
-	// UpdateActiveStatus 更新用户活跃状态
+	// UpdateActiveStatus 手动更新用户活跃状态；停用时标记为手动停用，重新启用时清除休眠标记并重置推文统计
 	UpdateActiveStatus(ctx context.Context, twitterID string, isActive bool) error
 
-	// Delete 删除用户
+	// DeactivateByTwitterIDs 批量停用用户
+	DeactivateByTwitterIDs(ctx context.Context, twitterIDs []string) error
+
+	// UpdateProfile 更新用户名、显示名及资料字段（粉丝数、认证状态、最近发推时间等）
+	UpdateProfile(ctx context.Context, user *entity.FollowedUser) error
+
+	// RecordCheck 累加用户推文统计并更新下次拉取时间
+	RecordCheck(ctx context.Context, twitterID string, check UserCheck) error
+
+	// MarkDormant 设置休眠标记（reason 为空表示清除），deactivate 为 true 时同时停用用户
+	MarkDormant(ctx context.Context, twitterID string, reason string, deactivate bool) error
+
+	// UpdateReplyPolicy 更新用户回复频率策略，传 nil 表示使用全局默认值
+	UpdateReplyPolicy(ctx context.Context, twitterID string, cooldownSeconds *int, maxRepliesPerWeek *int) error
+
+	// Delete 软删除用户，保留分组成员关系和推文统计
 	Delete(ctx context.Context, id int) error
 
+	// Restore 恢复已删除的用户，该用户不存在或未被删除时返回错误
+	Restore(ctx context.Context, id int) error
+
+	// PurgeDeleted 永久删除在 before 之前删除、且没有被回复日志引用的用户，返回删除的数量
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+
 	// Count 获取用户总数
 	Count(ctx context.Context) (int64, error)
+
+	// SetGroups 将用户的分组成员关系替换为 groupIDs，为空时移出所有分组
+	SetGroups(ctx context.Context, userID int, groupIDs []int) error
+
+	// Transaction 在同一个事务中执行 fn，fn 返回错误时回滚
+	Transaction(ctx context.Context, fn func(repo UserRepository) error) error
 }
 
@@ -12,6 +12,10 @@ type Client interface {
 	// IsHackathonRelated 判断推文是否与黑客松相关
 	// 返回：是否相关、原始LLM响应、错误
 	IsHackathonRelated(ctx context.Context, tweetContent string) (bool, string, error)
+
+	// CheckSafety 判断在推文下回复推广内容是否安全
+	// 返回：审核结果、原始LLM响应、错误
+	CheckSafety(ctx context.Context, tweetContent string) (*SafetyCheckResult, string, error)
 }
 
 // NewClient 根据配置创建对应的 LLM 客户端
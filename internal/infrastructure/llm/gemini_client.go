@@ -36,11 +36,40 @@ func NewGeminiClient(cfg *config.LLMConfig) Client {
 }
 
 func (c *geminiClient) IsHackathonRelated(ctx context.Context, tweetContent string) (bool, string, error) {
-	if c.client == nil {
-		return false, "", fmt.Errorf("Gemini client not initialized, check API key")
+	content, err := c.generate(ctx, fmt.Sprintf(HackathonDetectionPrompt, tweetContent))
+	if err != nil {
+		return false, "", err
+	}
+
+	var detection HackathonDetectionResult
+	if err := json.Unmarshal([]byte(content), &detection); err != nil {
+		// 如果解析失败，尝试简单判断
+		isRelated := strings.Contains(strings.ToLower(content), "true")
+		return isRelated, content, nil
+	}
+
+	return detection.IsHackathonRelated, content, nil
+}
+
+func (c *geminiClient) CheckSafety(ctx context.Context, tweetContent string) (*SafetyCheckResult, string, error) {
+	content, err := c.generate(ctx, fmt.Sprintf(SafetyCheckPrompt, tweetContent))
+	if err != nil {
+		return nil, "", err
 	}
 
-	prompt := fmt.Sprintf(HackathonDetectionPrompt, tweetContent)
+	var result SafetyCheckResult
+	if err := json.Unmarshal([]byte(content), &result); err != nil {
+		return nil, content, fmt.Errorf("failed to parse safety check result: %w", err)
+	}
+
+	return &result, content, nil
+}
+
+// generate 调用模型生成内容（失败时重试）并返回提取出的 JSON 内容
+func (c *geminiClient) generate(ctx context.Context, prompt string) (string, error) {
+	if c.client == nil {
+		return "", fmt.Errorf("Gemini client not initialized, check API key")
+	}
 
 	var lastErr error
 	for i := 0; i <= c.cfg.MaxRetries; i++ {
@@ -57,17 +86,8 @@ func (c *geminiClient) IsHackathonRelated(ctx context.Context, tweetContent stri
 
 		content := result.Text()
 		content = strings.TrimSpace(content)
-		content = extractJSON(content)
-
-		var detection HackathonDetectionResult
-		if err := json.Unmarshal([]byte(content), &detection); err != nil {
-			// 如果解析失败，尝试简单判断
-			isRelated := strings.Contains(strings.ToLower(content), "true")
-			return isRelated, content, nil
-		}
-
-		return detection.IsHackathonRelated, content, nil
+		return extractJSON(content), nil
 	}
 
-	return false, "", fmt.Errorf("failed after %d retries: %w", c.cfg.MaxRetries, lastErr)
+	return "", fmt.Errorf("failed after %d retries: %w", c.cfg.MaxRetries, lastErr)
 }
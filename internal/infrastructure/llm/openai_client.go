@@ -27,8 +27,36 @@ func NewOpenAIClient(cfg *config.LLMConfig) Client {
 }
 
 func (c *openaiClient) IsHackathonRelated(ctx context.Context, tweetContent string) (bool, string, error) {
-	prompt := fmt.Sprintf(HackathonDetectionPrompt, tweetContent)
+	content, err := c.complete(ctx, fmt.Sprintf(HackathonDetectionPrompt, tweetContent))
+	if err != nil {
+		return false, "", err
+	}
+
+	var result HackathonDetectionResult
+	if err := json.Unmarshal([]byte(content), &result); err != nil {
+		isRelated := strings.Contains(strings.ToLower(content), "true")
+		return isRelated, content, nil
+	}
+
+	return result.IsHackathonRelated, content, nil
+}
+
+func (c *openaiClient) CheckSafety(ctx context.Context, tweetContent string) (*SafetyCheckResult, string, error) {
+	content, err := c.complete(ctx, fmt.Sprintf(SafetyCheckPrompt, tweetContent))
+	if err != nil {
+		return nil, "", err
+	}
 
+	var result SafetyCheckResult
+	if err := json.Unmarshal([]byte(content), &result); err != nil {
+		return nil, content, fmt.Errorf("failed to parse safety check result: %w", err)
+	}
+
+	return &result, content, nil
+}
+
+// complete 发送单轮对话并返回提取出的 JSON 内容
+func (c *openaiClient) complete(ctx context.Context, prompt string) (string, error) {
 	req := ChatRequest{
 		Model: c.cfg.Model,
 		Messages: []ChatMessage{
@@ -43,24 +71,16 @@ func (c *openaiClient) IsHackathonRelated(ctx context.Context, tweetContent stri
 
 	respBody, err := c.doRequest(ctx, req)
 	if err != nil {
-		return false, "", err
+		return "", err
 	}
 
 	if len(respBody.Choices) == 0 {
-		return false, "", fmt.Errorf("empty response from LLM")
+		return "", fmt.Errorf("empty response from LLM")
 	}
 
 	content := respBody.Choices[0].Message.Content
 	content = strings.TrimSpace(content)
-	content = extractJSON(content)
-
-	var result HackathonDetectionResult
-	if err := json.Unmarshal([]byte(content), &result); err != nil {
-		isRelated := strings.Contains(strings.ToLower(content), "true")
-		return isRelated, content, nil
-	}
-
-	return result.IsHackathonRelated, content, nil
+	return extractJSON(content), nil
 }
 
 func (c *openaiClient) doRequest(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
@@ -24,6 +24,29 @@ const HackathonDetectionPrompt = `你是一个推文内容分析助手。请分
 {
     "is_hackathon_related": true或false,
     "confidence": 0.0到1.0之间的数字,
+    "reason": "判断理由（简短说明）",
+    "event_name": "推文中提到的黑客松活动名称，没有则为空字符串",
+    "event_url": "推文中活动的链接，没有则为空字符串",
+    "deadline": "报名或提交截止时间（保留推文原文表述），没有则为空字符串"
+}`
+
+const SafetyCheckPrompt = `你是一个社交媒体内容安全审核助手。我们准备在以下推文下回复一条推广文案，请判断这样做是否合适。
+
+需要检查的风险类别：
+1. sensitive_topic: 涉及裁员、失业、灾难、事故、死亡、疾病、战争等敏感或悲伤话题
+2. negative_sentiment: 作者情绪明显负面，如抱怨、愤怒、失望、求助
+3. controversy: 涉及政治、宗教、歧视、争议事件或网络骂战
+4. scam: 诈骗预警、钓鱼链接提醒、被盗号、假冒活动等安全警示
+
+推文内容：
+"""
+%s
+"""
+
+请以JSON格式回复，不要包含任何其他内容：
+{
+    "is_safe": true或false,
+    "categories": ["命中的风险类别，没有则为空数组"],
     "reason": "判断理由（简短说明）"
 }`
 
@@ -1,5 +1,7 @@
 package llm
 
+import "encoding/json"
+
 // ChatRequest OpenAI Chat API 请求
 type ChatRequest struct {
 	Model       string        `json:"model"`
@@ -40,5 +42,24 @@ type HackathonDetectionResult struct {
 	IsHackathonRelated bool    `json:"is_hackathon_related"`
 	Confidence         float64 `json:"confidence"`
 	Reason             string  `json:"reason"`
+	EventName          string  `json:"event_name"` // 推文中提到的活动名称，用于渲染文案模板
+	EventURL           string  `json:"event_url"`
+	Deadline           string  `json:"deadline"`
+}
+
+// ParseHackathonDetection 解析检测时保存的 LLM 原始响应，无法解析时返回 nil
+func ParseHackathonDetection(raw string) *HackathonDetectionResult {
+	var result HackathonDetectionResult
+	if err := json.Unmarshal([]byte(extractJSON(raw)), &result); err != nil {
+		return nil
+	}
+	return &result
+}
+
+// SafetyCheckResult LLM 安全审核结果
+type SafetyCheckResult struct {
+	IsSafe     bool     `json:"is_safe"`
+	Categories []string `json:"categories"`
+	Reason     string   `json:"reason"`
 }
 
@@ -7,6 +7,7 @@ import (
 	"github.com/zhoubofsy/x-bot/internal/domain/entity"
 	"github.com/zhoubofsy/x-bot/internal/domain/repository"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type adCopyRepository struct {
@@ -19,38 +20,152 @@ func NewAdCopyRepository(db *gorm.DB) repository.AdCopyRepository {
 
 func (r *adCopyRepository) GetAll(ctx context.Context) ([]*entity.AdCopy, error) {
 	var adCopies []*entity.AdCopy
-	err := r.db.WithContext(ctx).Order("priority DESC, created_at DESC").Find(&adCopies).Error
+	err := r.db.WithContext(ctx).Preload("Variants").Order("priority DESC, created_at DESC").Find(&adCopies).Error
 	return adCopies, err
 }
 
 func (r *adCopyRepository) GetByID(ctx context.Context, id int) (*entity.AdCopy, error) {
 	var adCopy entity.AdCopy
-	err := r.db.WithContext(ctx).First(&adCopy, id).Error
+	err := r.db.WithContext(ctx).Preload("Variants").First(&adCopy, id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &adCopy, nil
 }
 
+func (r *adCopyRepository) GetByName(ctx context.Context, name string) ([]*entity.AdCopy, error) {
+	var adCopies []*entity.AdCopy
+	err := r.db.WithContext(ctx).Preload("Variants").Where("name = ?", name).Order("id").Find(&adCopies).Error
+	return adCopies, err
+}
+
 func (r *adCopyRepository) GetActiveByCategory(ctx context.Context, category string) ([]*entity.AdCopy, error) {
 	var adCopies []*entity.AdCopy
 	err := r.db.WithContext(ctx).
+		Preload("Variants").
 		Where("is_active = ? AND category = ?", true, category).
 		Order("priority DESC, use_count ASC").
 		Find(&adCopies).Error
 	return adCopies, err
 }
 
-func (r *adCopyRepository) GetNextAvailable(ctx context.Context, category string) (*entity.AdCopy, error) {
-	var adCopy entity.AdCopy
-	err := r.db.WithContext(ctx).
-		Where("is_active = ? AND category = ?", true, category).
-		Order("priority DESC, use_count ASC, RANDOM()").
-		First(&adCopy).Error
+func (r *adCopyRepository) GetCandidates(ctx context.Context, q repository.AdCopyQuery) ([]*entity.AdCopy, error) {
+	now := time.Now()
+	query := r.db.WithContext(ctx).
+		Preload("Variants").
+		Where("is_active = ?", true).
+		Where("starts_at IS NULL OR starts_at <= ?", now).
+		Where("ends_at IS NULL OR ends_at > ?", now).
+		Where("max_total_uses = 0 OR use_count < max_total_uses")
+	if len(q.Categories) > 0 {
+		query = query.Where("category IN ?", q.Categories)
+	}
+	switch {
+	case q.CampaignID > 0:
+		query = query.Where("campaign_id = ?", q.CampaignID)
+	case len(q.IDs) == 0:
+		query = query.Where("campaign_id IS NULL")
+	}
+	if len(q.IDs) > 0 {
+		query = query.Where("id IN ?", q.IDs)
+	}
+
+	var all []*entity.AdCopy
+	if err := query.Order("priority DESC, use_count ASC").Find(&all).Error; err != nil {
+		return nil, err
+	}
+
+	// 使用上限和时段在内存中判断
+	var capped []*entity.AdCopy
+	for _, adCopy := range all {
+		if adCopy.MaxDailyUses > 0 || adCopy.MaxTotalUses > 0 {
+			capped = append(capped, adCopy)
+		}
+	}
+	usage, err := r.GetUsage(ctx, capped, now)
 	if err != nil {
 		return nil, err
 	}
-	return &adCopy, nil
+	var adCopies []*entity.AdCopy
+	for _, adCopy := range all {
+		if adCopy.Availability(now, usage[adCopy.ID]) == entity.AdCopyStatusAvailable {
+			adCopies = append(adCopies, adCopy)
+		}
+	}
+
+	// 优先选择有对应语言内容的文案
+	if q.Language != "" {
+		var matched []*entity.AdCopy
+		for _, adCopy := range adCopies {
+			if adCopy.HasLanguage(q.Language) {
+				matched = append(matched, adCopy)
+			}
+		}
+		if len(matched) > 0 {
+			return matched, nil
+		}
+	}
+	return adCopies, nil
+}
+
+func (r *adCopyRepository) GetUsage(ctx context.Context, adCopies []*entity.AdCopy, now time.Time) (map[int]entity.AdCopyUsage, error) {
+	usage := make(map[int]entity.AdCopyUsage, len(adCopies))
+	if len(adCopies) == 0 {
+		return usage, nil
+	}
+
+	// 每日次数按文案时区的零点统计，时区相同的文案合并查询
+	ids := make([]int, 0, len(adCopies))
+	bySince := make(map[time.Time][]int)
+	for _, adCopy := range adCopies {
+		ids = append(ids, adCopy.ID)
+		since := adCopy.DayStart(now)
+		bySince[since] = append(bySince[since], adCopy.ID)
+	}
+
+	type countRow struct {
+		AdCopyID int
+		Count    int64
+	}
+	for since, sinceIDs := range bySince {
+		var rows []countRow
+		err := r.db.WithContext(ctx).Model(&entity.ReplyLog{}).
+			Select("ad_copy_id, COUNT(*) AS count").
+			Where("ad_copy_id IN ? AND status IN ? AND created_at >= ?", sinceIDs, entity.OutgoingReplyStatuses, since).
+			Group("ad_copy_id").
+			Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			u := usage[row.AdCopyID]
+			u.Today = row.Count
+			usage[row.AdCopyID] = u
+		}
+	}
+
+	var rows []countRow
+	err := r.db.WithContext(ctx).Model(&entity.ReplyLog{}).
+		Select("ad_copy_id, COUNT(*) AS count").
+		Where("ad_copy_id IN ? AND status IN ?", ids, entity.QueuedReplyStatuses).
+		Group("ad_copy_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		u := usage[row.AdCopyID]
+		u.Queued = row.Count
+		usage[row.AdCopyID] = u
+	}
+	return usage, nil
+}
+
+func (r *adCopyRepository) DeactivateExpired(ctx context.Context, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&entity.AdCopy{}).
+		Where("is_active = ? AND ends_at IS NOT NULL AND ends_at <= ?", true, now).
+		Updates(map[string]interface{}{"is_active": false, "updated_at": now})
+	return result.RowsAffected, result.Error
 }
 
 func (r *adCopyRepository) IncrementUseCount(ctx context.Context, id int) error {
@@ -69,16 +184,134 @@ func (r *adCopyRepository) Save(ctx context.Context, adCopy *entity.AdCopy) erro
 }
 
 func (r *adCopyRepository) Update(ctx context.Context, adCopy *entity.AdCopy) error {
-	return r.db.WithContext(ctx).Save(adCopy).Error
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(adCopy).Error
+}
+
+func (r *adCopyRepository) ReplaceVariants(ctx context.Context, adCopyID int, variants []entity.AdCopyVariant) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("ad_copy_id = ?", adCopyID).Delete(&entity.AdCopyVariant{}).Error; err != nil {
+			return err
+		}
+		if len(variants) == 0 {
+			return nil
+		}
+		for i := range variants {
+			variants[i].ID = 0
+			variants[i].AdCopyID = adCopyID
+		}
+		return tx.Create(&variants).Error
+	})
+}
+
+func (r *adCopyRepository) CreateRevision(ctx context.Context, adCopy *entity.AdCopy, note string) (*entity.AdCopyRevision, error) {
+	revision := entity.NewAdCopyRevision(adCopy, note)
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 锁定文案行，避免并发更新生成重复的版本号
+		var current entity.AdCopy
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&current, adCopy.ID).Error; err != nil {
+			return err
+		}
+		var latest int
+		if err := tx.Model(&entity.AdCopyRevision{}).
+			Where("ad_copy_id = ?", adCopy.ID).
+			Select("COALESCE(MAX(revision), 0)").
+			Scan(&latest).Error; err != nil {
+			return err
+		}
+		revision.Revision = latest + 1
+		if err := tx.Create(revision).Error; err != nil {
+			return err
+		}
+		return tx.Model(&entity.AdCopy{}).Where("id = ?", adCopy.ID).UpdateColumn("revision", revision.Revision).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	adCopy.Revision = revision.Revision
+	return revision, nil
+}
+
+func (r *adCopyRepository) GetRevisions(ctx context.Context, adCopyID int) ([]*entity.AdCopyRevision, error) {
+	var revisions []*entity.AdCopyRevision
+	err := r.db.WithContext(ctx).
+		Where("ad_copy_id = ?", adCopyID).
+		Order("revision DESC").
+		Find(&revisions).Error
+	return revisions, err
+}
+
+func (r *adCopyRepository) GetRevision(ctx context.Context, adCopyID, revision int) (*entity.AdCopyRevision, error) {
+	var rev entity.AdCopyRevision
+	err := r.db.WithContext(ctx).
+		Where("ad_copy_id = ? AND revision = ?", adCopyID, revision).
+		First(&rev).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rev, nil
 }
 
 func (r *adCopyRepository) Delete(ctx context.Context, id int) error {
 	return r.db.WithContext(ctx).Delete(&entity.AdCopy{}, id).Error
 }
 
+func (r *adCopyRepository) GetDeleted(ctx context.Context) ([]*entity.AdCopy, error) {
+	var adCopies []*entity.AdCopy
+	err := r.db.WithContext(ctx).Unscoped().
+		Preload("Variants").
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC").
+		Find(&adCopies).Error
+	return adCopies, err
+}
+
+func (r *adCopyRepository) Restore(ctx context.Context, id int) error {
+	result := r.db.WithContext(ctx).Unscoped().Model(&entity.AdCopy{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *adCopyRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	var ids []int
+	err := r.db.WithContext(ctx).Unscoped().Model(&entity.AdCopy{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Where("NOT EXISTS (SELECT 1 FROM reply_logs rl WHERE rl.ad_copy_id = ad_copies.id)").
+		Where("NOT EXISTS (SELECT 1 FROM tracked_links tl WHERE tl.ad_copy_id = ad_copies.id)").
+		Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+
+	var purged int64
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("ad_copy_id IN ?", ids).Delete(&entity.AdCopyVariant{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("ad_copy_id IN ?", ids).Delete(&entity.AdCopyRevision{}).Error; err != nil {
+			return err
+		}
+		result := tx.Unscoped().Delete(&entity.AdCopy{}, ids)
+		purged = result.RowsAffected
+		return result.Error
+	})
+	return purged, err
+}
+
 func (r *adCopyRepository) Count(ctx context.Context) (int64, error) {
 	var count int64
 	err := r.db.WithContext(ctx).Model(&entity.AdCopy{}).Where("is_active = ?", true).Count(&count).Error
 	return count, err
 }
 
+func (r *adCopyRepository) Transaction(ctx context.Context, fn func(repo repository.AdCopyRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&adCopyRepository{db: tx})
+	})
+}
@@ -0,0 +1,78 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/zhoubofsy/x-bot/internal/domain/entity"
+	"github.com/zhoubofsy/x-bot/internal/domain/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type campaignRepository struct {
+	db *gorm.DB
+}
+
+func NewCampaignRepository(db *gorm.DB) repository.CampaignRepository {
+	return &campaignRepository{db: db}
+}
+
+func (r *campaignRepository) GetAll(ctx context.Context) ([]*entity.Campaign, error) {
+	var campaigns []*entity.Campaign
+	err := r.db.WithContext(ctx).Order("priority DESC, id").Find(&campaigns).Error
+	return campaigns, err
+}
+
+func (r *campaignRepository) GetByID(ctx context.Context, id int) (*entity.Campaign, error) {
+	var campaign entity.Campaign
+	err := r.db.WithContext(ctx).Preload("AdCopies").First(&campaign, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &campaign, nil
+}
+
+func (r *campaignRepository) GetByStatus(ctx context.Context, status entity.CampaignStatus) ([]*entity.Campaign, error) {
+	var campaigns []*entity.Campaign
+	err := r.db.WithContext(ctx).
+		Where("status = ?", status).
+		Order("priority DESC, id").
+		Find(&campaigns).Error
+	return campaigns, err
+}
+
+func (r *campaignRepository) Save(ctx context.Context, campaign *entity.Campaign) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(campaign).Error
+}
+
+func (r *campaignRepository) Update(ctx context.Context, campaign *entity.Campaign) error {
+	// LLM 用量由工作流并发累加，不随活动设置一起覆盖
+	return r.db.WithContext(ctx).Omit(clause.Associations, "llm_calls", "llm_spend").Save(campaign).Error
+}
+
+func (r *campaignRepository) Finish(ctx context.Context, id int, reason string) error {
+	return r.db.WithContext(ctx).Model(&entity.Campaign{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":        entity.CampaignStatusFinished,
+			"finish_reason": reason,
+		}).Error
+}
+
+func (r *campaignRepository) AddLLMUsage(ctx context.Context, id int, calls int, cost float64) error {
+	return r.db.WithContext(ctx).Model(&entity.Campaign{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"llm_calls": gorm.Expr("llm_calls + ?", calls),
+			"llm_spend": gorm.Expr("llm_spend + ?", cost),
+		}).Error
+}
+
+func (r *campaignRepository) Delete(ctx context.Context, id int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&entity.AdCopy{}).Where("campaign_id = ?", id).Update("campaign_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&entity.Campaign{}, id).Error
+	})
+}
@@ -23,6 +23,8 @@ func NewDB(cfg *config.DatabaseConfig) (*gorm.DB, error) {
 
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
 		Logger: logger.Default.LogMode(logLevel),
+		// 唯一约束冲突转换为 gorm.ErrDuplicatedKey，用于识别并发写入同一推文的回复日志
+		TranslateError: true,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect database: %w", err)
@@ -46,21 +48,37 @@ func AutoMigrate(db *gorm.DB) error {
 		AutoMigrate(
 			&entity.FollowedUser{},
 			&entity.AdCopy{},
+			&entity.AdCopyVariant{},
+			&entity.AdCopyRevision{},
 			&entity.ReplyLog{},
 			&entity.BotConfig{},
+			&entity.SearchQuery{},
+			&entity.UserGroup{},
+			&entity.UserGroupMember{},
+			&entity.Campaign{},
+			&entity.TrackedLink{},
+			&entity.LinkClick{},
 		)
 }
 
 // MigrateWithoutConstraints 跳过约束检查的迁移
-// 适用于表已存在但约束不同的情况
+// 适用于表已存在但约束不同的情况：不存在的表直接创建，已存在的表只补齐缺失的字段
 func MigrateWithoutConstraints(db *gorm.DB) error {
 	migrator := db.Migrator()
 
 	tables := []interface{}{
 		&entity.FollowedUser{},
 		&entity.AdCopy{},
+		&entity.AdCopyVariant{},
+		&entity.AdCopyRevision{},
 		&entity.ReplyLog{},
 		&entity.BotConfig{},
+		&entity.SearchQuery{},
+		&entity.UserGroup{},
+		&entity.UserGroupMember{},
+		&entity.Campaign{},
+		&entity.TrackedLink{},
+		&entity.LinkClick{},
 	}
 
 	for _, table := range tables {
@@ -68,6 +86,30 @@ func MigrateWithoutConstraints(db *gorm.DB) error {
 			if err := migrator.CreateTable(table); err != nil {
 				return err
 			}
+			continue
+		}
+		if err := addMissingColumns(db, table); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addMissingColumns 为已存在的表补齐实体中新增的字段
+func addMissingColumns(db *gorm.DB, table interface{}) error {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(table); err != nil {
+		return err
+	}
+
+	migrator := db.Migrator()
+	for _, column := range stmt.Schema.DBNames {
+		if migrator.HasColumn(table, column) {
+			continue
+		}
+		if err := migrator.AddColumn(table, column); err != nil {
+			return fmt.Errorf("failed to add column %s.%s: %w", stmt.Schema.Table, column, err)
 		}
 	}
 
@@ -2,11 +2,13 @@ package postgres
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/zhoubofsy/x-bot/internal/domain/entity"
 	"github.com/zhoubofsy/x-bot/internal/domain/repository"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type replyLogRepository struct {
@@ -21,6 +23,26 @@ func (r *replyLogRepository) Save(ctx context.Context, log *entity.ReplyLog) err
 	return r.db.WithContext(ctx).Create(log).Error
 }
 
+func (r *replyLogRepository) Update(ctx context.Context, log *entity.ReplyLog) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(log).Error
+}
+
+func (r *replyLogRepository) UpdateStatusIf(ctx context.Context, id int, from, to entity.ReplyStatus) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&entity.ReplyLog{}).
+		Where("id = ? AND status = ?", id, from).
+		Update("status", to)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *replyLogRepository) GetByID(ctx context.Context, id int) (*entity.ReplyLog, error) {
+	var log entity.ReplyLog
+	err := r.db.WithContext(ctx).Preload("AdCopy", withDeleted).Preload("AdCopy.Variants").First(&log, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &log, nil
+}
+
 func (r *replyLogRepository) GetByTweetID(ctx context.Context, tweetID string) (*entity.ReplyLog, error) {
 	var log entity.ReplyLog
 	err := r.db.WithContext(ctx).Where("tweet_id = ?", tweetID).First(&log).Error
@@ -38,18 +60,17 @@ func (r *replyLogRepository) ExistsByTweetID(ctx context.Context, tweetID string
 
 func (r *replyLogRepository) GetTodayReplyCount(ctx context.Context) (int64, error) {
 	var count int64
-	today := time.Now().Truncate(24 * time.Hour)
 	err := r.db.WithContext(ctx).Model(&entity.ReplyLog{}).
-		Where("created_at >= ?", today).
+		Where("created_at >= ?", startOfToday()).
 		Count(&count).Error
 	return count, err
 }
 
 func (r *replyLogRepository) GetTodaySuccessCount(ctx context.Context) (int64, error) {
 	var count int64
-	today := time.Now().Truncate(24 * time.Hour)
+	// 按发送时间统计，审核通过或重试后今天才发送的回复计入今日
 	err := r.db.WithContext(ctx).Model(&entity.ReplyLog{}).
-		Where("created_at >= ? AND status = ?", today, entity.ReplyStatusSuccess).
+		Where("sent_at >= ? AND status = ?", startOfToday(), entity.ReplyStatusSuccess).
 		Count(&count).Error
 	return count, err
 }
@@ -57,13 +78,23 @@ func (r *replyLogRepository) GetTodaySuccessCount(ctx context.Context) (int64, e
 func (r *replyLogRepository) GetRecentLogs(ctx context.Context, limit int) ([]*entity.ReplyLog, error) {
 	var logs []*entity.ReplyLog
 	err := r.db.WithContext(ctx).
-		Preload("AdCopy").
+		Preload("AdCopy", withDeleted).
 		Order("created_at DESC").
 		Limit(limit).
 		Find(&logs).Error
 	return logs, err
 }
 
+func (r *replyLogRepository) GetRecentReplyContents(ctx context.Context, limit int) ([]string, error) {
+	var contents []string
+	err := r.db.WithContext(ctx).Model(&entity.ReplyLog{}).
+		Where("status IN ? AND reply_content <> ''", entity.OutgoingReplyStatuses).
+		Order("created_at DESC").
+		Limit(limit).
+		Pluck("reply_content", &contents).Error
+	return contents, err
+}
+
 func (r *replyLogRepository) GetLogsByStatus(ctx context.Context, status entity.ReplyStatus, limit int) ([]*entity.ReplyLog, error) {
 	var logs []*entity.ReplyLog
 	err := r.db.WithContext(ctx).
@@ -74,9 +105,159 @@ func (r *replyLogRepository) GetLogsByStatus(ctx context.Context, status entity.
 	return logs, err
 }
 
+func (r *replyLogRepository) GetOldestByStatus(ctx context.Context, status entity.ReplyStatus, limit int) ([]*entity.ReplyLog, error) {
+	var logs []*entity.ReplyLog
+	err := r.db.WithContext(ctx).
+		Preload("AdCopy", withDeleted).
+		Preload("AdCopy.Variants").
+		Where("status = ?", status).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&logs).Error
+	return logs, err
+}
+
+func (r *replyLogRepository) GetDueRetries(ctx context.Context, now time.Time, limit int) ([]*entity.ReplyLog, error) {
+	var logs []*entity.ReplyLog
+	err := r.db.WithContext(ctx).
+		Preload("AdCopy", withDeleted).
+		Preload("AdCopy.Variants").
+		Where("status = ? AND next_retry_at <= ?", entity.ReplyStatusRetryScheduled, now).
+		Order("next_retry_at ASC").
+		Limit(limit).
+		Find(&logs).Error
+	return logs, err
+}
+
+func (r *replyLogRepository) GetLastReplyTimeByAuthor(ctx context.Context, authorID string) (*time.Time, error) {
+	var logs []*entity.ReplyLog
+	err := r.db.WithContext(ctx).
+		Where("tweet_author_id = ? AND status IN ?", authorID, entity.OutgoingReplyStatuses).
+		Order("created_at DESC").
+		Limit(1).
+		Find(&logs).Error
+	if err != nil || len(logs) == 0 {
+		return nil, err
+	}
+	return &logs[0].CreatedAt, nil
+}
+
+func (r *replyLogRepository) CountRepliesByAuthorSince(ctx context.Context, authorID string, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&entity.ReplyLog{}).
+		Where("tweet_author_id = ? AND status IN ? AND created_at >= ?", authorID, entity.OutgoingReplyStatuses, since).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *replyLogRepository) CountRepliesByGroupSince(ctx context.Context, groupID int, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&entity.ReplyLog{}).
+		Where("user_group_id = ? AND status IN ? AND created_at >= ?", groupID, entity.OutgoingReplyStatuses, since).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *replyLogRepository) ExistsReplyInConversation(ctx context.Context, conversationID string, quotedTweetID string, since time.Time) (bool, error) {
+	if conversationID == "" && quotedTweetID == "" {
+		return false, nil
+	}
+
+	var conds []string
+	var args []interface{}
+	if conversationID != "" {
+		conds = append(conds, "conversation_id = ?")
+		args = append(args, conversationID)
+	}
+	if quotedTweetID != "" {
+		conds = append(conds, "tweet_id = ?", "conversation_id = ?", "quoted_tweet_id = ?")
+		args = append(args, quotedTweetID, quotedTweetID, quotedTweetID)
+	}
+
+	var count int64
+	err := r.db.WithContext(ctx).Model(&entity.ReplyLog{}).
+		Where("status IN ? AND created_at >= ?", entity.OutgoingReplyStatuses, since).
+		Where("("+strings.Join(conds, " OR ")+")", args...).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *replyLogRepository) GetRepliesForMetrics(ctx context.Context, since time.Time, limit int) ([]*entity.ReplyLog, error) {
+	var logs []*entity.ReplyLog
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND reply_tweet_id <> '' AND created_at >= ?", entity.ReplyStatusSuccess, since).
+		Order("metrics_updated_at ASC NULLS FIRST").
+		Limit(limit).
+		Find(&logs).Error
+	return logs, err
+}
+
+func (r *replyLogRepository) UpdateReplyMetrics(ctx context.Context, id int, metrics repository.ReplyMetrics) error {
+	return r.db.WithContext(ctx).Model(&entity.ReplyLog{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"reply_like_count":    metrics.LikeCount,
+			"reply_reply_count":   metrics.ReplyCount,
+			"reply_retweet_count": metrics.RetweetCount,
+			"reply_quote_count":   metrics.QuoteCount,
+			"metrics_updated_at":  metrics.UpdatedAt,
+		}).Error
+}
+
+func (r *replyLogRepository) GetAdCopyEngagement(ctx context.Context, adCopyIDs []int) (map[int]*repository.AdCopyEngagement, error) {
+	// 回复中的短链接被点击也算作互动；带短链接的回复从发送起即可观察点击，未拉取互动数据也参与统计
+	query := r.db.WithContext(ctx).Model(&entity.ReplyLog{}).
+		Select(`ad_copy_id,
+			COUNT(*) AS replies,
+			COUNT(*) FILTER (WHERE reply_like_count + reply_reply_count + reply_retweet_count + reply_quote_count + COALESCE(tl.clicks, 0) > 0) AS engaged,
+			COALESCE(SUM(reply_like_count), 0) AS likes,
+			COALESCE(SUM(reply_reply_count), 0) AS comments,
+			COALESCE(SUM(reply_retweet_count), 0) AS retweets,
+			COALESCE(SUM(reply_quote_count), 0) AS quotes`).
+		Joins("LEFT JOIN (SELECT reply_log_id, SUM(click_count) AS clicks FROM tracked_links GROUP BY reply_log_id) tl ON tl.reply_log_id = reply_logs.id").
+		Where("ad_copy_id IS NOT NULL AND status = ?", entity.ReplyStatusSuccess).
+		Where("metrics_updated_at IS NOT NULL OR tl.reply_log_id IS NOT NULL").
+		Group("ad_copy_id")
+	if len(adCopyIDs) > 0 {
+		query = query.Where("ad_copy_id IN ?", adCopyIDs)
+	}
+
+	var rows []*repository.AdCopyEngagement
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	stats := make(map[int]*repository.AdCopyEngagement, len(rows))
+	for _, row := range rows {
+		stats[row.AdCopyID] = row
+	}
+	return stats, nil
+}
+
+func (r *replyLogRepository) CountByCampaignStatus(ctx context.Context, campaignID int) (map[entity.ReplyStatus]int64, error) {
+	var rows []struct {
+		Status entity.ReplyStatus
+		Count  int64
+	}
+	err := r.db.WithContext(ctx).Model(&entity.ReplyLog{}).
+		Select("status, COUNT(*) AS count").
+		Where("campaign_id = ?", campaignID).
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[entity.ReplyStatus]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
 func (r *replyLogRepository) GetStats(ctx context.Context) (*repository.ReplyStats, error) {
 	stats := &repository.ReplyStats{}
-	today := time.Now().Truncate(24 * time.Hour)
+	today := startOfToday()
 
 	// Total count
 	r.db.WithContext(ctx).Model(&entity.ReplyLog{}).Count(&stats.TotalCount)
@@ -90,11 +271,20 @@ func (r *replyLogRepository) GetStats(ctx context.Context) (*repository.ReplySta
 	// Skipped count
 	r.db.WithContext(ctx).Model(&entity.ReplyLog{}).Where("status = ?", entity.ReplyStatusSkipped).Count(&stats.SkippedCount)
 
+	// Blocked by safety count
+	r.db.WithContext(ctx).Model(&entity.ReplyLog{}).Where("status = ?", entity.ReplyStatusBlockedBySafety).Count(&stats.BlockedCount)
+
+	// Pending approval count
+	r.db.WithContext(ctx).Model(&entity.ReplyLog{}).Where("status = ?", entity.ReplyStatusPending).Count(&stats.PendingCount)
+
+	// Retry scheduled count
+	r.db.WithContext(ctx).Model(&entity.ReplyLog{}).Where("status = ?", entity.ReplyStatusRetryScheduled).Count(&stats.RetryCount)
+
 	// Today count
 	r.db.WithContext(ctx).Model(&entity.ReplyLog{}).Where("created_at >= ?", today).Count(&stats.TodayCount)
 
 	// Today success count
-	r.db.WithContext(ctx).Model(&entity.ReplyLog{}).Where("created_at >= ? AND status = ?", today, entity.ReplyStatusSuccess).Count(&stats.TodaySuccessCount)
+	r.db.WithContext(ctx).Model(&entity.ReplyLog{}).Where("sent_at >= ? AND status = ?", today, entity.ReplyStatusSuccess).Count(&stats.TodaySuccessCount)
 
 	// Hackathon count
 	r.db.WithContext(ctx).Model(&entity.ReplyLog{}).Where("is_hackathon = ?", true).Count(&stats.HackathonCount)
@@ -102,3 +292,14 @@ func (r *replyLogRepository) GetStats(ctx context.Context) (*repository.ReplySta
 	return stats, nil
 }
 
+// startOfToday 服务器时区的今日零点
+func startOfToday() time.Time {
+	now := time.Now()
+	year, month, day := now.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, now.Location())
+}
+
+// withDeleted 预加载回复日志引用的文案时包含已删除的文案，保证历史记录完整
+func withDeleted(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
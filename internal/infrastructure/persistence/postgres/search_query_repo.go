@@ -0,0 +1,64 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/zhoubofsy/x-bot/internal/domain/entity"
+	"github.com/zhoubofsy/x-bot/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+type searchQueryRepository struct {
+	db *gorm.DB
+}
+
+func NewSearchQueryRepository(db *gorm.DB) repository.SearchQueryRepository {
+	return &searchQueryRepository{db: db}
+}
+
+func (r *searchQueryRepository) GetAll(ctx context.Context) ([]*entity.SearchQuery, error) {
+	var queries []*entity.SearchQuery
+	err := r.db.WithContext(ctx).Order("created_at DESC").Find(&queries).Error
+	return queries, err
+}
+
+func (r *searchQueryRepository) GetActive(ctx context.Context) ([]*entity.SearchQuery, error) {
+	var queries []*entity.SearchQuery
+	err := r.db.WithContext(ctx).Where("is_active = ?", true).Order("id ASC").Find(&queries).Error
+	return queries, err
+}
+
+func (r *searchQueryRepository) GetByID(ctx context.Context, id int) (*entity.SearchQuery, error) {
+	var query entity.SearchQuery
+	err := r.db.WithContext(ctx).First(&query, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &query, nil
+}
+
+func (r *searchQueryRepository) Save(ctx context.Context, query *entity.SearchQuery) error {
+	return r.db.WithContext(ctx).Create(query).Error
+}
+
+func (r *searchQueryRepository) Update(ctx context.Context, query *entity.SearchQuery) error {
+	return r.db.WithContext(ctx).Save(query).Error
+}
+
+func (r *searchQueryRepository) UpdateCursor(ctx context.Context, id int, sinceID string, lastRunAt time.Time) error {
+	updates := map[string]interface{}{
+		"last_run_at": lastRunAt,
+		"updated_at":  lastRunAt,
+	}
+	if sinceID != "" {
+		updates["since_id"] = sinceID
+	}
+	return r.db.WithContext(ctx).Model(&entity.SearchQuery{}).
+		Where("id = ?", id).
+		Updates(updates).Error
+}
+
+func (r *searchQueryRepository) Delete(ctx context.Context, id int) error {
+	return r.db.WithContext(ctx).Delete(&entity.SearchQuery{}, id).Error
+}
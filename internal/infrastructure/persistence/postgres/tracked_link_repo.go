@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/zhoubofsy/x-bot/internal/domain/entity"
+	"github.com/zhoubofsy/x-bot/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+type trackedLinkRepository struct {
+	db *gorm.DB
+}
+
+func NewTrackedLinkRepository(db *gorm.DB) repository.TrackedLinkRepository {
+	return &trackedLinkRepository{db: db}
+}
+
+func (r *trackedLinkRepository) Create(ctx context.Context, link *entity.TrackedLink) error {
+	return r.db.WithContext(ctx).Create(link).Error
+}
+
+func (r *trackedLinkRepository) GetByCode(ctx context.Context, code string) (*entity.TrackedLink, error) {
+	var link entity.TrackedLink
+	err := r.db.WithContext(ctx).Where("code = ?", code).First(&link).Error
+	if err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+func (r *trackedLinkRepository) RecordClick(ctx context.Context, click *entity.LinkClick, since time.Time) (bool, error) {
+	recorded := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if !since.IsZero() && click.VisitorHash != "" {
+			var count int64
+			err := tx.Model(&entity.LinkClick{}).
+				Where("tracked_link_id = ? AND visitor_hash = ? AND clicked_at >= ?", click.TrackedLinkID, click.VisitorHash, since).
+				Count(&count).Error
+			if err != nil || count > 0 {
+				return err
+			}
+		}
+
+		if err := tx.Create(click).Error; err != nil {
+			return err
+		}
+		recorded = true
+		return tx.Model(&entity.TrackedLink{}).
+			Where("id = ?", click.TrackedLinkID).
+			Updates(map[string]interface{}{
+				"click_count":     gorm.Expr("click_count + 1"),
+				"last_clicked_at": click.ClickedAt,
+			}).Error
+	})
+	return recorded && err == nil, err
+}
+
+func (r *trackedLinkRepository) List(ctx context.Context, filter repository.TrackedLinkFilter) ([]*entity.TrackedLink, error) {
+	query := r.db.WithContext(ctx)
+	if filter.TweetID != "" {
+		query = query.Where("tweet_id = ?", filter.TweetID)
+	}
+	if filter.ReplyLogID > 0 {
+		query = query.Where("reply_log_id = ?", filter.ReplyLogID)
+	}
+	if filter.AdCopyID > 0 {
+		query = query.Where("ad_copy_id = ?", filter.AdCopyID)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var links []*entity.TrackedLink
+	err := query.Order("created_at DESC").Find(&links).Error
+	return links, err
+}
+
+func (r *trackedLinkRepository) GetAdCopyClicks(ctx context.Context) (map[int]*repository.AdCopyClicks, error) {
+	var rows []*repository.AdCopyClicks
+	err := r.db.WithContext(ctx).Table("tracked_links AS tl").
+		Select(`tl.ad_copy_id,
+			COUNT(DISTINCT tl.reply_log_id) AS tracked_replies,
+			COUNT(DISTINCT tl.reply_log_id) FILTER (WHERE tl.click_count > 0) AS clicked_replies,
+			COALESCE(SUM(tl.click_count), 0) AS clicks`).
+		Joins("JOIN reply_logs rl ON rl.id = tl.reply_log_id AND rl.status = ?", entity.ReplyStatusSuccess).
+		Where("tl.ad_copy_id IS NOT NULL").
+		Group("tl.ad_copy_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make(map[int]*repository.AdCopyClicks, len(rows))
+	for _, row := range rows {
+		stats[row.AdCopyID] = row
+	}
+	return stats, nil
+}
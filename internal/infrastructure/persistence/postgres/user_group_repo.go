@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/zhoubofsy/x-bot/internal/domain/entity"
+	"github.com/zhoubofsy/x-bot/internal/domain/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type userGroupRepository struct {
+	db *gorm.DB
+}
+
+func NewUserGroupRepository(db *gorm.DB) repository.UserGroupRepository {
+	return &userGroupRepository{db: db}
+}
+
+func (r *userGroupRepository) GetAll(ctx context.Context) ([]*entity.UserGroup, error) {
+	var groups []*entity.UserGroup
+	err := r.db.WithContext(ctx).Order("priority DESC, id ASC").Find(&groups).Error
+	return groups, err
+}
+
+func (r *userGroupRepository) GetByID(ctx context.Context, id int) (*entity.UserGroup, error) {
+	var group entity.UserGroup
+	err := r.db.WithContext(ctx).First(&group, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+func (r *userGroupRepository) Save(ctx context.Context, group *entity.UserGroup) error {
+	return r.db.WithContext(ctx).Create(group).Error
+}
+
+func (r *userGroupRepository) Update(ctx context.Context, group *entity.UserGroup) error {
+	return r.db.WithContext(ctx).Save(group).Error
+}
+
+func (r *userGroupRepository) Delete(ctx context.Context, id int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_group_id = ?", id).Delete(&entity.UserGroupMember{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&entity.UserGroup{}, id).Error
+	})
+}
+
+func (r *userGroupRepository) GetMembers(ctx context.Context, groupID int) ([]*entity.FollowedUser, error) {
+	var users []*entity.FollowedUser
+	err := r.db.WithContext(ctx).
+		Joins("JOIN user_group_members m ON m.followed_user_id = followed_users.id").
+		Where("m.user_group_id = ?", groupID).
+		Order("followed_users.id").
+		Find(&users).Error
+	return users, err
+}
+
+func (r *userGroupRepository) AddMembers(ctx context.Context, groupID int, userIDs []int) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	members := make([]entity.UserGroupMember, 0, len(userIDs))
+	for _, id := range userIDs {
+		members = append(members, entity.UserGroupMember{UserGroupID: groupID, FollowedUserID: id})
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&members).Error
+}
+
+func (r *userGroupRepository) RemoveMember(ctx context.Context, groupID int, userID int) error {
+	return r.db.WithContext(ctx).
+		Where("user_group_id = ? AND followed_user_id = ?", groupID, userID).
+		Delete(&entity.UserGroupMember{}).Error
+}
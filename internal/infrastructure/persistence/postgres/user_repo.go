@@ -2,6 +2,8 @@ package postgres
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	"github.com/zhoubofsy/x-bot/internal/domain/entity"
 	"github.com/zhoubofsy/x-bot/internal/domain/repository"
@@ -19,7 +21,76 @@ func NewUserRepository(db *gorm.DB) repository.UserRepository {
 
 func (r *userRepository) GetAllActiveUsers(ctx context.Context) ([]*entity.FollowedUser, error) {
 	var users []*entity.FollowedUser
-	err := r.db.WithContext(ctx).Where("is_active = ?", true).Find(&users).Error
+	err := r.db.WithContext(ctx).Preload("Groups").Where("is_active = ?", true).Find(&users).Error
+	return users, err
+}
+
+func (r *userRepository) List(ctx context.Context, filter repository.UserFilter) ([]*entity.FollowedUser, error) {
+	query := r.db.WithContext(ctx).Preload("Groups")
+	if filter.Deleted {
+		query = query.Unscoped().Where("deleted_at IS NOT NULL")
+	}
+
+	if filter.IsActive != nil {
+		query = query.Where("is_active = ?", *filter.IsActive)
+	}
+	if filter.Source != "" {
+		query = query.Where("source = ?", filter.Source)
+	}
+	if filter.Verified != nil {
+		query = query.Where("verified = ?", *filter.Verified)
+	}
+	if filter.MinFollowers > 0 {
+		query = query.Where("followers_count >= ?", filter.MinFollowers)
+	}
+	if filter.MaxFollowers > 0 {
+		query = query.Where("followers_count <= ?", filter.MaxFollowers)
+	}
+	if filter.GroupID > 0 {
+		query = query.Where("id IN (SELECT followed_user_id FROM user_group_members WHERE user_group_id = ?)", filter.GroupID)
+	}
+	if filter.Dormant != nil {
+		if *filter.Dormant {
+			query = query.Where("dormant_reason <> ''")
+		} else {
+			query = query.Where("dormant_reason IS NULL OR dormant_reason = ''")
+		}
+	}
+	if filter.Keyword != "" {
+		like := "%" + escapeLike(filter.Keyword) + "%"
+		query = query.Where("username ILIKE ? OR display_name ILIKE ?", like, like)
+	}
+
+	switch filter.OrderBy {
+	case "followers":
+		query = query.Order("followers_count DESC")
+	case "last_tweet":
+		query = query.Order("last_tweet_at DESC NULLS LAST")
+	case "hit_rate":
+		query = query.Order("hackathon_hits::float / NULLIF(tweets_seen, 0) DESC NULLS LAST")
+	default:
+		query = query.Order("id")
+	}
+
+	var users []*entity.FollowedUser
+	err := query.Find(&users).Error
+	return users, err
+}
+
+// escapeLike 转义 LIKE/ILIKE 模式中的通配符，使关键词按字面匹配
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+func (r *userRepository) GetAll(ctx context.Context) ([]*entity.FollowedUser, error) {
+	var users []*entity.FollowedUser
+	err := r.db.WithContext(ctx).Order("id").Find(&users).Error
+	return users, err
+}
+
+func (r *userRepository) GetAllWithDeleted(ctx context.Context) ([]*entity.FollowedUser, error) {
+	var users []*entity.FollowedUser
+	err := r.db.WithContext(ctx).Unscoped().Order("id").Find(&users).Error
 	return users, err
 }
 
@@ -32,10 +103,21 @@ func (r *userRepository) GetByTwitterID(ctx context.Context, twitterID string) (
 	return &user, nil
 }
 
+func (r *userRepository) GetActiveByTwitterID(ctx context.Context, twitterID string) (*entity.FollowedUser, error) {
+	var user entity.FollowedUser
+	err := r.db.WithContext(ctx).Preload("Groups").
+		Where("twitter_user_id = ? AND is_active = ?", twitterID, true).
+		First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
 func (r *userRepository) Save(ctx context.Context, user *entity.FollowedUser) error {
 	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
 		Columns:   []clause.Column{{Name: "twitter_user_id"}},
-		DoUpdates: clause.AssignmentColumns([]string{"username", "display_name", "is_active", "updated_at"}),
+		DoUpdates: upsertUserColumns(),
 	}).Create(user).Error
 }
 
@@ -45,23 +127,176 @@ func (r *userRepository) BatchSave(ctx context.Context, users []*entity.Followed
 	}
 	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
 		Columns:   []clause.Column{{Name: "twitter_user_id"}},
-		DoUpdates: clause.AssignmentColumns([]string{"username", "display_name", "is_active", "updated_at"}),
+		DoUpdates: upsertUserColumns(),
 	}).CreateInBatches(users, 100).Error
 }
 
 func (r *userRepository) UpdateActiveStatus(ctx context.Context, twitterID string, isActive bool) error {
+	// 手动停用的用户不会被关注同步重新启用
+	updates := map[string]interface{}{"is_active": isActive, "manually_disabled": !isActive}
+	if isActive {
+		// 重新启用后按新的统计窗口评估
+		updates["dormant_reason"] = ""
+		updates["tweets_seen"] = 0
+		updates["hackathon_hits"] = 0
+		updates["next_check_at"] = nil
+	}
+	return r.db.WithContext(ctx).Model(&entity.FollowedUser{}).
+		Where("twitter_user_id = ?", twitterID).
+		Updates(updates).Error
+}
+
+func (r *userRepository) DeactivateByTwitterIDs(ctx context.Context, twitterIDs []string) error {
+	if len(twitterIDs) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Model(&entity.FollowedUser{}).
+		Where("twitter_user_id IN ?", twitterIDs).
+		Update("is_active", false).Error
+}
+
+func (r *userRepository) UpdateProfile(ctx context.Context, user *entity.FollowedUser) error {
+	return r.db.WithContext(ctx).Model(&entity.FollowedUser{}).
+		Where("twitter_user_id = ?", user.TwitterUserID).
+		Select(append([]string{"username", "display_name"}, profileColumns...)).
+		Updates(user).Error
+}
+
+func (r *userRepository) RecordCheck(ctx context.Context, twitterID string, check repository.UserCheck) error {
+	updates := map[string]interface{}{
+		"tweets_seen":     gorm.Expr("tweets_seen + ?", check.TweetsSeen),
+		"hackathon_hits":  gorm.Expr("hackathon_hits + ?", check.HackathonHits),
+		"last_checked_at": check.CheckedAt,
+		"next_check_at":   check.NextCheckAt,
+	}
+	if check.LastSeenTweetID != "" {
+		updates["last_seen_tweet_id"] = check.LastSeenTweetID
+	}
+	if check.LastTweetAt != nil {
+		updates["last_tweet_at"] = gorm.Expr("GREATEST(last_tweet_at, ?)", *check.LastTweetAt)
+	}
 	return r.db.WithContext(ctx).Model(&entity.FollowedUser{}).
 		Where("twitter_user_id = ?", twitterID).
-		Update("is_active", isActive).Error
+		Updates(updates).Error
+}
+
+func (r *userRepository) MarkDormant(ctx context.Context, twitterID string, reason string, deactivate bool) error {
+	updates := map[string]interface{}{"dormant_reason": reason}
+	if deactivate {
+		updates["is_active"] = false
+	}
+	return r.db.WithContext(ctx).Model(&entity.FollowedUser{}).
+		Where("twitter_user_id = ?", twitterID).
+		Updates(updates).Error
+}
+
+func (r *userRepository) UpdateReplyPolicy(ctx context.Context, twitterID string, cooldownSeconds *int, maxRepliesPerWeek *int) error {
+	return r.db.WithContext(ctx).Model(&entity.FollowedUser{}).
+		Where("twitter_user_id = ?", twitterID).
+		Updates(map[string]interface{}{
+			"reply_cooldown_seconds": cooldownSeconds,
+			"max_replies_per_week":   maxRepliesPerWeek,
+		}).Error
 }
 
 func (r *userRepository) Delete(ctx context.Context, id int) error {
 	return r.db.WithContext(ctx).Delete(&entity.FollowedUser{}, id).Error
 }
 
+func (r *userRepository) Restore(ctx context.Context, id int) error {
+	result := r.db.WithContext(ctx).Unscoped().Model(&entity.FollowedUser{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *userRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	var ids []int
+	err := r.db.WithContext(ctx).Unscoped().Model(&entity.FollowedUser{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Where("NOT EXISTS (SELECT 1 FROM reply_logs rl WHERE rl.tweet_author_id = followed_users.twitter_user_id)").
+		Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+
+	var purged int64
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("followed_user_id IN ?", ids).Delete(&entity.UserGroupMember{}).Error; err != nil {
+			return err
+		}
+		result := tx.Unscoped().Delete(&entity.FollowedUser{}, ids)
+		purged = result.RowsAffected
+		return result.Error
+	})
+	return purged, err
+}
+
 func (r *userRepository) Count(ctx context.Context) (int64, error) {
 	var count int64
 	err := r.db.WithContext(ctx).Model(&entity.FollowedUser{}).Where("is_active = ?", true).Count(&count).Error
 	return count, err
 }
 
+func (r *userRepository) SetGroups(ctx context.Context, userID int, groupIDs []int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		query := tx.Where("followed_user_id = ?", userID)
+		if len(groupIDs) > 0 {
+			query = query.Where("user_group_id NOT IN ?", groupIDs)
+		}
+		if err := query.Delete(&entity.UserGroupMember{}).Error; err != nil {
+			return err
+		}
+		if len(groupIDs) == 0 {
+			return nil
+		}
+		members := make([]entity.UserGroupMember, 0, len(groupIDs))
+		for _, id := range groupIDs {
+			members = append(members, entity.UserGroupMember{UserGroupID: id, FollowedUserID: userID})
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&members).Error
+	})
+}
+
+func (r *userRepository) Transaction(ctx context.Context, fn func(repo repository.UserRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&userRepository{db: tx})
+	})
+}
+
+// profileColumns 用户资料字段，同步和定期刷新时更新
+var profileColumns = []string{
+	"description", "followers_count", "following_count", "tweet_count",
+	"protected", "verified", "location", "profile_url", "last_tweet_at", "profile_refreshed_at",
+}
+
+// upsertUserColumns 冲突时更新的字段；手动添加的用户保留 manual 来源，不会被同步覆盖；
+// 手动停用或因休眠被自动停用的用户保持停用，需手动重新启用；
+// 已删除的用户同步时保持删除，再次手动添加时恢复
+func upsertUserColumns() clause.Set {
+	return append(
+		clause.AssignmentColumns(append([]string{"username", "display_name", "updated_at"}, profileColumns...)),
+		clause.Assignment{
+			Column: clause.Column{Name: "source"},
+			Value: gorm.Expr("CASE WHEN followed_users.source = ? THEN followed_users.source ELSE excluded.source END",
+				entity.UserSourceManual),
+		},
+		clause.Assignment{
+			Column: clause.Column{Name: "is_active"},
+			Value: gorm.Expr("CASE WHEN followed_users.manually_disabled " +
+				"OR (followed_users.dormant_reason <> '' AND NOT followed_users.is_active) " +
+				"THEN followed_users.is_active ELSE excluded.is_active END"),
+		},
+		clause.Assignment{
+			Column: clause.Column{Name: "deleted_at"},
+			Value: gorm.Expr("CASE WHEN excluded.source = ? THEN NULL ELSE followed_users.deleted_at END",
+				entity.UserSourceManual),
+		},
+	)
+}
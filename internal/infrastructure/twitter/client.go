@@ -23,6 +23,13 @@ import (
 const (
 	baseURL  = "https://api.x.com/2"
 	oauthURL = "https://api.x.com"
+
+	// 获取推文时请求的字段
+	tweetFields = "created_at,author_id,lang,conversation_id,referenced_tweets,public_metrics,reply_settings"
+	userFields  = "public_metrics,verified"
+
+	// 查询用户资料时请求的字段
+	profileUserFields = "description,protected,public_metrics,verified,location,url,most_recent_tweet_id"
 )
 
 type Client interface {
@@ -30,6 +37,12 @@ type Client interface {
 	GetFollowing(ctx context.Context, userID string) ([]TwitterUser, error)
 	GetUserTweets(ctx context.Context, userID string, maxResults int) ([]Tweet, error)
 	ReplyToTweet(ctx context.Context, tweetID string, text string) (*Tweet, error)
+	SearchRecent(ctx context.Context, query string, sinceID string, maxResults int) (*SearchResult, error)
+	GetListMembers(ctx context.Context, listID string) ([]TwitterUser, error)
+	GetListTweets(ctx context.Context, listID string, maxResults int) ([]Tweet, error)
+	GetUsersByUsernames(ctx context.Context, usernames []string) ([]TwitterUser, []APIError, error)
+	GetUsersByIDs(ctx context.Context, ids []string) ([]TwitterUser, []APIError, error)
+	GetTweetsByIDs(ctx context.Context, ids []string) ([]Tweet, error)
 }
 
 type client struct {
@@ -64,7 +77,7 @@ func (c *client) GetMe(ctx context.Context) (*TwitterUser, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, c.handleError(resp)
+		return nil, handleError(resp)
 	}
 
 	var result struct {
@@ -83,7 +96,7 @@ func (c *client) GetFollowing(ctx context.Context, userID string) ([]TwitterUser
 	nextToken := ""
 
 	for {
-		endpoint := fmt.Sprintf("%s/users/%s/following?max_results=100", baseURL, userID)
+		endpoint := fmt.Sprintf("%s/users/%s/following?max_results=100&user.fields=%s", baseURL, userID, profileUserFields)
 		if nextToken != "" {
 			endpoint += "&pagination_token=" + nextToken
 		}
@@ -102,7 +115,7 @@ func (c *client) GetFollowing(ctx context.Context, userID string) ([]TwitterUser
 
 		if resp.StatusCode != http.StatusOK {
 			resp.Body.Close()
-			return nil, c.handleError(resp)
+			return nil, handleError(resp)
 		}
 
 		var result FollowingResponse
@@ -131,8 +144,8 @@ func (c *client) GetUserTweets(ctx context.Context, userID string, maxResults in
 		maxResults = 5
 	}
 
-	endpoint := fmt.Sprintf("%s/users/%s/tweets?max_results=%d&tweet.fields=created_at,author_id",
-		baseURL, userID, maxResults)
+	endpoint := fmt.Sprintf("%s/users/%s/tweets?max_results=%d&tweet.fields=%s&expansions=author_id&user.fields=%s",
+		baseURL, userID, maxResults, tweetFields, userFields)
 
 	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
 	if err != nil {
@@ -148,7 +161,7 @@ func (c *client) GetUserTweets(ctx context.Context, userID string, maxResults in
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, c.handleError(resp)
+		return nil, handleError(resp)
 		// fake response for testing
 		// fakeData := `{
 		// 	"data": [
@@ -167,10 +180,249 @@ func (c *client) GetUserTweets(ctx context.Context, userID string, maxResults in
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return nil, err
 	}
+	result.attachAuthors()
+
+	return result.Data, nil
+}
+
+// GetUsersByUsernames 按用户名批量查询用户资料，返回找到的用户和查询失败的用户名错误
+func (c *client) GetUsersByUsernames(ctx context.Context, usernames []string) ([]TwitterUser, []APIError, error) {
+	return c.lookupUsers(ctx, "/users/by", "usernames", usernames)
+}
+
+// GetUsersByIDs 按用户ID批量查询用户资料，返回找到的用户和查询失败（如已注销、被冻结）的用户错误
+func (c *client) GetUsersByIDs(ctx context.Context, ids []string) ([]TwitterUser, []APIError, error) {
+	return c.lookupUsers(ctx, "/users", "ids", ids)
+}
+
+// lookupUsers 批量查询用户资料，每次请求最多 100 个
+func (c *client) lookupUsers(ctx context.Context, path, param string, values []string) ([]TwitterUser, []APIError, error) {
+	var users []TwitterUser
+	var lookupErrors []APIError
+
+	for start := 0; start < len(values); start += 100 {
+		end := start + 100
+		if end > len(values) {
+			end = len(values)
+		}
+
+		params := url.Values{}
+		params.Set(param, strings.Join(values[start:end], ","))
+		params.Set("user.fields", profileUserFields)
+		endpoint := baseURL + path + "?" + params.Encode()
+
+		req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		c.signRequest(req, "GET", endpoint, nil)
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			err := handleError(resp)
+			resp.Body.Close()
+			return nil, nil, err
+		}
+
+		var result UsersLookupResponse
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			resp.Body.Close()
+			return nil, nil, err
+		}
+		resp.Body.Close()
+
+		users = append(users, result.Data...)
+		lookupErrors = append(lookupErrors, result.Errors...)
+	}
+
+	return users, lookupErrors, nil
+}
+
+// GetListMembers 获取列表的全部成员
+func (c *client) GetListMembers(ctx context.Context, listID string) ([]TwitterUser, error) {
+	var allUsers []TwitterUser
+	nextToken := ""
+
+	for {
+		endpoint := fmt.Sprintf("%s/lists/%s/members?max_results=100&user.fields=%s", baseURL, listID, profileUserFields)
+		if nextToken != "" {
+			endpoint += "&pagination_token=" + nextToken
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		c.signRequest(req, "GET", endpoint, nil)
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, handleError(resp)
+		}
+
+		var result FollowingResponse
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+		resp.Body.Close()
+
+		allUsers = append(allUsers, result.Data...)
+
+		if result.Meta == nil || result.Meta.NextToken == "" {
+			break
+		}
+		nextToken = result.Meta.NextToken
+	}
+
+	return allUsers, nil
+}
+
+// GetTweetsByIDs 按ID批量获取推文（含互动数据），已删除或不可见的推文不会返回
+func (c *client) GetTweetsByIDs(ctx context.Context, ids []string) ([]Tweet, error) {
+	var tweets []Tweet
+
+	for start := 0; start < len(ids); start += 100 {
+		end := start + 100
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		params := url.Values{}
+		params.Set("ids", strings.Join(ids[start:end], ","))
+		params.Set("tweet.fields", tweetFields)
+		endpoint := baseURL + "/tweets?" + params.Encode()
+
+		req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		c.signRequest(req, "GET", endpoint, nil)
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			err := handleError(resp)
+			resp.Body.Close()
+			return nil, err
+		}
+
+		var result TweetsResponse
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+		resp.Body.Close()
+
+		tweets = append(tweets, result.Data...)
+	}
+
+	return tweets, nil
+}
+
+// GetListTweets 获取列表时间线（列表成员的最新推文）
+func (c *client) GetListTweets(ctx context.Context, listID string, maxResults int) ([]Tweet, error) {
+	if maxResults > 100 {
+		maxResults = 100
+	}
+	if maxResults < 1 {
+		maxResults = 1
+	}
+
+	endpoint := fmt.Sprintf("%s/lists/%s/tweets?max_results=%d&tweet.fields=%s&expansions=author_id&user.fields=%s",
+		baseURL, listID, maxResults, tweetFields, userFields)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	c.signRequest(req, "GET", endpoint, nil)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, handleError(resp)
+	}
+
+	var result TweetsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	result.attachAuthors()
 
 	return result.Data, nil
 }
 
+// SearchRecent 搜索最近 7 天的推文，sinceID 不为空时只返回比它更新的推文
+func (c *client) SearchRecent(ctx context.Context, query string, sinceID string, maxResults int) (*SearchResult, error) {
+	if maxResults > 100 {
+		maxResults = 100
+	}
+	if maxResults < 10 {
+		maxResults = 10
+	}
+
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("max_results", strconv.Itoa(maxResults))
+	params.Set("tweet.fields", tweetFields)
+	params.Set("expansions", "author_id")
+	params.Set("user.fields", userFields)
+	if sinceID != "" {
+		params.Set("since_id", sinceID)
+	}
+	endpoint := baseURL + "/tweets/search/recent?" + params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	c.signRequest(req, "GET", endpoint, nil)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, handleError(resp)
+	}
+
+	var result TweetsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	result.attachAuthors()
+
+	searchResult := &SearchResult{Tweets: result.Data}
+	if result.Meta != nil {
+		searchResult.NewestID = result.Meta.NewestID
+	}
+	return searchResult, nil
+}
+
 func (c *client) ReplyToTweet(ctx context.Context, tweetID string, text string) (*Tweet, error) {
 	endpoint := baseURL + "/tweets"
 
@@ -203,7 +455,7 @@ func (c *client) ReplyToTweet(ctx context.Context, tweetID string, text string)
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusCreated {
-		return nil, c.handleError(resp)
+		return nil, handleError(resp)
 	}
 
 	var result CreateTweetResponse
@@ -266,7 +518,8 @@ func (c *client) signRequestForPost(req *http.Request, endpoint string) {
 	req.Header.Set("Authorization", authHeader)
 }
 
-func (c *client) handleError(resp *http.Response) error {
+// handleError 将非成功响应转换为 *Error，OAuth 客户端和流客户端共用
+func handleError(resp *http.Response) error {
 	body, _ := io.ReadAll(resp.Body)
 
 	// 添加更详细的错误提示
@@ -280,7 +533,16 @@ func (c *client) handleError(resp *http.Response) error {
 		hint = " (请求过于频繁: 已触发速率限制，请稍后重试)"
 	}
 
-	return fmt.Errorf("twitter API error: status=%d%s, body=%s", resp.StatusCode, hint, string(body))
+	apiErr := &Error{
+		StatusCode: resp.StatusCode,
+		Hint:       hint,
+		Body:       string(body),
+	}
+	if reset, err := strconv.ParseInt(resp.Header.Get("x-rate-limit-reset"), 10, 64); err == nil {
+		apiErr.RateLimitReset = time.Unix(reset, 0)
+	}
+
+	return apiErr
 }
 
 func (c *client) signRequest(req *http.Request, method, endpoint string, params map[string]string) {
@@ -0,0 +1,87 @@
+package twitter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// Error Twitter API 返回的错误
+type Error struct {
+	StatusCode     int
+	Hint           string
+	Body           string
+	RateLimitReset time.Time // 429 时 x-rate-limit-reset 头给出的限流重置时间
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("twitter API error: status=%d%s, body=%s", e.StatusCode, e.Hint, e.Body)
+}
+
+// FailureReason 回复失败原因分类
+type FailureReason string
+
+const (
+	FailureRateLimited      FailureReason = "rate_limited"
+	FailureDuplicateContent FailureReason = "duplicate_content"
+	FailureTweetDeleted     FailureReason = "tweet_deleted"
+	FailureReplyRestricted  FailureReason = "reply_restricted"
+	FailureAuth             FailureReason = "auth"
+	FailureServerError      FailureReason = "server_error"
+	FailureNetwork          FailureReason = "network"
+	FailureUnknown          FailureReason = "unknown"
+)
+
+// IsTransient 是否为可重试的临时性失败
+func (r FailureReason) IsTransient() bool {
+	switch r {
+	case FailureRateLimited, FailureServerError, FailureNetwork:
+		return true
+	}
+	return false
+}
+
+// ClassifyError 根据状态码和错误内容对失败原因分类
+func ClassifyError(err error) FailureReason {
+	if err == nil {
+		return ""
+	}
+
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		var netErr net.Error
+		if errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded) {
+			return FailureNetwork
+		}
+		return FailureUnknown
+	}
+
+	body := strings.ToLower(apiErr.Body)
+	switch {
+	case apiErr.StatusCode == 429:
+		return FailureRateLimited
+	case apiErr.StatusCode == 401:
+		return FailureAuth
+	case apiErr.StatusCode >= 500:
+		return FailureServerError
+	case strings.Contains(body, "duplicate"):
+		return FailureDuplicateContent
+	case apiErr.StatusCode == 404,
+		strings.Contains(body, "deleted"),
+		strings.Contains(body, "not visible"),
+		strings.Contains(body, "does not exist"),
+		strings.Contains(body, "could not find"):
+		return FailureTweetDeleted
+	case strings.Contains(body, "oauth1-permissions"),
+		strings.Contains(body, "client-forbidden"),
+		strings.Contains(body, "not permitted to perform this action"):
+		return FailureAuth
+	case apiErr.StatusCode == 403:
+		// 作者限制了回复范围（仅关注者/被提及者可回复）等
+		return FailureReplyRestricted
+	}
+	return FailureUnknown
+}
@@ -0,0 +1,55 @@
+package twitter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+)
+
+func TestClassifyError(t *testing.T) {
+	apiErr := func(status int, body string) error {
+		return &Error{StatusCode: status, Body: body}
+	}
+
+	tests := []struct {
+		name      string
+		err       error
+		want      FailureReason
+		transient bool
+	}{
+		{"nil", nil, "", false},
+		{"rate limited", apiErr(429, `{"title":"Too Many Requests"}`), FailureRateLimited, true},
+		{"rate limited with duplicate in body", apiErr(429, "duplicate"), FailureRateLimited, true},
+		{"internal server error", apiErr(500, ""), FailureServerError, true},
+		{"service unavailable", apiErr(503, `{"title":"Service Unavailable"}`), FailureServerError, true},
+		{"wrapped server error", fmt.Errorf("reply: %w", apiErr(502, "")), FailureServerError, true},
+		{"403 duplicate", apiErr(403, `{"detail":"You are not allowed to create a Tweet with duplicate content."}`), FailureDuplicateContent, false},
+		{"403 duplicate case folded", apiErr(403, "DUPLICATE content"), FailureDuplicateContent, false},
+		{"403 reply restricted", apiErr(403, `{"detail":"Reply to this conversation is not allowed because you have not been mentioned or otherwise engaged by the author"}`), FailureReplyRestricted, false},
+		{"403 tweet deleted", apiErr(403, `{"detail":"You attempted to reply to a Tweet that is deleted or not visible to you."}`), FailureTweetDeleted, false},
+		{"403 oauth permissions", apiErr(403, `{"type":"https://api.twitter.com/2/problems/oauth1-permissions"}`), FailureAuth, false},
+		{"403 client forbidden", apiErr(403, `{"reason":"client-forbidden"}`), FailureAuth, false},
+		{"403 not permitted", apiErr(403, `{"detail":"You are not permitted to perform this action."}`), FailureAuth, false},
+		{"401 unauthorized", apiErr(401, "Unauthorized"), FailureAuth, false},
+		{"404 not found", apiErr(404, ""), FailureTweetDeleted, false},
+		{"400 tweet does not exist", apiErr(400, `{"detail":"Could not find tweet with in_reply_to_tweet_id"}`), FailureTweetDeleted, false},
+		{"400 other", apiErr(400, `{"title":"Invalid Request"}`), FailureUnknown, false},
+		{"409 other", apiErr(409, ""), FailureUnknown, false},
+		{"network error", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, FailureNetwork, true},
+		{"deadline exceeded", fmt.Errorf("post: %w", context.DeadlineExceeded), FailureNetwork, true},
+		{"other error", errors.New("boom"), FailureUnknown, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ClassifyError(tt.err)
+			if got != tt.want {
+				t.Errorf("ClassifyError(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+			if got.IsTransient() != tt.transient {
+				t.Errorf("%q.IsTransient() = %v, want %v", got, got.IsTransient(), tt.transient)
+			}
+		})
+	}
+}
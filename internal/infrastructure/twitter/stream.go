@@ -0,0 +1,257 @@
+package twitter
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/zhoubofsy/x-bot/internal/config"
+)
+
+// StreamClient v2 filtered stream 客户端（使用 App-only Bearer Token 认证）
+type StreamClient interface {
+	// GetRules 获取当前生效的流规则
+	GetRules(ctx context.Context) ([]StreamRule, error)
+
+	// AddRules 添加流规则，返回创建成功的规则
+	AddRules(ctx context.Context, rules []StreamRule) ([]StreamRule, error)
+
+	// DeleteRules 按ID删除流规则
+	DeleteRules(ctx context.Context, ids []string) error
+
+	// Connect 连接 filtered stream 并阻塞读取，连接建立后调用 onConnected，每条匹配的推文调用一次 onTweet
+	// 超过 heartbeatTimeout 未收到任何数据（包括心跳）时断开并返回 ErrStreamStalled
+	Connect(ctx context.Context, heartbeatTimeout time.Duration, onConnected func(), onTweet func(StreamTweet)) error
+}
+
+// ErrStreamStalled 流在心跳超时时间内没有任何数据
+var ErrStreamStalled = fmt.Errorf("twitter stream stalled: no data or heartbeat received")
+
+// StreamRule 流过滤规则
+type StreamRule struct {
+	ID    string `json:"id,omitempty"`
+	Value string `json:"value"`
+	Tag   string `json:"tag,omitempty"`
+}
+
+// StreamTweet 流中推送的推文及其命中的规则
+type StreamTweet struct {
+	Tweet        Tweet
+	MatchingTags []string
+}
+
+type streamRulesResponse struct {
+	Data   []StreamRule `json:"data"`
+	Errors []APIError   `json:"errors,omitempty"`
+}
+
+type streamMessage struct {
+	Data          Tweet     `json:"data"`
+	Includes      *Includes `json:"includes,omitempty"`
+	MatchingRules []struct {
+		ID  string `json:"id"`
+		Tag string `json:"tag"`
+	} `json:"matching_rules"`
+	Errors []APIError `json:"errors,omitempty"`
+}
+
+type streamClient struct {
+	httpClient *http.Client
+	apiClient  *http.Client // 规则管理等普通请求，使用配置的请求超时
+	cfg        *config.TwitterConfig
+	baseURL    string
+}
+
+// NewStreamClient 创建 filtered stream 客户端，endpoint 为空时使用官方 API 地址（可指向本地桩服务用于测试）
+func NewStreamClient(cfg *config.TwitterConfig, endpoint string) StreamClient {
+	if endpoint == "" {
+		endpoint = baseURL
+	}
+	return &streamClient{
+		// 长连接不设置整体超时，由心跳超时控制
+		httpClient: &http.Client{},
+		apiClient:  &http.Client{Timeout: cfg.Timeout},
+		cfg:        cfg,
+		baseURL:    strings.TrimRight(endpoint, "/"),
+	}
+}
+
+func (c *streamClient) GetRules(ctx context.Context) ([]StreamRule, error) {
+	var result streamRulesResponse
+	if err := c.doJSON(ctx, "GET", c.baseURL+"/tweets/search/stream/rules", nil, &result); err != nil {
+		return nil, err
+	}
+	return result.Data, nil
+}
+
+func (c *streamClient) AddRules(ctx context.Context, rules []StreamRule) ([]StreamRule, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	add := make([]StreamRule, 0, len(rules))
+	for _, r := range rules {
+		add = append(add, StreamRule{Value: r.Value, Tag: r.Tag})
+	}
+
+	var result streamRulesResponse
+	payload := map[string]interface{}{"add": add}
+	if err := c.doJSON(ctx, "POST", c.baseURL+"/tweets/search/stream/rules", payload, &result); err != nil {
+		return nil, err
+	}
+	if len(result.Errors) > 0 && len(result.Data) == 0 {
+		return nil, fmt.Errorf("add stream rules failed: %s", result.Errors[0].Title+": "+result.Errors[0].Detail)
+	}
+	return result.Data, nil
+}
+
+func (c *streamClient) DeleteRules(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	payload := map[string]interface{}{
+		"delete": map[string][]string{"ids": ids},
+	}
+	return c.doJSON(ctx, "POST", c.baseURL+"/tweets/search/stream/rules", payload, nil)
+}
+
+func (c *streamClient) Connect(ctx context.Context, heartbeatTimeout time.Duration, onConnected func(), onTweet func(StreamTweet)) error {
+	params := url.Values{}
+	params.Set("tweet.fields", tweetFields)
+	params.Set("expansions", "author_id")
+	params.Set("user.fields", userFields)
+
+	// 每个连接使用独立的 context，返回时取消并关闭响应体，确保读取 goroutine 退出
+	connCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(connCtx, "GET", c.baseURL+"/tweets/search/stream?"+params.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.cfg.BearerToken)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return handleError(resp)
+	}
+	onConnected()
+
+	// 读取在单独的 goroutine 中进行，主循环负责心跳超时检测
+	lines := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		reader := bufio.NewReader(resp.Body)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(line) > 0 {
+				select {
+				case lines <- line:
+				case <-connCtx.Done():
+					return
+				}
+			}
+			if err != nil {
+				readErr <- err
+				return
+			}
+		}
+	}()
+
+	timer := time.NewTimer(heartbeatTimeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-readErr:
+			if err == io.EOF {
+				return fmt.Errorf("twitter stream closed by server")
+			}
+			return err
+		case <-timer.C:
+			return ErrStreamStalled
+		case line := <-lines:
+			if !timer.Stop() {
+				<-timer.C
+			}
+			timer.Reset(heartbeatTimeout)
+
+			line = bytes.TrimSpace(line)
+			if len(line) == 0 {
+				// 心跳（\r\n）
+				continue
+			}
+
+			var msg streamMessage
+			if err := json.Unmarshal(line, &msg); err != nil {
+				continue
+			}
+			if msg.Data.ID == "" {
+				// 连接级错误消息（如规则变更、连接数超限），交给上层重连处理
+				if len(msg.Errors) > 0 {
+					return fmt.Errorf("twitter stream error: %s: %s", msg.Errors[0].Title, msg.Errors[0].Detail)
+				}
+				continue
+			}
+
+			tweetsResp := TweetsResponse{Data: []Tweet{msg.Data}, Includes: msg.Includes}
+			tweetsResp.attachAuthors()
+
+			st := StreamTweet{Tweet: tweetsResp.Data[0]}
+			for _, rule := range msg.MatchingRules {
+				st.MatchingTags = append(st.MatchingTags, rule.Tag)
+			}
+			onTweet(st)
+		}
+	}
+}
+
+func (c *streamClient) doJSON(ctx context.Context, method, endpoint string, payload interface{}, out interface{}) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.cfg.BearerToken)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.apiClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return handleError(resp)
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,199 @@
+package twitter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zhoubofsy/x-bot/internal/config"
+)
+
+const (
+	testTweet1 = `{"data":{"id":"1","text":"Join our hackathon","author_id":"100"},"includes":{"users":[{"id":"100","username":"alice"}]},"matching_rules":[{"id":"r1","tag":"hackathon"}]}`
+	testTweet2 = `{"data":{"id":"2","text":"黑客松报名开始","author_id":"200"},"matching_rules":[{"id":"r1","tag":"hackathon"},{"id":"r2","tag":"zh"}]}`
+)
+
+// newTestStream 启动桩服务，handler 中的 write 每次写入后立即 flush，模拟分块推送
+func newTestStream(t *testing.T, handler func(w http.ResponseWriter, r *http.Request, write func(string))) (StreamClient, *httptest.Server) {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer test-token" {
+			t.Errorf("Authorization = %q", got)
+		}
+		flusher := w.(http.Flusher)
+		write := func(s string) {
+			fmt.Fprint(w, s)
+			flusher.Flush()
+		}
+		handler(w, r, write)
+	}))
+	t.Cleanup(server.Close)
+	return NewStreamClient(&config.TwitterConfig{BearerToken: "test-token", Timeout: time.Second}, server.URL), server
+}
+
+// waitClosed 等待桩服务观察到客户端断开连接
+func waitClosed(t *testing.T, closed <-chan struct{}) {
+	t.Helper()
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Connect 返回后连接没有关闭")
+	}
+}
+
+// waitGoroutines 等待 goroutine 数量回落到连接前的水平，检查读取 goroutine 没有泄漏
+func waitGoroutines(t *testing.T, baseline int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > baseline {
+		if time.Now().After(deadline) {
+			t.Fatalf("goroutines = %d, want <= %d", runtime.NumGoroutine(), baseline)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestConnectReadsChunkedStream(t *testing.T) {
+	closed := make(chan struct{})
+	client, _ := newTestStream(t, func(w http.ResponseWriter, r *http.Request, write func(string)) {
+		defer close(closed)
+		write("\r\n")
+		// 一条推文分两次写入
+		write(testTweet1[:30])
+		time.Sleep(20 * time.Millisecond)
+		write(testTweet1[30:] + "\r\n")
+		write("\r\n")
+		write(testTweet2 + "\r\n")
+		write(`{"errors":[{"title":"operational-disconnect","detail":"rules changed"}]}` + "\r\n")
+		// 客户端返回后继续推送的数据不能阻塞读取 goroutine
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(10 * time.Millisecond):
+				write(testTweet1 + "\r\n")
+			}
+		}
+	})
+
+	baseline := runtime.NumGoroutine()
+	connected := 0
+	var tweets []StreamTweet
+	err := client.Connect(context.Background(), time.Second, func() { connected++ }, func(st StreamTweet) {
+		tweets = append(tweets, st)
+	})
+
+	if err == nil || !strings.Contains(err.Error(), "operational-disconnect") {
+		t.Fatalf("Connect error = %v, want operational-disconnect", err)
+	}
+	if connected != 1 {
+		t.Errorf("onConnected called %d times, want 1", connected)
+	}
+	if len(tweets) != 2 {
+		t.Fatalf("received %d tweets, want 2", len(tweets))
+	}
+	if tweets[0].Tweet.ID != "1" || tweets[0].Tweet.Author == nil || tweets[0].Tweet.Author.Username != "alice" {
+		t.Errorf("tweet 1 = %+v, want id 1 with author alice", tweets[0].Tweet)
+	}
+	if got := strings.Join(tweets[1].MatchingTags, ","); tweets[1].Tweet.ID != "2" || got != "hackathon,zh" {
+		t.Errorf("tweet 2 = %s tags %s, want id 2 tags hackathon,zh", tweets[1].Tweet.ID, got)
+	}
+	waitClosed(t, closed)
+	waitGoroutines(t, baseline)
+}
+
+func TestConnectHeartbeatTimeout(t *testing.T) {
+	closed := make(chan struct{})
+	client, _ := newTestStream(t, func(w http.ResponseWriter, r *http.Request, write func(string)) {
+		defer close(closed)
+		write("\r\n")
+		<-r.Context().Done()
+	})
+
+	start := time.Now()
+	err := client.Connect(context.Background(), 100*time.Millisecond, func() {}, func(StreamTweet) {})
+	if !errors.Is(err, ErrStreamStalled) {
+		t.Fatalf("Connect error = %v, want ErrStreamStalled", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("heartbeat timeout took %s", elapsed)
+	}
+	waitClosed(t, closed)
+}
+
+func TestConnectReconnect(t *testing.T) {
+	var connections atomic.Int32
+	client, _ := newTestStream(t, func(w http.ResponseWriter, r *http.Request, write func(string)) {
+		n := connections.Add(1)
+		write(strings.Replace(testTweet1, `"id":"1"`, fmt.Sprintf(`"id":"%d"`, n), 1) + "\r\n")
+		// 返回即服务端关闭连接
+	})
+
+	var ids []string
+	for i := 0; i < 3; i++ {
+		err := client.Connect(context.Background(), time.Second, func() {}, func(st StreamTweet) {
+			ids = append(ids, st.Tweet.ID)
+		})
+		if err == nil || !strings.Contains(err.Error(), "closed by server") {
+			t.Fatalf("connection %d: error = %v, want closed by server", i+1, err)
+		}
+	}
+	if got := strings.Join(ids, ","); got != "1,2,3" {
+		t.Errorf("tweet ids = %s, want 1,2,3", got)
+	}
+}
+
+func TestConnectCancel(t *testing.T) {
+	closed := make(chan struct{})
+	client, _ := newTestStream(t, func(w http.ResponseWriter, r *http.Request, write func(string)) {
+		defer close(closed)
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(10 * time.Millisecond):
+				write("\r\n")
+			}
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	err := client.Connect(ctx, time.Second, func() {}, func(StreamTweet) {})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Connect error = %v, want context.Canceled", err)
+	}
+	waitClosed(t, closed)
+}
+
+func TestConnectRateLimited(t *testing.T) {
+	reset := time.Now().Add(time.Minute).Unix()
+	client, _ := newTestStream(t, func(w http.ResponseWriter, r *http.Request, write func(string)) {
+		w.Header().Set("x-rate-limit-reset", fmt.Sprint(reset))
+		w.WriteHeader(http.StatusTooManyRequests)
+		write(`{"title":"ConnectionException"}`)
+	})
+
+	connected := false
+	err := client.Connect(context.Background(), time.Second, func() { connected = true }, func(StreamTweet) {})
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("Connect error = %v, want 429 *Error", err)
+	}
+	if apiErr.RateLimitReset.Unix() != reset {
+		t.Errorf("RateLimitReset = %v, want %d", apiErr.RateLimitReset, reset)
+	}
+	if ClassifyError(err) != FailureRateLimited {
+		t.Errorf("ClassifyError = %s, want %s", ClassifyError(err), FailureRateLimited)
+	}
+	if connected {
+		t.Error("onConnected called for a failed connection")
+	}
+}
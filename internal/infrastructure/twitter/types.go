@@ -1,21 +1,104 @@
 package twitter
 
-import "time"
+import (
+	"strconv"
+	"time"
+)
 
 // TwitterUser Twitter用户信息
 type TwitterUser struct {
-	ID          string `json:"id"`
-	Username    string `json:"username"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	ID            string       `json:"id"`
+	Username      string       `json:"username"`
+	Name          string       `json:"name"`
+	Description   string       `json:"description"`
+	Protected     bool         `json:"protected"`
+	Verified      bool         `json:"verified"`
+	Location      string       `json:"location,omitempty"`
+	URL           string       `json:"url,omitempty"`
+	PublicMetrics *UserMetrics `json:"public_metrics,omitempty"`
+
+	// MostRecentTweetID 最新推文ID，可通过 SnowflakeTime 换算发布时间
+	MostRecentTweetID string `json:"most_recent_tweet_id,omitempty"`
+}
+
+// UserMetrics 用户公开数据
+type UserMetrics struct {
+	FollowersCount int `json:"followers_count"`
+	FollowingCount int `json:"following_count"`
+	TweetCount     int `json:"tweet_count"`
+	ListedCount    int `json:"listed_count"`
+}
+
+// twitterEpoch Snowflake ID 的起始时间（毫秒）
+const twitterEpoch = 1288834974657
+
+// SnowflakeTime 从推文ID（Snowflake）中解析创建时间
+func SnowflakeTime(id string) (time.Time, bool) {
+	n, err := strconv.ParseInt(id, 10, 64)
+	if err != nil || n <= 0 {
+		return time.Time{}, false
+	}
+	return time.UnixMilli((n >> 22) + twitterEpoch), true
 }
 
 // Tweet 推文信息
 type Tweet struct {
-	ID        string    `json:"id"`
-	Text      string    `json:"text"`
-	AuthorID  string    `json:"author_id"`
-	CreatedAt time.Time `json:"created_at"`
+	ID               string            `json:"id"`
+	Text             string            `json:"text"`
+	AuthorID         string            `json:"author_id"`
+	CreatedAt        time.Time         `json:"created_at"`
+	Lang             string            `json:"lang,omitempty"`
+	ConversationID   string            `json:"conversation_id,omitempty"`
+	ReferencedTweets []ReferencedTweet `json:"referenced_tweets,omitempty"`
+	PublicMetrics    *TweetMetrics     `json:"public_metrics,omitempty"`
+	ReplySettings    string            `json:"reply_settings,omitempty"`
+
+	// Author 通过 expansions=author_id 展开的作者信息
+	Author *TwitterUser `json:"-"`
+}
+
+// 引用推文类型
+const (
+	ReferencedTypeRetweeted = "retweeted"
+	ReferencedTypeQuoted    = "quoted"
+	ReferencedTypeRepliedTo = "replied_to"
+)
+
+// ReferencedTweet 推文引用关系（转推、引用、回复）
+type ReferencedTweet struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// TweetMetrics 推文互动数据
+type TweetMetrics struct {
+	RetweetCount int `json:"retweet_count"`
+	ReplyCount   int `json:"reply_count"`
+	LikeCount    int `json:"like_count"`
+	QuoteCount   int `json:"quote_count"`
+}
+
+// Engagement 互动总数
+func (m *TweetMetrics) Engagement() int {
+	if m == nil {
+		return 0
+	}
+	return m.RetweetCount + m.ReplyCount + m.LikeCount + m.QuoteCount
+}
+
+// HasReference 判断推文是否包含指定类型的引用
+func (t *Tweet) HasReference(refType string) bool {
+	return t.ReferencedID(refType) != ""
+}
+
+// ReferencedID 返回指定类型引用的推文ID，没有时返回空
+func (t *Tweet) ReferencedID(refType string) string {
+	for _, ref := range t.ReferencedTweets {
+		if ref.Type == refType {
+			return ref.ID
+		}
+	}
+	return ""
 }
 
 // APIResponse Twitter API 响应
@@ -34,12 +117,15 @@ type Meta struct {
 	ResultCount   int    `json:"result_count"`
 	NextToken     string `json:"next_token,omitempty"`
 	PreviousToken string `json:"previous_token,omitempty"`
+	NewestID      string `json:"newest_id,omitempty"`
+	OldestID      string `json:"oldest_id,omitempty"`
 }
 
 type APIError struct {
 	Title  string `json:"title"`
 	Detail string `json:"detail"`
 	Type   string `json:"type"`
+	Value  string `json:"value,omitempty"` // 出错的参数值，如查询不到的用户名
 }
 
 // CreateTweetRequest 创建推文请求
@@ -60,15 +146,41 @@ type CreateTweetResponse struct {
 	} `json:"data"`
 }
 
-// FollowingResponse 关注列表响应
+// FollowingResponse 关注列表响应（列表成员接口返回相同结构）
 type FollowingResponse struct {
 	Data []TwitterUser `json:"data"`
 	Meta *Meta         `json:"meta,omitempty"`
 }
 
+// UsersLookupResponse 按用户名查询用户响应，查询不到的用户名在 errors 中返回
+type UsersLookupResponse struct {
+	Data   []TwitterUser `json:"data"`
+	Errors []APIError    `json:"errors,omitempty"`
+}
+
 // TweetsResponse 推文列表响应
 type TweetsResponse struct {
-	Data []Tweet `json:"data"`
-	Meta *Meta   `json:"meta,omitempty"`
+	Data     []Tweet   `json:"data"`
+	Includes *Includes `json:"includes,omitempty"`
+	Meta     *Meta     `json:"meta,omitempty"`
 }
 
+// attachAuthors 将 includes 中的用户信息关联到推文
+func (r *TweetsResponse) attachAuthors() {
+	if r.Includes == nil {
+		return
+	}
+	users := make(map[string]*TwitterUser, len(r.Includes.Users))
+	for i := range r.Includes.Users {
+		users[r.Includes.Users[i].ID] = &r.Includes.Users[i]
+	}
+	for i := range r.Data {
+		r.Data[i].Author = users[r.Data[i].AuthorID]
+	}
+}
+
+// SearchResult 推文搜索结果
+type SearchResult struct {
+	Tweets   []Tweet
+	NewestID string // 本次结果中最新的推文ID，作为下次搜索的 since_id
+}
@@ -1,34 +1,54 @@
 package handler
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/zhoubofsy/x-bot/internal/application/service"
 	"github.com/zhoubofsy/x-bot/internal/domain/entity"
 	"github.com/zhoubofsy/x-bot/internal/domain/repository"
+	"github.com/zhoubofsy/x-bot/pkg/tweettext"
 )
 
 type AdCopyHandler struct {
 	adCopyRepo repository.AdCopyRepository
+	adMetrics  service.AdMetricsService
 }
 
-func NewAdCopyHandler(adCopyRepo repository.AdCopyRepository) *AdCopyHandler {
-	return &AdCopyHandler{adCopyRepo: adCopyRepo}
+func NewAdCopyHandler(adCopyRepo repository.AdCopyRepository, adMetrics service.AdMetricsService) *AdCopyHandler {
+	return &AdCopyHandler{
+		adCopyRepo: adCopyRepo,
+		adMetrics:  adMetrics,
+	}
 }
 
-// List 获取所有广告文案
+// List 获取所有广告文案，deleted=true 时只返回已删除的文案
 // @Summary 获取广告文案列表
 // @Tags ad-copies
 // @Produce json
+// @Param deleted query bool false "只返回已删除的文案"
 // @Success 200 {array} entity.AdCopy
 // @Router /api/v1/ad-copies [get]
 func (h *AdCopyHandler) List(c *gin.Context) {
-	adCopies, err := h.adCopyRepo.GetAll(c.Request.Context())
+	var adCopies []*entity.AdCopy
+	var err error
+	if deleted, _ := strconv.ParseBool(c.Query("deleted")); deleted {
+		adCopies, err = h.adCopyRepo.GetDeleted(c.Request.Context())
+	} else {
+		adCopies, err = h.adCopyRepo.GetAll(c.Request.Context())
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	for _, adCopy := range adCopies {
+		fillWeightedLength(adCopy)
+	}
+	h.fillStatus(c, adCopies...)
 	c.JSON(http.StatusOK, adCopies)
 }
 
@@ -51,6 +71,8 @@ func (h *AdCopyHandler) Get(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "ad copy not found"})
 		return
 	}
+	fillWeightedLength(adCopy)
+	h.fillStatus(c, adCopy)
 	c.JSON(http.StatusOK, adCopy)
 }
 
@@ -69,23 +91,65 @@ func (h *AdCopyHandler) Create(c *gin.Context) {
 		return
 	}
 
+	variants, err := service.BuildAdCopyVariants(input.Variants, input.LinkURL)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := service.ValidateAdCopyContent(input.Content, input.LinkURL); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	adCopy := &entity.AdCopy{
-		Name:     input.Name,
-		Content:  input.Content,
-		Category: input.Category,
-		Priority: input.Priority,
-		IsActive: true,
+		Name:         input.Name,
+		Content:      input.Content,
+		LinkURL:      input.LinkURL,
+		Language:     service.NormalizeAdCopyLanguage(input.Language),
+		Category:     input.Category,
+		Priority:     input.Priority,
+		IsActive:     true,
+		Variants:     variants,
+		Emojis:       input.Emojis,
+		Hashtags:     input.Hashtags,
+		CampaignID:   campaignIDOf(input.CampaignID),
+		StartsAt:     input.StartsAt,
+		EndsAt:       input.EndsAt,
+		MaxDailyUses: input.MaxDailyUses,
+		MaxTotalUses: input.MaxTotalUses,
+		Weekdays:     input.Weekdays,
+		StartHour:    input.StartHour,
+		EndHour:      input.EndHour,
+		Timezone:     input.Timezone,
+	}
+	if err := service.ValidateAdCopySchedule(adCopy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := service.ValidateAdCopyVariation(adCopy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
 	if adCopy.Category == "" {
 		adCopy.Category = "hackathon"
 	}
 
-	if err := h.adCopyRepo.Save(c.Request.Context(), adCopy); err != nil {
+	// 文案和初始版本在同一事务中保存，避免留下没有版本的文案
+	err = h.adCopyRepo.Transaction(c.Request.Context(), func(repo repository.AdCopyRepository) error {
+		if err := repo.Save(c.Request.Context(), adCopy); err != nil {
+			return err
+		}
+		_, err := repo.CreateRevision(c.Request.Context(), adCopy, "created")
+		return err
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	fillWeightedLength(adCopy)
+	h.fillStatus(c, adCopy)
 	c.JSON(http.StatusCreated, adCopy)
 }
 
@@ -117,12 +181,32 @@ func (h *AdCopyHandler) Update(c *gin.Context) {
 		return
 	}
 
+	before := entity.NewAdCopyRevision(adCopy, "")
+
 	if input.Name != nil {
 		adCopy.Name = *input.Name
 	}
 	if input.Content != nil {
 		adCopy.Content = *input.Content
 	}
+	if input.LinkURL != nil {
+		adCopy.LinkURL = *input.LinkURL
+	}
+	if input.Content != nil || input.LinkURL != nil {
+		if err := service.ValidateAdCopyContent(adCopy.Content, adCopy.LinkURL); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if input.Language != nil {
+		adCopy.Language = service.NormalizeAdCopyLanguage(*input.Language)
+	}
+	if input.Emojis != nil {
+		adCopy.Emojis = *input.Emojis
+	}
+	if input.Hashtags != nil {
+		adCopy.Hashtags = *input.Hashtags
+	}
 	if input.Category != nil {
 		adCopy.Category = *input.Category
 	}
@@ -132,16 +216,205 @@ func (h *AdCopyHandler) Update(c *gin.Context) {
 	if input.IsActive != nil {
 		adCopy.IsActive = *input.IsActive
 	}
+	if input.CampaignID != nil {
+		adCopy.CampaignID = campaignIDOf(input.CampaignID)
+	}
+	if input.StartsAt != nil {
+		adCopy.StartsAt = input.StartsAt
+	}
+	if input.EndsAt != nil {
+		adCopy.EndsAt = input.EndsAt
+	}
+	if input.MaxDailyUses != nil {
+		adCopy.MaxDailyUses = *input.MaxDailyUses
+	}
+	if input.MaxTotalUses != nil {
+		adCopy.MaxTotalUses = *input.MaxTotalUses
+	}
+	if input.Weekdays != nil {
+		adCopy.Weekdays = *input.Weekdays
+	}
+	if input.StartHour != nil {
+		adCopy.StartHour = input.StartHour
+	}
+	if input.EndHour != nil {
+		adCopy.EndHour = input.EndHour
+	}
+	if input.ClearSchedule {
+		adCopy.StartsAt = nil
+		adCopy.EndsAt = nil
+		adCopy.Weekdays = nil
+		adCopy.StartHour = nil
+		adCopy.EndHour = nil
+	}
+	if input.Timezone != nil {
+		adCopy.Timezone = *input.Timezone
+	}
+	if err := service.ValidateAdCopySchedule(adCopy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var variants []entity.AdCopyVariant
+	if input.Variants != nil {
+		if variants, err = service.BuildAdCopyVariants(*input.Variants, adCopy.LinkURL); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	} else if input.LinkURL != nil {
+		// 推广链接会渲染到变体中，只修改链接时也要重新检查已有变体的长度
+		if err := service.ValidateAdCopyVariants(adCopy.Variants, adCopy.LinkURL); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	updated := *adCopy
+	if input.Variants != nil {
+		updated.Variants = variants
+	}
+	if err := service.ValidateAdCopyVariation(&updated); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 文案、语言变体和新版本在同一事务中更新，避免只更新一部分
+	err = h.adCopyRepo.Transaction(c.Request.Context(), func(repo repository.AdCopyRepository) error {
+		if err := repo.Update(c.Request.Context(), adCopy); err != nil {
+			return err
+		}
+		if input.Variants != nil {
+			if err := repo.ReplaceVariants(c.Request.Context(), adCopy.ID, variants); err != nil {
+				return err
+			}
+		}
+		// 回复内容变化时生成新版本，之后的回复日志引用新版本
+		if before.SameContent(entity.NewAdCopyRevision(&updated, "")) {
+			return nil
+		}
+		revision, err := repo.CreateRevision(c.Request.Context(), &updated, "updated")
+		if err == nil {
+			adCopy.Revision = revision.Revision
+		}
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if input.Variants != nil {
+		adCopy.Variants = variants
+	}
+
+	fillWeightedLength(adCopy)
+	h.fillStatus(c, adCopy)
+	c.JSON(http.StatusOK, adCopy)
+}
 
-	if err := h.adCopyRepo.Update(c.Request.Context(), adCopy); err != nil {
+// Revisions 获取文案的历史版本
+// @Summary 获取广告文案历史版本
+// @Tags ad-copies
+// @Produce json
+// @Param id path int true "广告文案ID"
+// @Success 200 {array} entity.AdCopyRevision
+// @Router /api/v1/ad-copies/{id}/revisions [get]
+func (h *AdCopyHandler) Revisions(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	adCopy, err := h.adCopyRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "ad copy not found"})
+		return
+	}
+	revisions, err := h.adCopyRepo.GetRevisions(c.Request.Context(), adCopy.ID)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	c.JSON(http.StatusOK, gin.H{
+		"ad_copy_id": adCopy.ID,
+		"current":    adCopy.Revision,
+		"revisions":  revisions,
+	})
+}
+
+// Rollback 将文案内容恢复为指定版本，并生成一个新版本（历史版本不会被修改）
+// @Summary 回滚广告文案到指定版本
+// @Tags ad-copies
+// @Produce json
+// @Param id path int true "广告文案ID"
+// @Param revision path int true "版本号"
+// @Success 200 {object} entity.AdCopy
+// @Router /api/v1/ad-copies/{id}/revisions/{revision}/rollback [post]
+func (h *AdCopyHandler) Rollback(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	number, err := strconv.Atoi(c.Param("revision"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid revision"})
+		return
+	}
+
+	adCopy, err := h.adCopyRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "ad copy not found"})
+		return
+	}
+	revision, err := h.adCopyRepo.GetRevision(c.Request.Context(), adCopy.ID, number)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "revision not found"})
+		return
+	}
+	if !revision.SameContent(entity.NewAdCopyRevision(adCopy, "")) {
+		adCopy.Content = revision.Content
+		adCopy.LinkURL = revision.LinkURL
+		adCopy.Language = revision.Language
+		adCopy.Variants = revision.VariantEntities()
+		adCopy.Emojis = revision.Emojis
+		adCopy.Hashtags = revision.Hashtags
+
+		// 历史版本可能不满足当前的模板和长度规则，恢复前重新校验
+		if err := service.ValidateAdCopyContent(adCopy.Content, adCopy.LinkURL); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := service.ValidateAdCopyVariants(adCopy.Variants, adCopy.LinkURL); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := service.ValidateAdCopyVariation(adCopy); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		err = h.adCopyRepo.Transaction(c.Request.Context(), func(repo repository.AdCopyRepository) error {
+			if err := repo.Update(c.Request.Context(), adCopy); err != nil {
+				return err
+			}
+			if err := repo.ReplaceVariants(c.Request.Context(), adCopy.ID, adCopy.Variants); err != nil {
+				return err
+			}
+			_, err := repo.CreateRevision(c.Request.Context(), adCopy, fmt.Sprintf("rollback to #%d", revision.Revision))
+			return err
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
 
+	fillWeightedLength(adCopy)
+	h.fillStatus(c, adCopy)
 	c.JSON(http.StatusOK, adCopy)
 }
 
-// Delete 删除广告文案
+// Delete 删除广告文案（软删除），引用该文案的回复日志保持完整，可通过 restore 恢复
 // @Summary 删除广告文案
 // @Tags ad-copies
 // @Param id path int true "广告文案ID"
@@ -162,3 +435,188 @@ func (h *AdCopyHandler) Delete(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+// Restore 恢复已删除的广告文案
+// @Summary 恢复广告文案
+// @Tags ad-copies
+// @Produce json
+// @Param id path int true "广告文案ID"
+// @Success 200 {object} entity.AdCopy
+// @Router /api/v1/ad-copies/{id}/restore [post]
+func (h *AdCopyHandler) Restore(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	if err := h.adCopyRepo.Restore(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "deleted ad copy not found"})
+		return
+	}
+
+	adCopy, err := h.adCopyRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	fillWeightedLength(adCopy)
+	h.fillStatus(c, adCopy)
+	c.JSON(http.StatusOK, adCopy)
+}
+
+// Preview 使用示例推文渲染文案模板，可通过请求字段覆盖示例数据
+// @Summary 预览广告文案模板
+// @Tags ad-copies
+// @Accept json
+// @Produce json
+// @Param input body entity.PreviewAdCopyInput true "文案模板与示例数据"
+// @Router /api/v1/ad-copies/preview [post]
+func (h *AdCopyHandler) Preview(c *gin.Context) {
+	var input entity.PreviewAdCopyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	data := service.AdCopySampleData(input.LinkURL)
+	if input.TweetText != "" {
+		data.Tweet.Text = input.TweetText
+	}
+	if input.AuthorUsername != "" {
+		data.Author.Username = strings.TrimPrefix(input.AuthorUsername, "@")
+	}
+	if input.EventName != "" {
+		data.Event.Name = input.EventName
+	}
+	if input.Deadline != "" {
+		data.Deadline = input.Deadline
+	}
+
+	content, variations, err := service.RenderAdCopySample(input.Content, input.Emojis, input.Hashtags, data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"content":    content,
+		"length":     tweettext.Parse(content),
+		"variations": variations,
+		"sample":     data,
+	})
+}
+
+// PreviewSaved 使用示例推文渲染已保存的文案及其各语言变体
+// @Summary 预览已保存的广告文案
+// @Tags ad-copies
+// @Produce json
+// @Param id path int true "广告文案ID"
+// @Router /api/v1/ad-copies/{id}/preview [get]
+func (h *AdCopyHandler) PreviewSaved(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	adCopy, err := h.adCopyRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "ad copy not found"})
+		return
+	}
+
+	data := service.AdCopySampleData(adCopy.LinkURL)
+
+	rendered := make(map[string]string, len(adCopy.Variants)+1)
+	lengths := make(map[string]tweettext.Result, len(adCopy.Variants)+1)
+	variations := make(map[string]int, len(adCopy.Variants)+1)
+	defaultLang := adCopy.Language
+	if defaultLang == "" {
+		defaultLang = "default"
+	}
+	contents := map[string]string{defaultLang: adCopy.Content}
+	for _, v := range adCopy.Variants {
+		contents[v.Language] = v.Content
+	}
+	for lang, content := range contents {
+		text, count, err := service.RenderAdCopySample(content, adCopy.Emojis, adCopy.Hashtags, data)
+		if err != nil {
+			rendered[lang] = "ERROR: " + err.Error()
+			continue
+		}
+		rendered[lang] = text
+		lengths[lang] = tweettext.Parse(text)
+		variations[lang] = count
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"ad_copy_id": adCopy.ID,
+		"rendered":   rendered,
+		"lengths":    lengths,
+		"variations": variations,
+		"sample":     data,
+	})
+}
+
+// Performance 获取各广告文案的互动效果
+// @Summary 获取广告文案效果
+// @Tags ad-copies
+// @Produce json
+// @Success 200 {array} dto.AdCopyPerformance
+// @Router /api/v1/ad-copies/performance [get]
+func (h *AdCopyHandler) Performance(c *gin.Context) {
+	performance, err := h.adMetrics.Performance(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, performance)
+}
+
+// CollectMetrics 立即拉取近期回复的互动数据
+// @Summary 拉取回复互动数据
+// @Tags ad-copies
+// @Produce json
+// @Success 200 {object} dto.ReplyMetricsResult
+// @Router /api/v1/ad-copies/collect-metrics [post]
+func (h *AdCopyHandler) CollectMetrics(c *gin.Context) {
+	result, err := h.adMetrics.CollectReplyMetrics(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// campaignIDOf 0 或空表示不属于任何活动
+func campaignIDOf(id *int) *int {
+	if id == nil || *id <= 0 {
+		return nil
+	}
+	return id
+}
+
+// fillStatus 计算文案今日回复次数和当前可用状态，统计失败时按今日未使用计算
+func (h *AdCopyHandler) fillStatus(c *gin.Context, adCopies ...*entity.AdCopy) {
+	now := time.Now()
+	usage, err := h.adCopyRepo.GetUsage(c.Request.Context(), adCopies, now)
+	if err != nil {
+		usage = map[int]entity.AdCopyUsage{}
+	}
+
+	for _, adCopy := range adCopies {
+		adCopy.UsedToday = usage[adCopy.ID].Today
+		adCopy.QueuedUses = usage[adCopy.ID].Queued
+		adCopy.Status = adCopy.Availability(now, usage[adCopy.ID])
+	}
+}
+
+// fillWeightedLength 计算文案及其变体最长的 spintax 变体以示例数据渲染后的加权长度，渲染失败时按原文计算
+func fillWeightedLength(adCopy *entity.AdCopy) {
+	data := service.AdCopyLongestSampleData(adCopy)
+	adCopy.WeightedLength = service.AdCopyMaxWeightedLength(adCopy.Content, data)
+	for i := range adCopy.Variants {
+		adCopy.Variants[i].WeightedLength = service.AdCopyMaxWeightedLength(adCopy.Variants[i].Content, data)
+	}
+}
+
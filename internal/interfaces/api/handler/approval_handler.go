@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zhoubofsy/x-bot/internal/application/service"
+)
+
+type ApprovalHandler struct {
+	approvalService service.ApprovalService
+}
+
+func NewApprovalHandler(approvalService service.ApprovalService) *ApprovalHandler {
+	return &ApprovalHandler{approvalService: approvalService}
+}
+
+// EditApprovalRequest 修改待审核回复请求
+type EditApprovalRequest struct {
+	Content string `json:"content" binding:"required"`
+}
+
+// ApproveRequest 批准回复请求，content 不为空时以其替换原文案
+type ApproveRequest struct {
+	Content *string `json:"content"`
+}
+
+// RejectRequest 拒绝回复请求
+type RejectRequest struct {
+	Reason string `json:"reason"`
+}
+
+// List 获取待审核回复
+// @Summary 获取待审核回复列表
+// @Tags approvals
+// @Produce json
+// @Param limit query int false "限制数量" default(50)
+// @Success 200 {array} entity.ReplyLog
+// @Router /api/v1/approvals [get]
+func (h *ApprovalHandler) List(c *gin.Context) {
+	limit := 50
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 {
+		limit = l
+	}
+
+	logs, err := h.approvalService.ListPending(c.Request.Context(), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, logs)
+}
+
+// Edit 修改待审核回复的文案
+// @Summary 修改待审核回复
+// @Tags approvals
+// @Accept json
+// @Produce json
+// @Param id path int true "回复日志ID"
+// @Param input body EditApprovalRequest true "回复内容"
+// @Success 200 {object} entity.ReplyLog
+// @Router /api/v1/approvals/{id} [put]
+func (h *ApprovalHandler) Edit(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req EditApprovalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	log, err := h.approvalService.Edit(c.Request.Context(), id, req.Content)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, log)
+}
+
+// Approve 批准回复
+// @Summary 批准回复
+// @Tags approvals
+// @Accept json
+// @Produce json
+// @Param id path int true "回复日志ID"
+// @Param input body ApproveRequest false "可选：修改后的回复内容"
+// @Success 200 {object} entity.ReplyLog
+// @Router /api/v1/approvals/{id}/approve [post]
+func (h *ApprovalHandler) Approve(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req ApproveRequest
+	if !bindOptionalJSON(c, &req) {
+		return
+	}
+
+	log, err := h.approvalService.Approve(c.Request.Context(), id, req.Content)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, log)
+}
+
+// Reject 拒绝回复
+// @Summary 拒绝回复
+// @Tags approvals
+// @Accept json
+// @Produce json
+// @Param id path int true "回复日志ID"
+// @Param input body RejectRequest false "拒绝原因"
+// @Success 200 {object} entity.ReplyLog
+// @Router /api/v1/approvals/{id}/reject [post]
+func (h *ApprovalHandler) Reject(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req RejectRequest
+	if !bindOptionalJSON(c, &req) {
+		return
+	}
+
+	log, err := h.approvalService.Reject(c.Request.Context(), id, req.Reason)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, log)
+}
+
+// bindOptionalJSON 解析可选的 JSON 请求体，请求体为空时忽略；格式错误时返回 400，避免按未修改的内容处理
+func bindOptionalJSON(c *gin.Context, req interface{}) bool {
+	if err := c.ShouldBindJSON(req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	return true
+}
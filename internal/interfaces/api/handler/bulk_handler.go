@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zhoubofsy/x-bot/internal/application/dto"
+	"github.com/zhoubofsy/x-bot/internal/application/service"
+)
+
+type BulkHandler struct {
+	bulkService service.BulkService
+}
+
+func NewBulkHandler(bulkService service.BulkService) *BulkHandler {
+	return &BulkHandler{bulkService: bulkService}
+}
+
+// ExportAdCopies 导出广告文案
+// @Summary 导出广告文案
+// @Tags ad-copies
+// @Produce text/csv,json
+// @Param format query string false "csv 或 json，默认 csv"
+// @Success 200
+// @Router /api/v1/ad-copies/export [get]
+func (h *BulkHandler) ExportAdCopies(c *gin.Context) {
+	h.export(c, "ad-copies", h.bulkService.ExportAdCopies)
+}
+
+// ImportAdCopies 按名称新增或更新广告文案
+// @Summary 导入广告文案
+// @Tags ad-copies
+// @Accept text/csv,json,multipart/form-data
+// @Produce json
+// @Param format query string false "csv 或 json，默认按 Content-Type 或文件扩展名判断"
+// @Param dry_run query bool false "只校验不写入"
+// @Success 200 {object} dto.ImportResult
+// @Router /api/v1/ad-copies/import [post]
+func (h *BulkHandler) ImportAdCopies(c *gin.Context) {
+	h.importFile(c, h.bulkService.ImportAdCopies)
+}
+
+// ExportUsers 导出监控用户
+// @Summary 导出监控用户
+// @Tags users
+// @Produce text/csv,json
+// @Param format query string false "csv 或 json，默认 csv"
+// @Success 200
+// @Router /api/v1/users/export [get]
+func (h *BulkHandler) ExportUsers(c *gin.Context) {
+	h.export(c, "users", h.bulkService.ExportUsers)
+}
+
+// ImportUsers 按 twitter_user_id 新增或更新监控用户
+// @Summary 导入监控用户
+// @Tags users
+// @Accept text/csv,json,multipart/form-data
+// @Produce json
+// @Param format query string false "csv 或 json，默认按 Content-Type 或文件扩展名判断"
+// @Param dry_run query bool false "只校验不写入"
+// @Success 200 {object} dto.ImportResult
+// @Router /api/v1/users/import [post]
+func (h *BulkHandler) ImportUsers(c *gin.Context) {
+	h.importFile(c, h.bulkService.ImportUsers)
+}
+
+func (h *BulkHandler) export(c *gin.Context, name string, export func(context.Context, string, io.Writer) error) {
+	format := strings.ToLower(c.DefaultQuery("format", service.BulkFormatCSV))
+
+	// 先写入缓冲区，导出失败时仍可返回 JSON 错误
+	var buf bytes.Buffer
+	if err := export(c.Request.Context(), format, &buf); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	contentType := "text/csv; charset=utf-8"
+	if format == service.BulkFormatJSON {
+		contentType = "application/json; charset=utf-8"
+	}
+	filename := fmt.Sprintf("%s-%s.%s", name, time.Now().Format("20060102"), format)
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
+
+func (h *BulkHandler) importFile(c *gin.Context, importer func(context.Context, string, io.Reader, bool) (*dto.ImportResult, error)) {
+	dryRun := false
+	if v := c.Query("dry_run"); v != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的 dry_run"})
+			return
+		}
+	}
+
+	format := strings.ToLower(c.Query("format"))
+	body := io.Reader(c.Request.Body)
+	// 支持表单上传（file 字段）或直接以请求体提交文件内容
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		header, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "缺少上传文件 file"})
+			return
+		}
+		file, err := header.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		defer file.Close()
+		body = file
+		if format == "" {
+			format = service.BulkFormatOf(header.Filename)
+		}
+	} else if format == "" && strings.Contains(c.ContentType(), "json") {
+		format = service.BulkFormatJSON
+	}
+	if format == "" {
+		format = service.BulkFormatCSV
+	}
+
+	result, err := importer(c.Request.Context(), format, body, dryRun)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
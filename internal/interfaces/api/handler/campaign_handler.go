@@ -0,0 +1,246 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zhoubofsy/x-bot/internal/application/service"
+	"github.com/zhoubofsy/x-bot/internal/domain/entity"
+	"github.com/zhoubofsy/x-bot/internal/domain/repository"
+)
+
+type CampaignHandler struct {
+	campaignRepo      repository.CampaignRepository
+	campaignTargeting service.CampaignTargeting
+}
+
+func NewCampaignHandler(campaignRepo repository.CampaignRepository, campaignTargeting service.CampaignTargeting) *CampaignHandler {
+	return &CampaignHandler{
+		campaignRepo:      campaignRepo,
+		campaignTargeting: campaignTargeting,
+	}
+}
+
+// List 获取所有推广活动
+// @Summary 获取推广活动列表
+// @Tags campaigns
+// @Produce json
+// @Success 200 {array} entity.Campaign
+// @Router /api/v1/campaigns [get]
+func (h *CampaignHandler) List(c *gin.Context) {
+	campaigns, err := h.campaignRepo.GetAll(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, campaigns)
+}
+
+// Get 获取推广活动及其广告文案
+// @Summary 获取单个推广活动
+// @Tags campaigns
+// @Produce json
+// @Param id path int true "活动ID"
+// @Success 200 {object} entity.Campaign
+// @Router /api/v1/campaigns/{id} [get]
+func (h *CampaignHandler) Get(c *gin.Context) {
+	campaign, ok := h.loadCampaign(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, campaign)
+}
+
+// Create 创建推广活动，默认为草稿状态
+// @Summary 创建推广活动
+// @Tags campaigns
+// @Accept json
+// @Produce json
+// @Param input body entity.CreateCampaignInput true "活动信息"
+// @Success 201 {object} entity.Campaign
+// @Router /api/v1/campaigns [post]
+func (h *CampaignHandler) Create(c *gin.Context) {
+	var input entity.CreateCampaignInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	campaign := &entity.Campaign{
+		Name:           input.Name,
+		Description:    input.Description,
+		Category:       input.Category,
+		Status:         entity.CampaignStatus(input.Status),
+		UserGroupIDs:   input.UserGroupIDs,
+		SearchQueryIDs: input.SearchQueryIDs,
+		MaxReplies:     input.MaxReplies,
+		MaxLLMSpend:    input.MaxLLMSpend,
+		StartsAt:       input.StartsAt,
+		EndsAt:         input.EndsAt,
+		Priority:       input.Priority,
+	}
+	if campaign.Category == "" {
+		campaign.Category = "hackathon"
+	}
+	if campaign.Status == "" {
+		campaign.Status = entity.CampaignStatusDraft
+	}
+	if err := validateCampaign(campaign); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.campaignRepo.Save(c.Request.Context(), campaign); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, campaign)
+}
+
+// Update 更新推广活动，可通过 status 启用（active）、暂停（paused）或结束（finished）
+// @Summary 更新推广活动
+// @Tags campaigns
+// @Accept json
+// @Produce json
+// @Param id path int true "活动ID"
+// @Param input body entity.UpdateCampaignInput true "更新信息"
+// @Success 200 {object} entity.Campaign
+// @Router /api/v1/campaigns/{id} [put]
+func (h *CampaignHandler) Update(c *gin.Context) {
+	campaign, ok := h.loadCampaign(c)
+	if !ok {
+		return
+	}
+
+	var input entity.UpdateCampaignInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if input.Name != nil {
+		campaign.Name = *input.Name
+	}
+	if input.Description != nil {
+		campaign.Description = *input.Description
+	}
+	if input.Category != nil {
+		campaign.Category = *input.Category
+	}
+	if input.Status != nil {
+		campaign.Status = entity.CampaignStatus(*input.Status)
+		// 手动变更状态后清除自动结束的原因
+		campaign.FinishReason = ""
+	}
+	if input.UserGroupIDs != nil {
+		campaign.UserGroupIDs = *input.UserGroupIDs
+	}
+	if input.SearchQueryIDs != nil {
+		campaign.SearchQueryIDs = *input.SearchQueryIDs
+	}
+	if input.MaxReplies != nil {
+		campaign.MaxReplies = *input.MaxReplies
+	}
+	if input.MaxLLMSpend != nil {
+		campaign.MaxLLMSpend = *input.MaxLLMSpend
+	}
+	if input.StartsAt != nil {
+		campaign.StartsAt = input.StartsAt
+	}
+	if input.EndsAt != nil {
+		campaign.EndsAt = input.EndsAt
+	}
+	if input.ClearSchedule {
+		campaign.StartsAt = nil
+		campaign.EndsAt = nil
+	}
+	if input.Priority != nil {
+		campaign.Priority = *input.Priority
+	}
+	if err := validateCampaign(campaign); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.campaignRepo.Update(c.Request.Context(), campaign); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, campaign)
+}
+
+// Delete 删除推广活动，其广告文案保留并解除关联
+// @Summary 删除推广活动
+// @Tags campaigns
+// @Param id path int true "活动ID"
+// @Success 204
+// @Router /api/v1/campaigns/{id} [delete]
+func (h *CampaignHandler) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的活动ID"})
+		return
+	}
+
+	if err := h.campaignRepo.Delete(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Stats 获取推广活动的回复、LLM 用量和剩余预算
+// @Summary 获取推广活动统计
+// @Tags campaigns
+// @Produce json
+// @Param id path int true "活动ID"
+// @Success 200 {object} dto.CampaignStats
+// @Router /api/v1/campaigns/{id}/stats [get]
+func (h *CampaignHandler) Stats(c *gin.Context) {
+	campaign, ok := h.loadCampaign(c)
+	if !ok {
+		return
+	}
+
+	stats, err := h.campaignTargeting.Stats(c.Request.Context(), campaign)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// loadCampaign 解析路径中的活动ID并加载活动，失败时已写入响应
+func (h *CampaignHandler) loadCampaign(c *gin.Context) (*entity.Campaign, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的活动ID"})
+		return nil, false
+	}
+
+	campaign, err := h.campaignRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "活动不存在"})
+		return nil, false
+	}
+	return campaign, true
+}
+
+// validateCampaign 校验活动状态、分类和投放时间
+func validateCampaign(campaign *entity.Campaign) error {
+	if !entity.ValidCampaignStatus(campaign.Status) {
+		return fmt.Errorf("无效的活动状态: %s", campaign.Status)
+	}
+	if !service.DetectableCategory(campaign.Category) {
+		return fmt.Errorf("不支持的推文分类: %s", campaign.Category)
+	}
+	if campaign.StartsAt != nil && campaign.EndsAt != nil && !campaign.EndsAt.After(*campaign.StartsAt) {
+		return fmt.Errorf("ends_at must be after starts_at")
+	}
+	return nil
+}
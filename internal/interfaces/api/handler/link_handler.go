@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zhoubofsy/x-bot/internal/application/service"
+	"github.com/zhoubofsy/x-bot/internal/domain/repository"
+)
+
+type LinkHandler struct {
+	linkTracker service.LinkTracker
+	linkRepo    repository.TrackedLinkRepository
+}
+
+func NewLinkHandler(linkTracker service.LinkTracker, linkRepo repository.TrackedLinkRepository) *LinkHandler {
+	return &LinkHandler{
+		linkTracker: linkTracker,
+		linkRepo:    linkRepo,
+	}
+}
+
+// Redirect 记录点击并跳转到附加了 UTM 参数的目标地址（公开访问，无需 API Key）
+// 爬虫和同一访客在去重窗口内的重复点击不计数
+// @Summary 短链接跳转
+// @Tags links
+// @Param code path string true "短码"
+// @Success 302
+// @Router /r/{code} [get]
+func (h *LinkHandler) Redirect(c *gin.Context) {
+	target, err := h.linkTracker.Click(c.Request.Context(), c.Param("code"), c.ClientIP(), c.Request.Referer(), c.Request.UserAgent())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Redirect(http.StatusFound, target)
+}
+
+// List 获取短链接及点击次数
+// @Summary 获取短链接列表
+// @Tags links
+// @Produce json
+// @Param tweet_id query string false "被回复的推文ID"
+// @Param reply_log_id query int false "回复日志ID"
+// @Param ad_copy_id query int false "广告文案ID"
+// @Param limit query int false "返回数量，默认 50"
+// @Success 200 {array} entity.TrackedLink
+// @Router /api/v1/links [get]
+func (h *LinkHandler) List(c *gin.Context) {
+	filter := repository.TrackedLinkFilter{
+		TweetID: c.Query("tweet_id"),
+		Limit:   50,
+	}
+	if v := c.Query("reply_log_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的回复日志ID"})
+			return
+		}
+		filter.ReplyLogID = id
+	}
+	if v := c.Query("ad_copy_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的文案ID"})
+			return
+		}
+		filter.AdCopyID = id
+	}
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的 limit"})
+			return
+		}
+		filter.Limit = limit
+	}
+
+	links, err := h.linkRepo.List(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, links)
+}
@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	apperrors "github.com/zhoubofsy/x-bot/pkg/errors"
+)
+
+// respondError 将业务错误映射为 HTTP 状态码
+func respondError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case apperrors.Is(err, apperrors.ErrNotFound):
+		status = http.StatusNotFound
+	case apperrors.Is(err, apperrors.ErrInvalidInput):
+		status = http.StatusBadRequest
+	case apperrors.Is(err, apperrors.ErrAlreadyExists):
+		status = http.StatusConflict
+	}
+
+	message := err.Error()
+	var appErr *apperrors.AppError
+	if errors.As(err, &appErr) {
+		message = appErr.Message
+	}
+
+	c.JSON(status, gin.H{"error": message})
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zhoubofsy/x-bot/internal/domain/entity"
+	"github.com/zhoubofsy/x-bot/internal/domain/repository"
+)
+
+type SearchQueryHandler struct {
+	searchQueryRepo repository.SearchQueryRepository
+}
+
+func NewSearchQueryHandler(searchQueryRepo repository.SearchQueryRepository) *SearchQueryHandler {
+	return &SearchQueryHandler{searchQueryRepo: searchQueryRepo}
+}
+
+// List 获取所有搜索条件
+// @Summary 获取搜索条件列表
+// @Tags search-queries
+// @Produce json
+// @Success 200 {array} entity.SearchQuery
+// @Router /api/v1/search-queries [get]
+func (h *SearchQueryHandler) List(c *gin.Context) {
+	queries, err := h.searchQueryRepo.GetAll(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, queries)
+}
+
+// Create 创建搜索条件
+// @Summary 创建搜索条件
+// @Tags search-queries
+// @Accept json
+// @Produce json
+// @Param input body entity.CreateSearchQueryInput true "搜索条件"
+// @Success 201 {object} entity.SearchQuery
+// @Router /api/v1/search-queries [post]
+func (h *SearchQueryHandler) Create(c *gin.Context) {
+	var input entity.CreateSearchQueryInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := &entity.SearchQuery{
+		Name:       input.Name,
+		Query:      input.Query,
+		MaxResults: input.MaxResults,
+		IsActive:   true,
+	}
+	if query.MaxResults == 0 {
+		query.MaxResults = 10
+	}
+
+	if err := h.searchQueryRepo.Save(c.Request.Context(), query); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, query)
+}
+
+// Update 更新搜索条件
+// @Summary 更新搜索条件
+// @Tags search-queries
+// @Accept json
+// @Produce json
+// @Param id path int true "搜索条件ID"
+// @Param input body entity.UpdateSearchQueryInput true "更新信息"
+// @Success 200 {object} entity.SearchQuery
+// @Router /api/v1/search-queries/{id} [put]
+func (h *SearchQueryHandler) Update(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	query, err := h.searchQueryRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "search query not found"})
+		return
+	}
+
+	var input entity.UpdateSearchQueryInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if input.Name != nil {
+		query.Name = *input.Name
+	}
+	if input.Query != nil && *input.Query != query.Query {
+		// 搜索条件变化后旧游标不再适用
+		query.Query = *input.Query
+		query.SinceID = ""
+	}
+	if input.MaxResults != nil {
+		query.MaxResults = *input.MaxResults
+	}
+	if input.IsActive != nil {
+		query.IsActive = *input.IsActive
+	}
+	if input.ResetSince {
+		query.SinceID = ""
+	}
+
+	if err := h.searchQueryRepo.Update(c.Request.Context(), query); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, query)
+}
+
+// Delete 删除搜索条件
+// @Summary 删除搜索条件
+// @Tags search-queries
+// @Param id path int true "搜索条件ID"
+// @Success 204
+// @Router /api/v1/search-queries/{id} [delete]
+func (h *SearchQueryHandler) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	if err := h.searchQueryRepo.Delete(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zhoubofsy/x-bot/internal/application/service"
+)
+
+type StreamHandler struct {
+	streamService service.StreamService
+}
+
+func NewStreamHandler(streamService service.StreamService) *StreamHandler {
+	return &StreamHandler{streamService: streamService}
+}
+
+// AddStreamRuleRequest 添加流规则请求
+type AddStreamRuleRequest struct {
+	Value string `json:"value" binding:"required"`
+	Tag   string `json:"tag"`
+}
+
+// Status 获取 filtered stream 消费状态
+// @Summary 获取实时流状态
+// @Tags stream
+// @Produce json
+// @Success 200 {object} dto.StreamStatus
+// @Router /api/v1/stream/status [get]
+func (h *StreamHandler) Status(c *gin.Context) {
+	c.JSON(http.StatusOK, h.streamService.Status())
+}
+
+// ListRules 获取流规则
+// @Summary 获取流规则列表
+// @Tags stream
+// @Produce json
+// @Success 200 {array} twitter.StreamRule
+// @Router /api/v1/stream/rules [get]
+func (h *StreamHandler) ListRules(c *gin.Context) {
+	rules, err := h.streamService.ListRules(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, rules)
+}
+
+// AddRule 添加流规则
+// @Summary 添加流规则
+// @Tags stream
+// @Accept json
+// @Produce json
+// @Param input body AddStreamRuleRequest true "规则内容"
+// @Success 201 {object} twitter.StreamRule
+// @Router /api/v1/stream/rules [post]
+func (h *StreamHandler) AddRule(c *gin.Context) {
+	var req AddStreamRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule, err := h.streamService.AddRule(c.Request.Context(), req.Value, req.Tag)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, rule)
+}
+
+// DeleteRule 删除流规则
+// @Summary 删除流规则
+// @Tags stream
+// @Param id path string true "规则ID"
+// @Success 204
+// @Router /api/v1/stream/rules/{id} [delete]
+func (h *StreamHandler) DeleteRule(c *gin.Context) {
+	if err := h.streamService.DeleteRule(c.Request.Context(), c.Param("id")); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
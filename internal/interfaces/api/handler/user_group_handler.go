@@ -0,0 +1,286 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zhoubofsy/x-bot/internal/domain/entity"
+	"github.com/zhoubofsy/x-bot/internal/domain/repository"
+)
+
+type UserGroupHandler struct {
+	groupRepo repository.UserGroupRepository
+	userRepo  repository.UserRepository
+}
+
+func NewUserGroupHandler(groupRepo repository.UserGroupRepository, userRepo repository.UserRepository) *UserGroupHandler {
+	return &UserGroupHandler{
+		groupRepo: groupRepo,
+		userRepo:  userRepo,
+	}
+}
+
+// List 获取所有分组
+// @Summary 获取用户分组列表
+// @Tags user-groups
+// @Produce json
+// @Success 200 {array} entity.UserGroup
+// @Router /api/v1/user-groups [get]
+func (h *UserGroupHandler) List(c *gin.Context) {
+	groups, err := h.groupRepo.GetAll(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, groups)
+}
+
+// Create 创建分组
+// @Summary 创建用户分组
+// @Tags user-groups
+// @Accept json
+// @Produce json
+// @Param input body entity.CreateUserGroupInput true "分组信息"
+// @Success 201 {object} entity.UserGroup
+// @Router /api/v1/user-groups [post]
+func (h *UserGroupHandler) Create(c *gin.Context) {
+	var input entity.CreateUserGroupInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !validTimezone(input.Timezone) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的时区: " + input.Timezone})
+		return
+	}
+
+	group := &entity.UserGroup{
+		Name:            input.Name,
+		Description:     input.Description,
+		AdCategories:    input.AdCategories,
+		AdCopyIDs:       input.AdCopyIDs,
+		MaxDailyReplies: input.MaxDailyReplies,
+		Weekdays:        input.Weekdays,
+		StartHour:       input.StartHour,
+		EndHour:         input.EndHour,
+		Timezone:        input.Timezone,
+		Priority:        input.Priority,
+		IsActive:        true,
+	}
+
+	if err := h.groupRepo.Save(c.Request.Context(), group); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, group)
+}
+
+// Update 更新分组
+// @Summary 更新用户分组
+// @Tags user-groups
+// @Accept json
+// @Produce json
+// @Param id path int true "分组ID"
+// @Param input body entity.UpdateUserGroupInput true "更新信息"
+// @Success 200 {object} entity.UserGroup
+// @Router /api/v1/user-groups/{id} [put]
+func (h *UserGroupHandler) Update(c *gin.Context) {
+	group, ok := h.loadGroup(c)
+	if !ok {
+		return
+	}
+
+	var input entity.UpdateUserGroupInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if input.Name != nil {
+		group.Name = *input.Name
+	}
+	if input.Description != nil {
+		group.Description = *input.Description
+	}
+	if input.AdCategories != nil {
+		group.AdCategories = *input.AdCategories
+	}
+	if input.AdCopyIDs != nil {
+		group.AdCopyIDs = *input.AdCopyIDs
+	}
+	if input.MaxDailyReplies != nil {
+		group.MaxDailyReplies = *input.MaxDailyReplies
+	}
+	if input.Weekdays != nil {
+		group.Weekdays = *input.Weekdays
+	}
+	if input.StartHour != nil {
+		group.StartHour = input.StartHour
+	}
+	if input.EndHour != nil {
+		group.EndHour = input.EndHour
+	}
+	if input.ClearSchedule {
+		group.Weekdays = nil
+		group.StartHour = nil
+		group.EndHour = nil
+	}
+	if input.Timezone != nil {
+		if !validTimezone(*input.Timezone) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的时区: " + *input.Timezone})
+			return
+		}
+		group.Timezone = *input.Timezone
+	}
+	if input.Priority != nil {
+		group.Priority = *input.Priority
+	}
+	if input.IsActive != nil {
+		group.IsActive = *input.IsActive
+	}
+
+	if err := h.groupRepo.Update(c.Request.Context(), group); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, group)
+}
+
+// Delete 删除分组（成员用户本身不受影响）
+// @Summary 删除用户分组
+// @Tags user-groups
+// @Param id path int true "分组ID"
+// @Success 204
+// @Router /api/v1/user-groups/{id} [delete]
+func (h *UserGroupHandler) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的分组ID"})
+		return
+	}
+
+	if err := h.groupRepo.Delete(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListMembers 获取分组成员
+// @Summary 获取分组成员
+// @Tags user-groups
+// @Produce json
+// @Param id path int true "分组ID"
+// @Success 200 {array} entity.FollowedUser
+// @Router /api/v1/user-groups/{id}/members [get]
+func (h *UserGroupHandler) ListMembers(c *gin.Context) {
+	group, ok := h.loadGroup(c)
+	if !ok {
+		return
+	}
+
+	users, err := h.groupRepo.GetMembers(c.Request.Context(), group.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, users)
+}
+
+// AddMembers 按 Twitter 用户ID 添加分组成员，未被监控的用户会在 not_found 中返回
+// @Summary 添加分组成员
+// @Tags user-groups
+// @Accept json
+// @Produce json
+// @Param id path int true "分组ID"
+// @Param input body entity.GroupMembersInput true "成员列表"
+// @Router /api/v1/user-groups/{id}/members [post]
+func (h *UserGroupHandler) AddMembers(c *gin.Context) {
+	group, ok := h.loadGroup(c)
+	if !ok {
+		return
+	}
+
+	var input entity.GroupMembersInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var userIDs []int
+	notFound := []string{}
+	for _, twitterID := range input.TwitterUserIDs {
+		user, err := h.userRepo.GetByTwitterID(c.Request.Context(), twitterID)
+		if err != nil || user == nil {
+			notFound = append(notFound, twitterID)
+			continue
+		}
+		userIDs = append(userIDs, user.ID)
+	}
+
+	if err := h.groupRepo.AddMembers(c.Request.Context(), group.ID, userIDs); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"added":     len(userIDs),
+		"not_found": notFound,
+	})
+}
+
+// RemoveMember 移除分组成员
+// @Summary 移除分组成员
+// @Tags user-groups
+// @Param id path int true "分组ID"
+// @Param twitter_id path string true "Twitter用户ID"
+// @Success 204
+// @Router /api/v1/user-groups/{id}/members/{twitter_id} [delete]
+func (h *UserGroupHandler) RemoveMember(c *gin.Context) {
+	group, ok := h.loadGroup(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.userRepo.GetByTwitterID(c.Request.Context(), c.Param("twitter_id"))
+	if err != nil || user == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "用户不存在"})
+		return
+	}
+
+	if err := h.groupRepo.RemoveMember(c.Request.Context(), group.ID, user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// loadGroup 解析路径中的分组ID并加载分组，失败时已写入响应
+func (h *UserGroupHandler) loadGroup(c *gin.Context) (*entity.UserGroup, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的分组ID"})
+		return nil, false
+	}
+
+	group, err := h.groupRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "分组不存在"})
+		return nil, false
+	}
+	return group, true
+}
+
+func validTimezone(tz string) bool {
+	if tz == "" {
+		return true
+	}
+	_, err := time.LoadLocation(tz)
+	return err == nil
+}
@@ -1,34 +1,130 @@
 package handler
 
 import (
+	"context"
+	"encoding/json"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/zhoubofsy/x-bot/internal/application/dto"
+	"github.com/zhoubofsy/x-bot/internal/application/service"
 	"github.com/zhoubofsy/x-bot/internal/domain/entity"
 	"github.com/zhoubofsy/x-bot/internal/domain/repository"
 )
 
 type UserHandler struct {
-	userRepo repository.UserRepository
+	userRepo        repository.UserRepository
+	followerService service.FollowerService
+	userActivity    service.UserActivityService
 }
 
-func NewUserHandler(userRepo repository.UserRepository) *UserHandler {
+func NewUserHandler(
+	userRepo repository.UserRepository,
+	followerService service.FollowerService,
+	userActivity service.UserActivityService,
+) *UserHandler {
 	return &UserHandler{
-		userRepo: userRepo,
+		userRepo:        userRepo,
+		followerService: followerService,
+		userActivity:    userActivity,
 	}
 }
 
 // AddUserRequest 添加用户请求
+// 只需提供用户名（可带 @），会通过 Twitter API 校验并补全用户ID和资料；
+// API 不可用时，如同时提供了 twitter_user_id，则按提供的信息直接保存
 type AddUserRequest struct {
-	TwitterUserID string `json:"twitter_user_id" binding:"required"`
-	Username      string `json:"username" binding:"required"`
+	Username      string `json:"username" binding:"required_without=TwitterUserID"` // 可带 @，为空时按 twitter_user_id 查询
+	TwitterUserID string `json:"twitter_user_id" binding:"required_without=Username"`
 	DisplayName   string `json:"display_name"`
+
+	// 可选：该用户的回复频率策略，不填使用全局默认值
+	ReplyCooldownSeconds *int `json:"reply_cooldown_seconds" binding:"omitempty,min=0"`
+	MaxRepliesPerWeek    *int `json:"max_replies_per_week" binding:"omitempty,min=0"`
+}
+
+// BatchAddResult 批量添加中单个用户名的处理结果
+type BatchAddResult struct {
+	Handle string               `json:"handle"`
+	Status string               `json:"status"` // added, skipped, failed
+	User   *entity.FollowedUser `json:"user,omitempty"`
+	Error  string               `json:"error,omitempty"`
 }
 
-// List 获取所有监控用户
+// ReplyPolicyRequest 更新用户回复频率策略请求，字段为 null 表示恢复全局默认值，未传的字段保持不变
+type ReplyPolicyRequest struct {
+	ReplyCooldownSeconds *int `json:"reply_cooldown_seconds" binding:"omitempty,min=0"`
+	MaxRepliesPerWeek    *int `json:"max_replies_per_week" binding:"omitempty,min=0"`
+}
+
+// List 获取监控用户，支持筛选：
+// status=active|inactive|all|deleted（默认 active，deleted 只返回已删除的用户）、source、verified=true|false、
+// min_followers、max_followers、group_id、dormant=true|false、q（用户名或显示名）、
+// sort=followers|last_tweet|hit_rate
 func (h *UserHandler) List(c *gin.Context) {
-	users, err := h.userRepo.GetAllActiveUsers(c.Request.Context())
+	filter := repository.UserFilter{
+		Source:  c.Query("source"),
+		Keyword: strings.TrimPrefix(c.Query("q"), "@"),
+		OrderBy: c.Query("sort"),
+	}
+
+	switch status := c.DefaultQuery("status", "active"); status {
+	case "active":
+		active := true
+		filter.IsActive = &active
+	case "inactive":
+		active := false
+		filter.IsActive = &active
+	case "deleted":
+		filter.Deleted = true
+	case "all":
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status 只能为 active、inactive、all 或 deleted"})
+		return
+	}
+	if v := c.Query("verified"); v != "" {
+		verified, err := strconv.ParseBool(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "verified 必须为 true 或 false"})
+			return
+		}
+		filter.Verified = &verified
+	}
+	if v := c.Query("dormant"); v != "" {
+		dormant, err := strconv.ParseBool(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "dormant 必须为 true 或 false"})
+			return
+		}
+		filter.Dormant = &dormant
+	}
+	for _, p := range []struct {
+		name string
+		dest *int
+	}{
+		{"min_followers", &filter.MinFollowers},
+		{"max_followers", &filter.MaxFollowers},
+		{"group_id", &filter.GroupID},
+	} {
+		v := c.Query(p.name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": p.name + " 必须为非负整数"})
+			return
+		}
+		*p.dest = n
+	}
+	if filter.MaxFollowers > 0 && filter.MinFollowers > filter.MaxFollowers {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "min_followers 不能大于 max_followers"})
+		return
+	}
+
+	users, err := h.userRepo.List(c.Request.Context(), filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -36,6 +132,26 @@ func (h *UserHandler) List(c *gin.Context) {
 	c.JSON(http.StatusOK, users)
 }
 
+// RefreshProfiles 立即刷新所有活跃用户的资料
+func (h *UserHandler) RefreshProfiles(c *gin.Context) {
+	result, err := h.followerService.RefreshProfiles(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// EvaluateActivity 立即评估用户活跃度，处理休眠或低价值用户
+func (h *UserHandler) EvaluateActivity(c *gin.Context) {
+	result, err := h.userActivity.Evaluate(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
 // Add 手动添加监控用户
 func (h *UserHandler) Add(c *gin.Context) {
 	var req AddUserRequest
@@ -44,12 +160,23 @@ func (h *UserHandler) Add(c *gin.Context) {
 		return
 	}
 
+	lookups, err := h.resolveUsers(c.Request.Context(), []AddUserRequest{req})
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "查询用户资料失败: " + err.Error()})
+		return
+	}
+	if lookups[0].Error != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": lookups[0].Handle + ": " + lookups[0].Error})
+		return
+	}
+	user := lookups[0].User
+
 	// 检查用户是否已存在
-	existing, _ := h.userRepo.GetByTwitterID(c.Request.Context(), req.TwitterUserID)
+	existing, _ := h.userRepo.GetByTwitterID(c.Request.Context(), user.TwitterUserID)
 	if existing != nil {
 		// 如果已存在但被禁用，重新激活
 		if !existing.IsActive {
-			h.userRepo.UpdateActiveStatus(c.Request.Context(), req.TwitterUserID, true)
+			h.userRepo.UpdateActiveStatus(c.Request.Context(), user.TwitterUserID, true)
 			existing.IsActive = true
 			c.JSON(http.StatusOK, gin.H{
 				"message": "用户已重新激活",
@@ -61,13 +188,6 @@ func (h *UserHandler) Add(c *gin.Context) {
 		return
 	}
 
-	user := &entity.FollowedUser{
-		TwitterUserID: req.TwitterUserID,
-		Username:      req.Username,
-		DisplayName:   req.DisplayName,
-		IsActive:      true,
-	}
-
 	if err := h.userRepo.Save(c.Request.Context(), user); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -79,40 +199,133 @@ func (h *UserHandler) Add(c *gin.Context) {
 	})
 }
 
-// BatchAdd 批量添加监控用户
+// BatchAdd 批量添加监控用户，返回每个用户名的处理结果
 func (h *UserHandler) BatchAdd(c *gin.Context) {
-	var users []AddUserRequest
-	if err := c.ShouldBindJSON(&users); err != nil {
+	var reqs []AddUserRequest
+	if err := c.ShouldBindJSON(&reqs); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	var added, skipped int
-	for _, req := range users {
-		existing, _ := h.userRepo.GetByTwitterID(c.Request.Context(), req.TwitterUserID)
-		if existing != nil {
-			skipped++
+	lookups, err := h.resolveUsers(c.Request.Context(), reqs)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "查询用户资料失败: " + err.Error()})
+		return
+	}
+
+	var added, skipped, failed int
+	results := make([]BatchAddResult, 0, len(lookups))
+	for _, lookup := range lookups {
+		result := BatchAddResult{Handle: lookup.Handle}
+
+		if lookup.Error != "" {
+			result.Status = "failed"
+			result.Error = lookup.Error
+			failed++
+			results = append(results, result)
 			continue
 		}
 
-		user := &entity.FollowedUser{
-			TwitterUserID: req.TwitterUserID,
-			Username:      req.Username,
-			DisplayName:   req.DisplayName,
-			IsActive:      true,
-		}
-		if err := h.userRepo.Save(c.Request.Context(), user); err == nil {
+		existing, _ := h.userRepo.GetByTwitterID(c.Request.Context(), lookup.User.TwitterUserID)
+		if existing != nil {
+			result.Status = "skipped"
+			result.User = existing
+			result.Error = "用户已存在"
+			skipped++
+		} else if err := h.userRepo.Save(c.Request.Context(), lookup.User); err != nil {
+			result.Status = "failed"
+			result.Error = err.Error()
+			failed++
+		} else {
+			result.Status = "added"
+			result.User = lookup.User
 			added++
 		}
+
+		results = append(results, result)
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "批量添加完成",
 		"added":   added,
 		"skipped": skipped,
+		"failed":  failed,
+		"results": results,
 	})
 }
 
+// resolveUsers 通过 API 解析用户资料，并应用请求中的回复策略：提供用户名时按用户名查询，否则按 twitter_user_id 查询
+// API 调用失败时，只有全部请求都同时提供了用户名和 twitter_user_id 才回退为按请求内容保存
+func (h *UserHandler) resolveUsers(ctx context.Context, reqs []AddUserRequest) ([]*dto.UserLookupResult, error) {
+	var handles, ids []string
+	for _, req := range reqs {
+		if req.Username != "" {
+			handles = append(handles, req.Username)
+		} else {
+			ids = append(ids, req.TwitterUserID)
+		}
+	}
+
+	lookups, err := h.lookupUsers(ctx, reqs, handles, ids)
+	if err != nil {
+		for _, req := range reqs {
+			if req.Username == "" || req.TwitterUserID == "" {
+				return nil, err
+			}
+		}
+		lookups = make([]*dto.UserLookupResult, 0, len(reqs))
+		for _, req := range reqs {
+			lookups = append(lookups, &dto.UserLookupResult{
+				Handle:   req.Username,
+				Username: strings.TrimPrefix(req.Username, "@"),
+				User: &entity.FollowedUser{
+					TwitterUserID: req.TwitterUserID,
+					Username:      strings.TrimPrefix(req.Username, "@"),
+					DisplayName:   req.DisplayName,
+					IsActive:      true,
+				},
+			})
+		}
+	}
+
+	for i, lookup := range lookups {
+		if lookup.User == nil {
+			continue
+		}
+		lookup.User.Source = entity.UserSourceManual
+		lookup.User.ReplyCooldownSeconds = reqs[i].ReplyCooldownSeconds
+		lookup.User.MaxRepliesPerWeek = reqs[i].MaxRepliesPerWeek
+	}
+
+	return lookups, nil
+}
+
+// lookupUsers 分别按用户名和用户ID查询，再按请求顺序合并结果
+func (h *UserHandler) lookupUsers(ctx context.Context, reqs []AddUserRequest, handles, ids []string) ([]*dto.UserLookupResult, error) {
+	var byName, byID []*dto.UserLookupResult
+	var err error
+	if len(handles) > 0 {
+		if byName, err = h.followerService.LookupUsers(ctx, handles); err != nil {
+			return nil, err
+		}
+	}
+	if len(ids) > 0 {
+		if byID, err = h.followerService.LookupUsersByID(ctx, ids); err != nil {
+			return nil, err
+		}
+	}
+
+	lookups := make([]*dto.UserLookupResult, 0, len(reqs))
+	for _, req := range reqs {
+		if req.Username != "" {
+			lookups, byName = append(lookups, byName[0]), byName[1:]
+		} else {
+			lookups, byID = append(lookups, byID[0]), byID[1:]
+		}
+	}
+	return lookups, nil
+}
+
 // Delete 删除监控用户
 func (h *UserHandler) Delete(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
@@ -129,6 +342,22 @@ func (h *UserHandler) Delete(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "用户已删除"})
 }
 
+// Restore 恢复已删除的监控用户，分组成员关系和推文统计保持不变
+func (h *UserHandler) Restore(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的用户ID"})
+		return
+	}
+
+	if err := h.userRepo.Restore(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "已删除的用户不存在"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "用户已恢复"})
+}
+
 // UpdateStatus 更新用户状态
 func (h *UserHandler) UpdateStatus(c *gin.Context) {
 	twitterID := c.Param("twitter_id")
@@ -148,3 +377,43 @@ func (h *UserHandler) UpdateStatus(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "状态已更新"})
 }
 
+// UpdateReplyPolicy 更新用户回复频率策略
+func (h *UserHandler) UpdateReplyPolicy(c *gin.Context) {
+	twitterID := c.Param("twitter_id")
+	var req ReplyPolicyRequest
+	if err := c.ShouldBindBodyWithJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// 区分未传的字段和显式传 null 的字段
+	var present map[string]json.RawMessage
+	if body, ok := c.Get(gin.BodyBytesKey); ok {
+		if err := json.Unmarshal(body.([]byte), &present); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	user, err := h.userRepo.GetByTwitterID(c.Request.Context(), twitterID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "用户不存在"})
+		return
+	}
+
+	if _, ok := present["reply_cooldown_seconds"]; ok {
+		user.ReplyCooldownSeconds = req.ReplyCooldownSeconds
+	}
+	if _, ok := present["max_replies_per_week"]; ok {
+		user.MaxRepliesPerWeek = req.MaxRepliesPerWeek
+	}
+	if err := h.userRepo.UpdateReplyPolicy(c.Request.Context(), twitterID, user.ReplyCooldownSeconds, user.MaxRepliesPerWeek); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "回复策略已更新",
+		"user":    user,
+	})
+}
+
@@ -13,17 +13,20 @@ import (
 type WorkflowHandler struct {
 	workflowService service.WorkflowService
 	followerService service.FollowerService
+	retryService    service.RetryService
 	replyLogRepo    repository.ReplyLogRepository
 }
 
 func NewWorkflowHandler(
 	workflowService service.WorkflowService,
 	followerService service.FollowerService,
+	retryService service.RetryService,
 	replyLogRepo repository.ReplyLogRepository,
 ) *WorkflowHandler {
 	return &WorkflowHandler{
 		workflowService: workflowService,
 		followerService: followerService,
+		retryService:    retryService,
 		replyLogRepo:    replyLogRepo,
 	}
 }
@@ -123,3 +126,27 @@ func (h *WorkflowHandler) GetRecentLogs(c *gin.Context) {
 
 	c.JSON(http.StatusOK, logs)
 }
+
+// RetryReplyLog 手动重试失败的回复
+// @Summary 手动重试失败的回复
+// @Description 立即重新发送失败或等待重试的回复，不受最大重试次数限制
+// @Tags workflow
+// @Produce json
+// @Param id path int true "回复日志ID"
+// @Success 200 {object} entity.ReplyLog
+// @Router /api/v1/reply-logs/{id}/retry [post]
+func (h *WorkflowHandler) RetryReplyLog(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	log, err := h.retryService.Retry(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, log)
+}
@@ -11,12 +11,26 @@ type Router struct {
 	workflowHandler *handler.WorkflowHandler
 	adCopyHandler   *handler.AdCopyHandler
 	userHandler     *handler.UserHandler
+	groupHandler    *handler.UserGroupHandler
+	campaignHandler *handler.CampaignHandler
+	linkHandler     *handler.LinkHandler
+	bulkHandler     *handler.BulkHandler
+	approvalHandler *handler.ApprovalHandler
+	searchHandler   *handler.SearchQueryHandler
+	streamHandler   *handler.StreamHandler
 }
 
 func NewRouter(
 	workflowHandler *handler.WorkflowHandler,
 	adCopyHandler *handler.AdCopyHandler,
 	userHandler *handler.UserHandler,
+	groupHandler *handler.UserGroupHandler,
+	campaignHandler *handler.CampaignHandler,
+	linkHandler *handler.LinkHandler,
+	bulkHandler *handler.BulkHandler,
+	approvalHandler *handler.ApprovalHandler,
+	searchHandler *handler.SearchQueryHandler,
+	streamHandler *handler.StreamHandler,
 	mode string,
 	apiKey string,
 ) *Router {
@@ -33,6 +47,13 @@ func NewRouter(
 		workflowHandler: workflowHandler,
 		adCopyHandler:   adCopyHandler,
 		userHandler:     userHandler,
+		groupHandler:    groupHandler,
+		campaignHandler: campaignHandler,
+		linkHandler:     linkHandler,
+		bulkHandler:     bulkHandler,
+		approvalHandler: approvalHandler,
+		searchHandler:   searchHandler,
+		streamHandler:   streamHandler,
 	}
 
 	r.setupRoutes(apiKey)
@@ -46,6 +67,9 @@ func (r *Router) setupRoutes(apiKey string) {
 		c.JSON(200, gin.H{"status": "ok"})
 	})
 
+	// Tracked links (回复中的短链接，公开访问)
+	r.engine.GET("/r/:code", r.linkHandler.Redirect)
+
 	// API v1
 	v1 := r.engine.Group("/api/v1")
 	v1.Use(middleware.APIKeyAuth(apiKey))
@@ -60,15 +84,52 @@ func (r *Router) setupRoutes(apiKey string) {
 		// Stats & Logs
 		v1.GET("/stats", r.workflowHandler.GetStats)
 		v1.GET("/reply-logs", r.workflowHandler.GetRecentLogs)
+		v1.POST("/reply-logs/:id/retry", r.workflowHandler.RetryReplyLog)
+
+		// Approvals (人工审核回复)
+		approvals := v1.Group("/approvals")
+		{
+			approvals.GET("", r.approvalHandler.List)
+			approvals.PUT("/:id", r.approvalHandler.Edit)
+			approvals.POST("/:id/approve", r.approvalHandler.Approve)
+			approvals.POST("/:id/reject", r.approvalHandler.Reject)
+		}
 
 		// Ad Copies
 		adCopies := v1.Group("/ad-copies")
 		{
 			adCopies.GET("", r.adCopyHandler.List)
+			adCopies.GET("/performance", r.adCopyHandler.Performance)
+			adCopies.POST("/collect-metrics", r.adCopyHandler.CollectMetrics)
+			adCopies.GET("/export", r.bulkHandler.ExportAdCopies)
+			adCopies.POST("/import", r.bulkHandler.ImportAdCopies)
 			adCopies.GET("/:id", r.adCopyHandler.Get)
+			adCopies.GET("/:id/preview", r.adCopyHandler.PreviewSaved)
+			adCopies.GET("/:id/revisions", r.adCopyHandler.Revisions)
+			adCopies.POST("/:id/revisions/:revision/rollback", r.adCopyHandler.Rollback)
 			adCopies.POST("", r.adCopyHandler.Create)
+			adCopies.POST("/preview", r.adCopyHandler.Preview)
 			adCopies.PUT("/:id", r.adCopyHandler.Update)
 			adCopies.DELETE("/:id", r.adCopyHandler.Delete)
+			adCopies.POST("/:id/restore", r.adCopyHandler.Restore)
+		}
+
+		// Search Queries (搜索发现未关注账号的推文)
+		searchQueries := v1.Group("/search-queries")
+		{
+			searchQueries.GET("", r.searchHandler.List)
+			searchQueries.POST("", r.searchHandler.Create)
+			searchQueries.PUT("/:id", r.searchHandler.Update)
+			searchQueries.DELETE("/:id", r.searchHandler.Delete)
+		}
+
+		// Filtered Stream (实时流规则与状态)
+		stream := v1.Group("/stream")
+		{
+			stream.GET("/status", r.streamHandler.Status)
+			stream.GET("/rules", r.streamHandler.ListRules)
+			stream.POST("/rules", r.streamHandler.AddRule)
+			stream.DELETE("/rules/:id", r.streamHandler.DeleteRule)
 		}
 
 		// Monitored Users (手动管理监控用户)
@@ -77,9 +138,41 @@ func (r *Router) setupRoutes(apiKey string) {
 			users.GET("", r.userHandler.List)
 			users.POST("", r.userHandler.Add)
 			users.POST("/batch", r.userHandler.BatchAdd)
+			users.GET("/export", r.bulkHandler.ExportUsers)
+			users.POST("/import", r.bulkHandler.ImportUsers)
+			users.POST("/refresh-profiles", r.userHandler.RefreshProfiles)
+			users.POST("/evaluate-activity", r.userHandler.EvaluateActivity)
 			users.DELETE("/:id", r.userHandler.Delete)
+			users.POST("/:id/restore", r.userHandler.Restore)
 			users.PATCH("/:twitter_id/status", r.userHandler.UpdateStatus)
+			users.PATCH("/:twitter_id/reply-policy", r.userHandler.UpdateReplyPolicy)
+		}
+
+		// User Groups (用户分组与分组投放)
+		groups := v1.Group("/user-groups")
+		{
+			groups.GET("", r.groupHandler.List)
+			groups.POST("", r.groupHandler.Create)
+			groups.PUT("/:id", r.groupHandler.Update)
+			groups.DELETE("/:id", r.groupHandler.Delete)
+			groups.GET("/:id/members", r.groupHandler.ListMembers)
+			groups.POST("/:id/members", r.groupHandler.AddMembers)
+			groups.DELETE("/:id/members/:twitter_id", r.groupHandler.RemoveMember)
 		}
+
+		// Campaigns (推广活动：文案、投放目标与预算)
+		campaigns := v1.Group("/campaigns")
+		{
+			campaigns.GET("", r.campaignHandler.List)
+			campaigns.POST("", r.campaignHandler.Create)
+			campaigns.GET("/:id", r.campaignHandler.Get)
+			campaigns.PUT("/:id", r.campaignHandler.Update)
+			campaigns.DELETE("/:id", r.campaignHandler.Delete)
+			campaigns.GET("/:id/stats", r.campaignHandler.Stats)
+		}
+
+		// Tracked Links (短链接与点击)
+		v1.GET("/links", r.linkHandler.List)
 	}
 }
 
@@ -2,6 +2,8 @@ package scheduler
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/robfig/cron/v3"
 	"github.com/zhoubofsy/x-bot/internal/application/dto"
@@ -13,37 +15,149 @@ import (
 type Scheduler struct {
 	cron            *cron.Cron
 	workflowService service.WorkflowService
+	followerService service.FollowerService
+	userActivity    service.UserActivityService
+	adMetrics       service.AdMetricsService
+	adReplyService  service.AdReplyService
+	approvalService service.ApprovalService
+	retryService    service.RetryService
+	purgeService    service.PurgeService
 	cfg             *config.WorkflowConfig
 	logger          *zap.Logger
 }
 
 func NewScheduler(
 	workflowService service.WorkflowService,
+	followerService service.FollowerService,
+	userActivity service.UserActivityService,
+	adMetrics service.AdMetricsService,
+	adReplyService service.AdReplyService,
+	approvalService service.ApprovalService,
+	retryService service.RetryService,
+	purgeService service.PurgeService,
 	cfg *config.WorkflowConfig,
 	logger *zap.Logger,
 ) *Scheduler {
 	return &Scheduler{
 		cron:            cron.New(),
 		workflowService: workflowService,
+		followerService: followerService,
+		userActivity:    userActivity,
+		adMetrics:       adMetrics,
+		adReplyService:  adReplyService,
+		approvalService: approvalService,
+		retryService:    retryService,
+		purgeService:    purgeService,
 		cfg:             cfg,
 		logger:          logger,
 	}
 }
 
 func (s *Scheduler) Start() error {
-	if !s.cfg.EnableScheduler {
+	jobs := 0
+
+	if s.cfg.EnableScheduler {
+		if _, err := s.cron.AddFunc(s.cfg.Schedule, s.executeWorkflow); err != nil {
+			s.logger.Error("添加定时任务失败", zap.Error(err))
+			return err
+		}
+		s.logger.Info("工作流定时任务已添加", zap.String("schedule", s.cfg.Schedule))
+		jobs++
+	} else {
 		s.logger.Info("定时任务已禁用")
-		return nil
 	}
 
-	_, err := s.cron.AddFunc(s.cfg.Schedule, s.executeWorkflow)
-	if err != nil {
-		s.logger.Error("添加定时任务失败", zap.Error(err))
-		return err
+	// 人工审核模式下按回复间隔逐条发送已批准的回复
+	if s.cfg.RequireApproval {
+		interval := s.cfg.ReplyInterval
+		if interval <= 0 {
+			interval = time.Minute
+		}
+		spec := fmt.Sprintf("@every %s", interval)
+		if _, err := s.cron.AddFunc(spec, s.sendApprovedReply); err != nil {
+			s.logger.Error("添加审核发送任务失败", zap.Error(err))
+			return err
+		}
+		s.logger.Info("审核发送任务已添加", zap.Duration("interval", interval))
+		jobs++
+	}
+
+	// 定期重试临时性失败的回复
+	if s.cfg.Retry.MaxAttempts > 0 {
+		interval := s.cfg.Retry.CheckInterval
+		if interval <= 0 {
+			interval = time.Minute
+		}
+		spec := fmt.Sprintf("@every %s", interval)
+		if _, err := s.cron.AddFunc(spec, s.processRetries); err != nil {
+			s.logger.Error("添加重试任务失败", zap.Error(err))
+			return err
+		}
+		s.logger.Info("重试任务已添加", zap.Duration("interval", interval))
+		jobs++
+	}
+
+	// 定期刷新监控用户资料
+	if s.cfg.ProfileRefresh > 0 {
+		spec := fmt.Sprintf("@every %s", s.cfg.ProfileRefresh)
+		if _, err := s.cron.AddFunc(spec, s.refreshProfiles); err != nil {
+			s.logger.Error("添加用户资料刷新任务失败", zap.Error(err))
+			return err
+		}
+		s.logger.Info("用户资料刷新任务已添加", zap.Duration("interval", s.cfg.ProfileRefresh))
+		jobs++
+	}
+
+	// 定期评估用户活跃度，停用或降低休眠、低价值用户的拉取频率
+	if s.cfg.Activity.EvaluateInterval > 0 {
+		spec := fmt.Sprintf("@every %s", s.cfg.Activity.EvaluateInterval)
+		if _, err := s.cron.AddFunc(spec, s.evaluateActivity); err != nil {
+			s.logger.Error("添加用户活跃度评估任务失败", zap.Error(err))
+			return err
+		}
+		s.logger.Info("用户活跃度评估任务已添加", zap.Duration("interval", s.cfg.Activity.EvaluateInterval))
+		jobs++
+	}
+
+	// 定期拉取回复互动数据，供文案选择策略学习
+	if interval := s.cfg.AdSelection.MetricsInterval; interval > 0 {
+		spec := fmt.Sprintf("@every %s", interval)
+		if _, err := s.cron.AddFunc(spec, s.collectReplyMetrics); err != nil {
+			s.logger.Error("添加回复互动数据任务失败", zap.Error(err))
+			return err
+		}
+		s.logger.Info("回复互动数据任务已添加", zap.Duration("interval", interval))
+		jobs++
+	}
+
+	// 定期停用已过结束时间的广告文案（选择文案时本身会跳过过期文案）
+	if interval := s.cfg.AdCopyExpireCheck; interval > 0 {
+		spec := fmt.Sprintf("@every %s", interval)
+		if _, err := s.cron.AddFunc(spec, s.deactivateExpiredAdCopies); err != nil {
+			s.logger.Error("添加广告文案过期检查任务失败", zap.Error(err))
+			return err
+		}
+		s.logger.Info("广告文案过期检查任务已添加", zap.Duration("interval", interval))
+		jobs++
+	}
+
+	// 定期永久删除已软删除且没有被引用的文案和用户
+	if interval := s.cfg.Purge.Interval; interval > 0 {
+		spec := fmt.Sprintf("@every %s", interval)
+		if _, err := s.cron.AddFunc(spec, s.purgeDeleted); err != nil {
+			s.logger.Error("添加已删除数据清理任务失败", zap.Error(err))
+			return err
+		}
+		s.logger.Info("已删除数据清理任务已添加", zap.Duration("interval", interval))
+		jobs++
+	}
+
+	if jobs == 0 {
+		return nil
 	}
 
 	s.cron.Start()
-	s.logger.Info("定时任务已启动", zap.String("schedule", s.cfg.Schedule))
+	s.logger.Info("定时任务已启动")
 
 	return nil
 }
@@ -78,3 +192,57 @@ func (s *Scheduler) executeWorkflow() {
 	)
 }
 
+func (s *Scheduler) sendApprovedReply() {
+	result, err := s.approvalService.SendNextApproved(context.Background())
+	if err != nil {
+		s.logger.Error("发送已批准回复失败", zap.Error(err))
+		return
+	}
+	if result == nil {
+		return
+	}
+
+	s.logger.Info("已批准回复发送完成",
+		zap.Int("reply_log_id", result.ReplyLogID),
+		zap.String("tweet_id", result.TweetID),
+		zap.String("reply_tweet_id", result.ReplyTweetID),
+		zap.String("error", result.Error),
+	)
+}
+
+func (s *Scheduler) processRetries() {
+	// 每次只重试一条，与正常回复保持相同的节奏
+	if _, err := s.retryService.ProcessDue(context.Background(), 1); err != nil {
+		s.logger.Error("重试失败回复出错", zap.Error(err))
+	}
+}
+
+func (s *Scheduler) refreshProfiles() {
+	if _, err := s.followerService.RefreshProfiles(context.Background()); err != nil {
+		s.logger.Error("刷新用户资料失败", zap.Error(err))
+	}
+}
+
+func (s *Scheduler) evaluateActivity() {
+	if _, err := s.userActivity.Evaluate(context.Background()); err != nil {
+		s.logger.Error("评估用户活跃度失败", zap.Error(err))
+	}
+}
+
+func (s *Scheduler) collectReplyMetrics() {
+	if _, err := s.adMetrics.CollectReplyMetrics(context.Background()); err != nil {
+		s.logger.Error("拉取回复互动数据失败", zap.Error(err))
+	}
+}
+
+func (s *Scheduler) deactivateExpiredAdCopies() {
+	if _, err := s.adReplyService.DeactivateExpired(context.Background()); err != nil {
+		s.logger.Error("停用过期广告文案失败", zap.Error(err))
+	}
+}
+
+func (s *Scheduler) purgeDeleted() {
+	if _, err := s.purgeService.PurgeDeleted(context.Background()); err != nil {
+		s.logger.Error("清理已删除数据失败", zap.Error(err))
+	}
+}
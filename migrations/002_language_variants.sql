@@ -0,0 +1,17 @@
+-- 广告文案默认内容的语言
+ALTER TABLE ad_copies ADD COLUMN IF NOT EXISTS language VARCHAR(16) DEFAULT '';
+
+-- 广告文案多语言变体表
+CREATE TABLE IF NOT EXISTS ad_copy_variants (
+    id SERIAL PRIMARY KEY,
+    ad_copy_id INT NOT NULL REFERENCES ad_copies(id) ON DELETE CASCADE,
+    language VARCHAR(16) NOT NULL,
+    content TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ad_copy_variants_copy_lang ON ad_copy_variants(ad_copy_id, language);
+
+-- 回复日志记录推文语言
+ALTER TABLE reply_logs ADD COLUMN IF NOT EXISTS tweet_lang VARCHAR(16);
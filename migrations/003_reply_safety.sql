@@ -0,0 +1,2 @@
+-- 回复日志记录跳过/拦截原因（如安全审核命中的类别）
+ALTER TABLE reply_logs ADD COLUMN IF NOT EXISTS skip_reason TEXT;
@@ -0,0 +1,3 @@
+-- 人工审核：记录拟发送（或已发送）的回复内容和审核时间
+ALTER TABLE reply_logs ADD COLUMN IF NOT EXISTS reply_content TEXT;
+ALTER TABLE reply_logs ADD COLUMN IF NOT EXISTS reviewed_at TIMESTAMP WITH TIME ZONE;
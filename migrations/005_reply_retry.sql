@@ -0,0 +1,6 @@
+-- 回复失败分类与重试
+ALTER TABLE reply_logs ADD COLUMN IF NOT EXISTS failure_reason VARCHAR(32);
+ALTER TABLE reply_logs ADD COLUMN IF NOT EXISTS retry_count INT DEFAULT 0;
+ALTER TABLE reply_logs ADD COLUMN IF NOT EXISTS next_retry_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_reply_logs_next_retry_at ON reply_logs(next_retry_at);
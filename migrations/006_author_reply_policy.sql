@@ -0,0 +1,5 @@
+-- 按作者的回复频率策略，NULL 表示使用全局默认值
+ALTER TABLE followed_users ADD COLUMN IF NOT EXISTS reply_cooldown_seconds INT;
+ALTER TABLE followed_users ADD COLUMN IF NOT EXISTS max_replies_per_week INT;
+
+CREATE INDEX IF NOT EXISTS idx_reply_logs_author_created ON reply_logs(tweet_author_id, created_at);
@@ -0,0 +1,6 @@
+-- 会话级去重：记录推文所在会话和被引用的推文
+ALTER TABLE reply_logs ADD COLUMN IF NOT EXISTS conversation_id VARCHAR(64);
+ALTER TABLE reply_logs ADD COLUMN IF NOT EXISTS quoted_tweet_id VARCHAR(64);
+
+CREATE INDEX IF NOT EXISTS idx_reply_logs_conversation_id ON reply_logs(conversation_id);
+CREATE INDEX IF NOT EXISTS idx_reply_logs_quoted_tweet_id ON reply_logs(quoted_tweet_id);
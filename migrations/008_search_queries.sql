@@ -0,0 +1,14 @@
+-- 保存的推文搜索条件（recent search），各自维护 since_id 游标
+CREATE TABLE IF NOT EXISTS search_queries (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(128) NOT NULL,
+    query TEXT NOT NULL,
+    max_results INT DEFAULT 10,
+    is_active BOOLEAN DEFAULT true,
+    since_id VARCHAR(64),
+    last_run_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_search_queries_active ON search_queries(is_active);
@@ -0,0 +1,5 @@
+-- 监控用户来源：manual（手动添加）、following（关注列表）、list:<id>（X 列表）
+-- 已有用户无法区分来源，统一视为手动添加，避免同步时被误停用
+ALTER TABLE followed_users ADD COLUMN IF NOT EXISTS source VARCHAR(64) DEFAULT 'manual';
+
+CREATE INDEX IF NOT EXISTS idx_followed_users_source ON followed_users(source);
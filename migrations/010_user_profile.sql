@@ -0,0 +1,5 @@
+-- 监控用户资料：通过 /2/users/by 按用户名解析时填充
+ALTER TABLE followed_users ADD COLUMN IF NOT EXISTS description TEXT;
+ALTER TABLE followed_users ADD COLUMN IF NOT EXISTS followers_count INT DEFAULT 0;
+ALTER TABLE followed_users ADD COLUMN IF NOT EXISTS following_count INT DEFAULT 0;
+ALTER TABLE followed_users ADD COLUMN IF NOT EXISTS protected BOOLEAN DEFAULT false;
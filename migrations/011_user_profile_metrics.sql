@@ -0,0 +1,9 @@
+-- 监控用户资料指标：通过 user.fields 拉取并定期刷新，可用于筛选和定向规则
+ALTER TABLE followed_users ADD COLUMN IF NOT EXISTS tweet_count INT DEFAULT 0;
+ALTER TABLE followed_users ADD COLUMN IF NOT EXISTS verified BOOLEAN DEFAULT false;
+ALTER TABLE followed_users ADD COLUMN IF NOT EXISTS location VARCHAR(256);
+ALTER TABLE followed_users ADD COLUMN IF NOT EXISTS profile_url VARCHAR(512);
+ALTER TABLE followed_users ADD COLUMN IF NOT EXISTS last_tweet_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE followed_users ADD COLUMN IF NOT EXISTS profile_refreshed_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_followed_users_followers ON followed_users(followers_count);
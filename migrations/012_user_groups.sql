@@ -0,0 +1,30 @@
+-- 监控用户分组：按分组限定广告类别/文案、每日回复上限和回复时段
+CREATE TABLE IF NOT EXISTS user_groups (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(128) NOT NULL UNIQUE,
+    description TEXT,
+    ad_categories TEXT,
+    ad_copy_ids TEXT,
+    max_daily_replies INT DEFAULT 0,
+    weekdays TEXT,
+    start_hour INT,
+    end_hour INT,
+    timezone VARCHAR(64),
+    priority INT DEFAULT 0,
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS user_group_members (
+    user_group_id INT NOT NULL REFERENCES user_groups(id) ON DELETE CASCADE,
+    followed_user_id INT NOT NULL REFERENCES followed_users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_group_id, followed_user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_group_members_user ON user_group_members(followed_user_id);
+
+-- 记录回复所属分组，用于分组每日上限统计
+ALTER TABLE reply_logs ADD COLUMN IF NOT EXISTS user_group_id INT;
+CREATE INDEX IF NOT EXISTS idx_reply_logs_user_group_id ON reply_logs(user_group_id);
@@ -0,0 +1,7 @@
+-- 监控用户推文统计：用于自适应拉取频率和自动处理休眠、低价值用户
+ALTER TABLE followed_users ADD COLUMN IF NOT EXISTS tweets_seen INT DEFAULT 0;
+ALTER TABLE followed_users ADD COLUMN IF NOT EXISTS hackathon_hits INT DEFAULT 0;
+ALTER TABLE followed_users ADD COLUMN IF NOT EXISTS last_seen_tweet_id VARCHAR(64);
+ALTER TABLE followed_users ADD COLUMN IF NOT EXISTS last_checked_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE followed_users ADD COLUMN IF NOT EXISTS next_check_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE followed_users ADD COLUMN IF NOT EXISTS dormant_reason VARCHAR(256);
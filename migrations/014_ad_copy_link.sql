@@ -0,0 +1,2 @@
+-- 广告文案推广链接：文案模板中以 {{.TrackingLink}} 引用
+ALTER TABLE ad_copies ADD COLUMN IF NOT EXISTS link_url VARCHAR(512);
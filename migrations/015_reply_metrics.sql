@@ -0,0 +1,8 @@
+-- 回复推文互动数据：定期拉取，用于按效果选择广告文案
+ALTER TABLE reply_logs ADD COLUMN IF NOT EXISTS reply_like_count INT DEFAULT 0;
+ALTER TABLE reply_logs ADD COLUMN IF NOT EXISTS reply_reply_count INT DEFAULT 0;
+ALTER TABLE reply_logs ADD COLUMN IF NOT EXISTS reply_retweet_count INT DEFAULT 0;
+ALTER TABLE reply_logs ADD COLUMN IF NOT EXISTS reply_quote_count INT DEFAULT 0;
+ALTER TABLE reply_logs ADD COLUMN IF NOT EXISTS metrics_updated_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_reply_logs_ad_copy_id ON reply_logs(ad_copy_id);
@@ -0,0 +1,11 @@
+-- 广告文案投放时间、使用上限和时段
+ALTER TABLE ad_copies ADD COLUMN IF NOT EXISTS starts_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE ad_copies ADD COLUMN IF NOT EXISTS ends_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE ad_copies ADD COLUMN IF NOT EXISTS max_daily_uses INT DEFAULT 0;
+ALTER TABLE ad_copies ADD COLUMN IF NOT EXISTS max_total_uses INT DEFAULT 0;
+ALTER TABLE ad_copies ADD COLUMN IF NOT EXISTS weekdays TEXT;
+ALTER TABLE ad_copies ADD COLUMN IF NOT EXISTS start_hour INT;
+ALTER TABLE ad_copies ADD COLUMN IF NOT EXISTS end_hour INT;
+ALTER TABLE ad_copies ADD COLUMN IF NOT EXISTS timezone VARCHAR(64);
+
+CREATE INDEX IF NOT EXISTS idx_ad_copies_ends_at ON ad_copies(ends_at) WHERE ends_at IS NOT NULL;
@@ -0,0 +1,28 @@
+-- 推广活动：拥有广告文案，投放到指定的用户分组和搜索条件，并限制回复数和 LLM 花费
+CREATE TABLE IF NOT EXISTS campaigns (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(128) NOT NULL UNIQUE,
+    description TEXT,
+    category VARCHAR(64) DEFAULT 'hackathon',
+    status VARCHAR(16) DEFAULT 'draft',
+    finish_reason VARCHAR(255),
+    user_group_ids TEXT,
+    search_query_ids TEXT,
+    max_replies INT DEFAULT 0,
+    max_llm_spend DOUBLE PRECISION DEFAULT 0,
+    llm_calls INT DEFAULT 0,
+    llm_spend DOUBLE PRECISION DEFAULT 0,
+    starts_at TIMESTAMP WITH TIME ZONE,
+    ends_at TIMESTAMP WITH TIME ZONE,
+    priority INT DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_campaigns_status ON campaigns(status);
+
+ALTER TABLE ad_copies ADD COLUMN IF NOT EXISTS campaign_id INT REFERENCES campaigns(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_ad_copies_campaign_id ON ad_copies(campaign_id);
+
+ALTER TABLE reply_logs ADD COLUMN IF NOT EXISTS campaign_id INT;
+CREATE INDEX IF NOT EXISTS idx_reply_logs_campaign_id ON reply_logs(campaign_id);
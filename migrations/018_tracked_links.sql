@@ -0,0 +1,27 @@
+-- 回复链接追踪：回复中的链接替换为短链接，记录每次点击
+CREATE TABLE IF NOT EXISTS tracked_links (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(16) NOT NULL UNIQUE,
+    original_url TEXT NOT NULL,
+    target_url TEXT NOT NULL,
+    tweet_id VARCHAR(64) NOT NULL,
+    ad_copy_id INT,
+    campaign_id INT,
+    click_count INT DEFAULT 0,
+    last_clicked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_tracked_links_tweet_id ON tracked_links(tweet_id);
+CREATE INDEX IF NOT EXISTS idx_tracked_links_ad_copy_id ON tracked_links(ad_copy_id);
+
+CREATE TABLE IF NOT EXISTS link_clicks (
+    id SERIAL PRIMARY KEY,
+    tracked_link_id INT NOT NULL REFERENCES tracked_links(id) ON DELETE CASCADE,
+    referrer TEXT,
+    user_agent TEXT,
+    clicked_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_link_clicks_tracked_link_id ON link_clicks(tracked_link_id);
+CREATE INDEX IF NOT EXISTS idx_link_clicks_clicked_at ON link_clicks(clicked_at);
@@ -0,0 +1,29 @@
+-- 广告文案版本：文案内容每次变化生成不可变快照，回复日志引用实际使用的版本
+CREATE TABLE IF NOT EXISTS ad_copy_revisions (
+    id SERIAL PRIMARY KEY,
+    ad_copy_id INT NOT NULL,
+    revision INT NOT NULL,
+    name VARCHAR(128),
+    content TEXT NOT NULL,
+    link_url VARCHAR(512),
+    language VARCHAR(16),
+    variants TEXT,
+    note VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ad_copy_revisions_copy_rev ON ad_copy_revisions(ad_copy_id, revision);
+
+ALTER TABLE ad_copies ADD COLUMN IF NOT EXISTS revision INT DEFAULT 0;
+ALTER TABLE reply_logs ADD COLUMN IF NOT EXISTS ad_copy_revision INT DEFAULT 0;
+
+-- 为已有文案生成初始版本
+INSERT INTO ad_copy_revisions (ad_copy_id, revision, name, content, link_url, language, variants, note)
+SELECT a.id, 1, a.name, a.content, a.link_url, a.language,
+       (SELECT COALESCE(json_agg(json_build_object('language', v.language, 'content', v.content) ORDER BY v.language), '[]'::json)
+          FROM ad_copy_variants v WHERE v.ad_copy_id = a.id)::text,
+       'initial'
+FROM ad_copies a
+WHERE COALESCE(a.revision, 0) = 0;
+
+UPDATE ad_copies SET revision = 1 WHERE COALESCE(revision, 0) = 0;
@@ -0,0 +1,6 @@
+-- 广告文案和监控用户改为软删除，保留回复日志引用和监控历史
+ALTER TABLE ad_copies ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+CREATE INDEX IF NOT EXISTS idx_ad_copies_deleted_at ON ad_copies(deleted_at);
+
+ALTER TABLE followed_users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+CREATE INDEX IF NOT EXISTS idx_followed_users_deleted_at ON followed_users(deleted_at);
@@ -0,0 +1,6 @@
+-- 广告文案变体：模板中以 {{.Emoji}}、{{.Hashtag}} 引用的 emoji 和话题标签集合，每次回复随机选择
+ALTER TABLE ad_copies ADD COLUMN IF NOT EXISTS emojis TEXT;
+ALTER TABLE ad_copies ADD COLUMN IF NOT EXISTS hashtags TEXT;
+
+ALTER TABLE ad_copy_revisions ADD COLUMN IF NOT EXISTS emojis TEXT;
+ALTER TABLE ad_copy_revisions ADD COLUMN IF NOT EXISTS hashtags TEXT;
@@ -0,0 +1,2 @@
+-- 手动停用标记：通过 API 或批量导入停用的用户，关注同步时保持停用，需手动重新启用
+ALTER TABLE followed_users ADD COLUMN IF NOT EXISTS manually_disabled BOOLEAN DEFAULT FALSE;
@@ -0,0 +1,2 @@
+-- 推广活动的推文分类：检测推文时使用活动的分类，并只选择该分类的活动文案
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS category VARCHAR(64) DEFAULT 'hackathon';
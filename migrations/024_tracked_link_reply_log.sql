@@ -0,0 +1,11 @@
+-- 短链接改为发送时生成并关联回复日志，同一推文的多条回复（重试、重新生成）分别统计点击
+ALTER TABLE tracked_links ADD COLUMN IF NOT EXISTS reply_log_id INT;
+CREATE INDEX IF NOT EXISTS idx_tracked_links_reply_log_id ON tracked_links(reply_log_id);
+
+UPDATE tracked_links tl
+SET reply_log_id = (SELECT MAX(rl.id) FROM reply_logs rl WHERE rl.tweet_id = tl.tweet_id)
+WHERE tl.reply_log_id IS NULL;
+
+-- 点击去重：同一访客（IP 和 User-Agent 的哈希）在窗口内重复点击只计一次
+ALTER TABLE link_clicks ADD COLUMN IF NOT EXISTS visitor_hash VARCHAR(64);
+CREATE INDEX IF NOT EXISTS idx_link_clicks_visitor ON link_clicks(tracked_link_id, visitor_hash, clicked_at);
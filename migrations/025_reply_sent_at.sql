@@ -0,0 +1,8 @@
+-- 记录回复发送成功的时间，每日回复上限按发送时间而不是创建时间统计
+ALTER TABLE reply_logs ADD COLUMN IF NOT EXISTS sent_at TIMESTAMP WITH TIME ZONE;
+CREATE INDEX IF NOT EXISTS idx_reply_logs_sent_at ON reply_logs(sent_at);
+
+-- 历史成功回复没有发送时间，按审核时间或创建时间补齐
+UPDATE reply_logs
+SET sent_at = COALESCE(reviewed_at, created_at)
+WHERE status = 'success' AND sent_at IS NULL;
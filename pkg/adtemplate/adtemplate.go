@@ -0,0 +1,259 @@
+package adtemplate
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+	tparse "text/template/parse"
+)
+
+// Data 渲染广告文案时可用的变量，如 {{.Author.Username}}、{{.Event.Name}}、{{.Deadline}}、{{.TrackingLink}}、{{.Emoji}}
+type Data struct {
+	Author       Author
+	Tweet        Tweet
+	Event        Event
+	Deadline     string // 报名或提交截止时间（推文中的原文表述）
+	TrackingLink string // 文案的推广链接
+	Emoji        string // 从文案的 emoji 集合中随机选择的一个
+	Hashtag      string // 从文案的话题标签集合中随机选择的一个
+}
+
+// Author 推文作者
+type Author struct {
+	ID          string
+	Username    string
+	DisplayName string
+}
+
+// Tweet 被回复的推文
+type Tweet struct {
+	ID   string
+	Text string
+	Lang string
+	URL  string
+}
+
+// Event 推文中提到的黑客松活动
+type Event struct {
+	Name string
+	URL  string
+}
+
+// funcs 模板可用的函数，只提供无副作用的字符串处理
+var funcs = template.FuncMap{
+	// default 值为空时使用默认值，如 {{default "this hackathon" .Event.Name}}
+	"default": func(def string, value string) string {
+		if strings.TrimSpace(value) == "" {
+			return def
+		}
+		return value
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"trim":  strings.TrimSpace,
+	// truncate 按字符截断，超出时以省略号结尾
+	"truncate": func(n int, value string) string {
+		runes := []rune(value)
+		if n <= 0 || len(runes) <= n {
+			return value
+		}
+		if n == 1 {
+			return "…"
+		}
+		return string(runes[:n-1]) + "…"
+	},
+}
+
+// IsTemplate 判断内容是否包含模板语法，不包含时按原文发送
+func IsTemplate(content string) bool {
+	return strings.Contains(content, "{{")
+}
+
+// Render 使用变量渲染文案，不包含模板语法的内容原样返回
+func Render(content string, data Data) (string, error) {
+	if !IsTemplate(content) {
+		return content, nil
+	}
+
+	tmpl, err := parse(content)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("渲染文案模板失败: %w", err)
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
+
+// Validate 校验文案模板：语法正确且只引用已有的变量和函数
+// 遍历语法树检查所有分支中引用的变量（示例数据渲染不会执行到的分支也检查），再用示例数据渲染一次
+func Validate(content string) error {
+	if !IsTemplate(content) {
+		return nil
+	}
+
+	tmpl, err := parse(content)
+	if err != nil {
+		return err
+	}
+	for _, t := range tmpl.Templates() {
+		if t.Tree == nil || t.Tree.Root == nil {
+			continue
+		}
+		if err := checkNode(t.Tree.Root, dataType); err != nil {
+			return fmt.Errorf("文案模板引用了不存在的变量: %w", err)
+		}
+	}
+
+	_, err = Render(content, Sample())
+	return err
+}
+
+var dataType = reflect.TypeOf(Data{})
+
+// checkNode 检查节点中引用的字段在 dot 的类型上存在，dot 为 nil 表示类型未知（如 range 内部），不检查
+func checkNode(node tparse.Node, dot reflect.Type) error {
+	switch n := node.(type) {
+	case *tparse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := checkNode(child, dot); err != nil {
+				return err
+			}
+		}
+	case *tparse.ActionNode:
+		return checkPipe(n.Pipe, dot)
+	case *tparse.TemplateNode:
+		return checkPipe(n.Pipe, dot)
+	case *tparse.IfNode:
+		return checkBranch(&n.BranchNode, dot, dot)
+	case *tparse.WithNode:
+		return checkBranch(&n.BranchNode, dot, pipeType(n.Pipe, dot))
+	case *tparse.RangeNode:
+		return checkBranch(&n.BranchNode, dot, nil)
+	}
+	return nil
+}
+
+// checkBranch 检查条件和 else 分支（使用外层 dot）以及主体（使用 inner）
+func checkBranch(n *tparse.BranchNode, dot, inner reflect.Type) error {
+	if err := checkPipe(n.Pipe, dot); err != nil {
+		return err
+	}
+	if err := checkNode(n.List, inner); err != nil {
+		return err
+	}
+	if n.ElseList != nil {
+		return checkNode(n.ElseList, dot)
+	}
+	return nil
+}
+
+func checkPipe(pipe *tparse.PipeNode, dot reflect.Type) error {
+	if pipe == nil {
+		return nil
+	}
+	for _, cmd := range pipe.Cmds {
+		for _, arg := range cmd.Args {
+			if err := checkArg(arg, dot); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func checkArg(arg tparse.Node, dot reflect.Type) error {
+	switch n := arg.(type) {
+	case *tparse.FieldNode:
+		_, err := fieldType(dot, n.Ident)
+		return err
+	case *tparse.VariableNode:
+		// $ 始终指向根数据，其他变量的类型未知
+		if n.Ident[0] == "$" {
+			_, err := fieldType(dataType, n.Ident[1:])
+			return err
+		}
+	case *tparse.ChainNode:
+		return checkArg(n.Node, dot)
+	case *tparse.PipeNode:
+		return checkPipe(n, dot)
+	}
+	return nil
+}
+
+// pipeType with 的管道只是一个字段引用时返回该字段的类型，否则返回 nil
+func pipeType(pipe *tparse.PipeNode, dot reflect.Type) reflect.Type {
+	if pipe == nil || len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) != 1 {
+		return nil
+	}
+	switch n := pipe.Cmds[0].Args[0].(type) {
+	case *tparse.FieldNode:
+		t, _ := fieldType(dot, n.Ident)
+		return t
+	case *tparse.VariableNode:
+		if n.Ident[0] == "$" {
+			t, _ := fieldType(dataType, n.Ident[1:])
+			return t
+		}
+	case *tparse.DotNode:
+		return dot
+	}
+	return nil
+}
+
+// fieldType 按字段路径逐级查找类型，t 为 nil 时不检查
+func fieldType(t reflect.Type, idents []string) (reflect.Type, error) {
+	if t == nil {
+		return nil, nil
+	}
+	for i, ident := range idents {
+		if t.Kind() != reflect.Struct {
+			return nil, fmt.Errorf(".%s", strings.Join(idents[:i+1], "."))
+		}
+		f, ok := t.FieldByName(ident)
+		if !ok {
+			return nil, fmt.Errorf(".%s", strings.Join(idents[:i+1], "."))
+		}
+		t = f.Type
+	}
+	return t, nil
+}
+
+// Sample 预览和校验使用的示例数据
+func Sample() Data {
+	return Data{
+		Author: Author{
+			ID:          "1234567890",
+			Username:    "alice_dev",
+			DisplayName: "Alice",
+		},
+		Tweet: Tweet{
+			ID:   "1800000000000000000",
+			Text: "Excited to join ETHGlobal Hackathon this weekend! Submissions close Sunday 23:59 UTC 🚀",
+			Lang: "en",
+			URL:  "https://x.com/alice_dev/status/1800000000000000000",
+		},
+		Event: Event{
+			Name: "ETHGlobal Hackathon",
+			URL:  "https://ethglobal.com",
+		},
+		Deadline:     "Sunday 23:59 UTC",
+		TrackingLink: "https://example.com",
+		Emoji:        "🚀",
+		Hashtag:      "#hackathon",
+	}
+}
+
+func parse(content string) (*template.Template, error) {
+	tmpl, err := template.New("ad_copy").Funcs(funcs).Option("missingkey=error").Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("文案模板语法错误: %w", err)
+	}
+	return tmpl, nil
+}
@@ -0,0 +1,78 @@
+package adtemplate
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"plain text", "Good luck with the hackathon!", ""},
+		{"fields", "Hi @{{.Author.Username}}, good luck at {{.Event.Name}} {{.Emoji}} {{.TrackingLink}}", ""},
+		{"functions", `{{default "this hackathon" .Event.Name | upper}} {{truncate 10 .Tweet.Text | trim | lower}}`, ""},
+		{"root variable", "{{with .Event}}{{.Name}} by {{$.Author.DisplayName}}{{end}}", ""},
+		{"unknown field", "Hi {{.Author.Handle}}", "不存在的变量"},
+		{"unknown top-level field", "{{.Link}}", "不存在的变量"},
+		{"field on a string", "{{.Deadline.Date}}", "不存在的变量"},
+		{"unknown field in unused branch", "{{if .Event.Name}}ok{{else}}{{.Event.Date}}{{end}}", "不存在的变量"},
+		{"unknown field inside with", "{{with .Author}}{{.Email}}{{end}}", "不存在的变量"},
+		{"unknown root variable", "{{with .Author}}{{$.Missing}}{{end}}", "不存在的变量"},
+		{"unknown function", "{{shout .Author.Username}}", "语法错误"},
+		{"unclosed action", "Hi {{.Author.Username", "语法错误"},
+		{"missing end", "{{if .Event.Name}}go", "语法错误"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.content)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate(%q) error = %v, want nil", tt.content, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate(%q) error = %v, want containing %q", tt.content, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	data := Sample()
+	data.Event.Name = ""
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"plain text kept as is", "  Good luck! {not a template}  ", "  Good luck! {not a template}  "},
+		{"fields", "Hi @{{.Author.Username}} {{.Emoji}} {{.TrackingLink}}", "Hi @alice_dev 🚀 https://example.com"},
+		{"default for empty value", `Good luck at {{default "this hackathon" .Event.Name}}`, "Good luck at this hackathon"},
+		{"default keeps value", `{{default "friend" .Author.DisplayName}}`, "Alice"},
+		{"upper and lower", "{{upper .Author.DisplayName}} {{lower .Hashtag}}", "ALICE #hackathon"},
+		{"truncate", "{{truncate 8 .Tweet.Text}}", "Excited…"},
+		{"truncate shorter value", "{{truncate 20 .Author.Username}}", "alice_dev"},
+		{"if on empty field", "{{if .Event.Name}}{{.Event.Name}}{{else}}your hackathon{{end}}", "your hackathon"},
+		{"result trimmed", "  {{.Deadline}}  ", "Sunday 23:59 UTC"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Render(tt.content, data)
+			if err != nil {
+				t.Fatalf("Render(%q) error = %v", tt.content, err)
+			}
+			if got != tt.want {
+				t.Errorf("Render(%q) = %q, want %q", tt.content, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := Render("{{.Author.Handle}}", data); err == nil {
+		t.Error("Render with an unknown field error = nil, want error")
+	}
+}
@@ -44,3 +44,7 @@ func Is(err, target error) bool {
 	return errors.Is(err, target)
 }
 
+func As(err error, target interface{}) bool {
+	return errors.As(err, target)
+}
+
@@ -0,0 +1,88 @@
+package langdetect
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Undetermined 无法判断语言时返回的值（与 Twitter 的 lang 字段保持一致）
+const Undetermined = "und"
+
+// undeterminedCodes Twitter 返回的非具体语言代码
+// und: 未识别, qme: 仅媒体, qam: 仅提及, qct: 仅标签, qht: 仅话题, qst: 极短文本, zxx: 无语言内容
+var undeterminedCodes = map[string]bool{
+	"":    true,
+	"und": true,
+	"qme": true,
+	"qam": true,
+	"qct": true,
+	"qht": true,
+	"qst": true,
+	"zxx": true,
+}
+
+// IsUndetermined 判断语言代码是否不代表具体语言
+func IsUndetermined(lang string) bool {
+	return undeterminedCodes[strings.ToLower(lang)]
+}
+
+// Normalize 规范化语言代码，如 "zh-cn"、"zh-TW" 统一为 "zh"
+func Normalize(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if i := strings.IndexAny(lang, "-_"); i > 0 {
+		lang = lang[:i]
+	}
+	if IsUndetermined(lang) {
+		return Undetermined
+	}
+	return lang
+}
+
+// Detect 基于字符集的本地语言检测
+// 仅区分中文、日文、韩文和英文（拉丁字母），忽略链接、@提及和 #标签
+func Detect(text string) string {
+	var han, kana, hangul, latin int
+
+	for _, word := range strings.Fields(text) {
+		if strings.HasPrefix(word, "http://") || strings.HasPrefix(word, "https://") ||
+			strings.HasPrefix(word, "@") || strings.HasPrefix(word, "#") {
+			continue
+		}
+		for _, r := range word {
+			switch {
+			case unicode.Is(unicode.Han, r):
+				han++
+			case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+				kana++
+			case unicode.Is(unicode.Hangul, r):
+				hangul++
+			case r < unicode.MaxASCII && unicode.IsLetter(r):
+				latin++
+			}
+		}
+	}
+
+	// 日文中同时包含汉字与假名，出现假名即判定为日文
+	if kana > 0 && kana+han >= hangul {
+		return "ja"
+	}
+	if hangul > 0 && hangul >= han {
+		return "ko"
+	}
+	// 一个汉字的信息量约等于一个英文单词，按 1:4 与拉丁字母比较
+	if han > 0 && han*4 >= latin {
+		return "zh"
+	}
+	if latin > 0 {
+		return "en"
+	}
+	return Undetermined
+}
+
+// Resolve 优先使用 Twitter 返回的语言，无法判断时回退到本地检测
+func Resolve(twitterLang, text string) string {
+	if lang := Normalize(twitterLang); lang != Undetermined {
+		return lang
+	}
+	return Detect(text)
+}
@@ -0,0 +1,71 @@
+package langdetect
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"英文", "Join our hackathon this weekend", "en"},
+		{"中文", "本周末一起参加黑客松吧", "zh"},
+		{"日文", "ハッカソンに参加しましょう", "ja"},
+		{"日文汉字与假名混合", "週末のハッカソンに参加", "ja"},
+		{"韩文", "해커톤에 참가하세요", "ko"},
+		{"中文夹杂英文单词", "今天参加 hackathon 比赛", "zh"},
+		{"英文夹杂少量汉字", "We are hosting a global hackathon in 北京", "en"},
+		{"单个英文单词", "hi", "en"},
+		{"单个汉字", "好", "zh"},
+		{"空文本", "", Undetermined},
+		{"仅表情", "🚀🔥👍", Undetermined},
+		{"仅表情和标点", "🎉!!! ...", Undetermined},
+		{"忽略链接提及和标签", "@alice #hackathon https://example.com 报名", "zh"},
+		{"仅链接提及和标签", "@alice #hackathon https://example.com", Undetermined},
+		{"表情与中文混合", "🚀 冲冲冲", "zh"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Detect(tt.text); got != tt.want {
+				t.Errorf("Detect(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		lang string
+		want string
+	}{
+		{"zh-CN", "zh"},
+		{"zh_tw", "zh"},
+		{" EN ", "en"},
+		{"", Undetermined},
+		{"qme", Undetermined},
+		{"ZXX", Undetermined},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.lang); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.lang, got, tt.want)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		twitterLang string
+		text        string
+		want        string
+	}{
+		{"ja", "hello world", "ja"},
+		{"und", "本周末一起参加黑客松", "zh"},
+		{"qst", "hi", "en"},
+		{"", "🚀", Undetermined},
+	}
+	for _, tt := range tests {
+		if got := Resolve(tt.twitterLang, tt.text); got != tt.want {
+			t.Errorf("Resolve(%q, %q) = %q, want %q", tt.twitterLang, tt.text, got, tt.want)
+		}
+	}
+}
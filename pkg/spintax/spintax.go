@@ -0,0 +1,241 @@
+// Package spintax 解析文案中的 spintax 语法，如 {Hi|Hey|Hello}，每次随机选择一个候选项生成不同的文本。
+//
+// 候选项可以嵌套（{Good luck|{Best|All the best} wishes}），可以为空（{!|}）；
+// 不含 | 的 {...} 按原文保留；Go 模板动作 {{...}} 原样保留，由模板渲染处理，
+// 也可以作为候选项（{{{.Author}}|friend}）；
+// \{、\}、\| 表示字面字符。
+package spintax
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+)
+
+// maxCount 组合数的上限，超过时按该值计
+const maxCount = 1 << 20
+
+// Spintax 解析后的 spintax 文本
+type Spintax struct {
+	nodes []node
+}
+
+// node 字面文本或一组候选项
+type node struct {
+	text    string
+	choices [][]node
+}
+
+// Parse 解析 spintax 文本，花括号不匹配时返回错误
+func Parse(text string) (*Spintax, error) {
+	p := &parser{s: text}
+	nodes, err := p.sequence(false)
+	if err != nil {
+		return nil, err
+	}
+	return &Spintax{nodes: nodes}, nil
+}
+
+// Validate 校验 spintax 语法
+func Validate(text string) error {
+	_, err := Parse(text)
+	return err
+}
+
+// Spin 随机生成一个变体；语法错误时原样返回
+func Spin(text string) string {
+	s, err := Parse(text)
+	if err != nil {
+		return text
+	}
+	return s.Spin()
+}
+
+// Spin 随机生成一个变体
+func (s *Spintax) Spin() string {
+	var sb strings.Builder
+	spin(&sb, s.nodes)
+	return sb.String()
+}
+
+// Count 可能生成的变体数量（不去重），1 表示没有候选项
+func (s *Spintax) Count() int {
+	return count(s.nodes)
+}
+
+// Longest 每组候选项都选择 length 最大的一项，得到最长的变体，用于校验长度
+func (s *Spintax) Longest(length func(string) int) string {
+	return longest(s.nodes, length)
+}
+
+// Cover 确定性地生成一组变体，每个候选项（包括嵌套的候选项）至少出现在其中一个变体中，用于校验全部候选项
+func (s *Spintax) Cover() []string {
+	n := width(s.nodes)
+	variants := make([]string, n)
+	for k := range n {
+		var sb strings.Builder
+		pick(&sb, s.nodes, k)
+		variants[k] = sb.String()
+	}
+	return variants
+}
+
+func spin(sb *strings.Builder, nodes []node) {
+	for _, n := range nodes {
+		if n.choices == nil {
+			sb.WriteString(n.text)
+			continue
+		}
+		spin(sb, n.choices[rand.IntN(len(n.choices))])
+	}
+}
+
+func count(nodes []node) int {
+	total := 1
+	for _, n := range nodes {
+		if n.choices == nil {
+			continue
+		}
+		sum := 0
+		for _, choice := range n.choices {
+			sum = min(sum+count(choice), maxCount)
+		}
+		total = min(total*sum, maxCount)
+	}
+	return total
+}
+
+// width 覆盖全部候选项所需的变体数：一组候选项为各候选项所需数之和，序列为其中最大的一组
+func width(nodes []node) int {
+	total := 1
+	for _, n := range nodes {
+		if n.choices == nil {
+			continue
+		}
+		sum := 0
+		for _, choice := range n.choices {
+			sum += width(choice)
+		}
+		total = max(total, sum)
+	}
+	return total
+}
+
+// pick 生成第 k 个覆盖变体：每组候选项按 k 对该组所需变体数取模，依次对应各候选项及其嵌套的候选项
+func pick(sb *strings.Builder, nodes []node, k int) {
+	for _, n := range nodes {
+		if n.choices == nil {
+			sb.WriteString(n.text)
+			continue
+		}
+		i := k % width([]node{n})
+		for _, choice := range n.choices {
+			if w := width(choice); i >= w {
+				i -= w
+				continue
+			}
+			pick(sb, choice, i)
+			break
+		}
+	}
+}
+
+func longest(nodes []node, length func(string) int) string {
+	var sb strings.Builder
+	for _, n := range nodes {
+		if n.choices == nil {
+			sb.WriteString(n.text)
+			continue
+		}
+		best, bestLen := "", -1
+		for _, choice := range n.choices {
+			text := longest(choice, length)
+			if l := length(text); l > bestLen {
+				best, bestLen = text, l
+			}
+		}
+		sb.WriteString(best)
+	}
+	return sb.String()
+}
+
+type parser struct {
+	s   string
+	pos int
+}
+
+// sequence 解析到文本结束，或在候选项中解析到 | 或 } 为止（不消耗该字符）
+func (p *parser) sequence(inGroup bool) ([]node, error) {
+	var nodes []node
+	var sb strings.Builder
+	flush := func() {
+		if sb.Len() > 0 {
+			nodes = append(nodes, node{text: sb.String()})
+			sb.Reset()
+		}
+	}
+
+	for p.pos < len(p.s) {
+		rest := p.s[p.pos:]
+		c := rest[0]
+		switch {
+		case strings.HasPrefix(rest, "{{") && !strings.HasPrefix(rest, "{{{"):
+			// 模板动作原样保留，缺少 }} 时由模板校验报错；{{{ 为候选项组内以模板动作开头，按 { 处理
+			end := strings.Index(rest, "}}")
+			if end < 0 {
+				end = len(rest) - 2
+			}
+			sb.WriteString(rest[:end+2])
+			p.pos += end + 2
+		case c == '\\' && len(rest) > 1 && strings.IndexByte(`{}|\`, rest[1]) >= 0:
+			sb.WriteByte(rest[1])
+			p.pos += 2
+		case c == '{':
+			flush()
+			group, err := p.group()
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, group...)
+		case inGroup && (c == '|' || c == '}'):
+			flush()
+			return nodes, nil
+		case c == '}':
+			return nil, fmt.Errorf("spintax 语法错误: 第 %d 个字节处多余的 }", p.pos+1)
+		default:
+			sb.WriteByte(c)
+			p.pos++
+		}
+	}
+	flush()
+	return nodes, nil
+}
+
+// group 解析 { 开始的一组候选项；只有一个候选项时按原文保留花括号
+func (p *parser) group() ([]node, error) {
+	start := p.pos
+	p.pos++
+
+	var choices [][]node
+	for {
+		choice, err := p.sequence(true)
+		if err != nil {
+			return nil, err
+		}
+		choices = append(choices, choice)
+		if p.pos >= len(p.s) {
+			return nil, fmt.Errorf("spintax 语法错误: 第 %d 个字节处的 { 没有闭合", start+1)
+		}
+		c := p.s[p.pos]
+		p.pos++
+		if c == '}' {
+			break
+		}
+	}
+
+	if len(choices) == 1 {
+		nodes := append([]node{{text: "{"}}, choices[0]...)
+		return append(nodes, node{text: "}"}), nil
+	}
+	return []node{{choices: choices}}, nil
+}
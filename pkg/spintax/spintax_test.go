@@ -0,0 +1,167 @@
+package spintax
+
+import (
+	"slices"
+	"testing"
+	"unicode/utf8"
+)
+
+// spinAll 多次展开，返回出现过的全部变体
+func spinAll(t *testing.T, text string, n int) []string {
+	t.Helper()
+	s, err := Parse(text)
+	if err != nil {
+		t.Fatalf("Parse(%q) error = %v", text, err)
+	}
+	seen := make(map[string]bool)
+	for i := 0; i < n; i++ {
+		seen[s.Spin()] = true
+	}
+	variants := make([]string, 0, len(seen))
+	for v := range seen {
+		variants = append(variants, v)
+	}
+	slices.Sort(variants)
+	return variants
+}
+
+func TestSpin(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		want  []string
+		count int
+	}{
+		{"plain", "Hello world", []string{"Hello world"}, 1},
+		{"group", "{Hi|Hey} there", []string{"Hey there", "Hi there"}, 2},
+		{"empty choice", "Go{!|}", []string{"Go", "Go!"}, 2},
+		{"nested", "{Good luck|{Best|All the best} wishes}", []string{"All the best wishes", "Best wishes", "Good luck"}, 3},
+		{"deeply nested", "{a|{b|{c|d}}}", []string{"a", "b", "c", "d"}, 4},
+		{"sequence", "{a|b}{1|2}", []string{"a1", "a2", "b1", "b2"}, 4},
+		{"single choice kept", "{literal} text", []string{"{literal} text"}, 1},
+		{"escaped braces", `\{a|b\}`, []string{"{a|b}"}, 1},
+		{"escaped pipe in group", `{a\|b|c}`, []string{"a|b", "c"}, 2},
+		{"escaped backslash", `a\\b`, []string{`a\b`}, 1},
+		{"template kept", "{{.Author}} {hi|hey}", []string{"{{.Author}} hey", "{{.Author}} hi"}, 2},
+		{"template inside group", "{{{.Author}}|friend}", []string{"friend", "{{.Author}}"}, 2},
+		{"template with pipe", `{{if .Event}}{{.Event | printf "%s"}}{{end}}`, []string{`{{if .Event}}{{.Event | printf "%s"}}{{end}}`}, 1},
+		{"cjk", "{你好|こんにちは}🚀", []string{"こんにちは🚀", "你好🚀"}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := spinAll(t, tt.text, 200); !slices.Equal(got, tt.want) {
+				t.Errorf("Spin(%q) variants = %q, want %q", tt.text, got, tt.want)
+			}
+			s, _ := Parse(tt.text)
+			if got := s.Count(); got != tt.count {
+				t.Errorf("Count(%q) = %d, want %d", tt.text, got, tt.count)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, text := range []string{
+		"{a|b",
+		"a|b}",
+		"{a|{b|c}",
+		"}",
+		"{a|b}}",
+	} {
+		if err := Validate(text); err == nil {
+			t.Errorf("Validate(%q) error = nil, want unbalanced braces", text)
+		}
+		// 语法错误时 Spin 原样返回
+		if got := Spin(text); got != text {
+			t.Errorf("Spin(%q) = %q, want the text unchanged", text, got)
+		}
+	}
+
+	// 未闭合的模板动作交给模板校验
+	if err := Validate("{{.Author"); err != nil {
+		t.Errorf("Validate of an unclosed template action error = %v, want nil", err)
+	}
+}
+
+func TestLongest(t *testing.T) {
+	s, err := Parse("{Hi|Hello} {there|everyone}{!|}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Longest(utf8.RuneCountInString); got != "Hello everyone!" {
+		t.Errorf("Longest() = %q, want %q", got, "Hello everyone!")
+	}
+
+	// 按传入的长度函数比较，CJK 字符权重更高时选择 CJK 候选项
+	s, err = Parse("{abc|中文}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	weighted := func(text string) int {
+		n := 0
+		for _, r := range text {
+			if r > 0x7f {
+				n += 2
+			} else {
+				n++
+			}
+		}
+		return n
+	}
+	if got := s.Longest(weighted); got != "中文" {
+		t.Errorf("Longest(weighted) = %q, want %q", got, "中文")
+	}
+}
+
+func TestCover(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"plain", "Hello world", []string{"Hello world"}},
+		{"group", "{Hi|Hey} there", []string{"Hi there", "Hey there"}},
+		{"sequence uses the widest group", "{a|b}{1|2|3}", []string{"a1", "b2", "a3"}},
+		{"nested", "{a|{b|c}}", []string{"a", "b", "c"}},
+		{"nested in sequence", "{x|y}{a|{b|c}}", []string{"xa", "yb", "xc"}},
+		{"template inside group", "{{{.Author}}|friend}!", []string{"{{.Author}}!", "friend!"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := s.Cover(); !slices.Equal(got, tt.want) {
+				t.Errorf("Cover(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+
+	// 组合数很大时覆盖变体数只与候选项数量有关
+	text := ""
+	for i := 0; i < 30; i++ {
+		text += "{a|b|c}"
+	}
+	s, err := Parse(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(s.Cover()); got != 3 {
+		t.Errorf("len(Cover()) = %d, want 3", got)
+	}
+}
+
+func TestCountCapped(t *testing.T) {
+	text := ""
+	for i := 0; i < 30; i++ {
+		text += "{a|b}"
+	}
+	s, err := Parse(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Count(); got != maxCount {
+		t.Errorf("Count() = %d, want capped at %d", got, maxCount)
+	}
+}
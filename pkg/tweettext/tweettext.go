@@ -0,0 +1,237 @@
+package tweettext
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// 与 twitter-text v3 配置保持一致
+const (
+	// MaxWeightedLength 单条推文允许的最大加权长度
+	MaxWeightedLength = 280
+	// URLLength 链接经 t.co 转换后固定计入的长度
+	URLLength = 23
+	// defaultWeight 未落在 lightRanges 内的字符（中日韩文字、emoji 等）计 2
+	defaultWeight = 2
+)
+
+// ErrTooLong 推文超出最大加权长度
+var ErrTooLong = errors.New("推文超出长度限制")
+
+// lightRanges 计 1 的码点区间：拉丁、西里尔、希腊等常用文字以及常用标点
+var lightRanges = [][2]rune{
+	{0, 4351},
+	{8192, 8205},
+	{8208, 8223},
+	{8242, 8247},
+}
+
+// urlPattern 匹配带协议的链接以及常见顶级域名的裸域名链接（如 example.com/path）
+var urlPattern = regexp.MustCompile(`(?i)https?://[^\s\x{3000}-\x{9FFF}\x{AC00}-\x{D7AF}\x{FF00}-\x{FFEF}]+|` +
+	`\b(?:[a-z0-9](?:[a-z0-9-]*[a-z0-9])?\.)+` +
+	`(?:com|org|net|io|dev|ai|co|app|xyz|me|gg|so|tech|info|biz|edu|gov|cn|jp|kr|uk|de|fr|us|ly|sh|to|tv|fm|link|site|online)` +
+	`\b(?::\d+)?(?:/[^\s\x{3000}-\x{9FFF}\x{AC00}-\x{D7AF}\x{FF00}-\x{FFEF}]*)?`)
+
+// Result 加权长度计算结果
+type Result struct {
+	WeightedLength int  `json:"weighted_length"`
+	Remaining      int  `json:"remaining"` // 距离上限的剩余长度，超出时为负数
+	Valid          bool `json:"valid"`     // 是否未超出上限
+}
+
+// Parse 按 twitter-text 规则计算文本的加权长度：
+// 文本先做 NFC 规范化；链接固定计 23；emoji（含肤色、ZWJ 组合、国旗、键帽序列）整体计 2；
+// lightRanges 内的字符计 1，其余字符计 2
+func Parse(text string) Result {
+	text = norm.NFC.String(text)
+
+	length := 0
+	last := 0
+	for _, loc := range FindURLs(text) {
+		length += textLength(text[last:loc[0]]) + URLLength
+		last = loc[1]
+	}
+	length += textLength(text[last:])
+
+	return Result{
+		WeightedLength: length,
+		Remaining:      MaxWeightedLength - length,
+		Valid:          length <= MaxWeightedLength,
+	}
+}
+
+// WeightedLength 文本的加权长度
+func WeightedLength(text string) int {
+	return Parse(text).WeightedLength
+}
+
+// Validate 校验文本未超出推文长度限制
+func Validate(text string) error {
+	result := Parse(text)
+	if !result.Valid {
+		return fmt.Errorf("%w: 加权长度 %d，上限 %d", ErrTooLong, result.WeightedLength, MaxWeightedLength)
+	}
+	return nil
+}
+
+// FindURLs 返回文本中链接的字节位置 [start, end)，识别规则与长度计算一致
+func FindURLs(text string) [][2]int {
+	var urls [][2]int
+	for _, loc := range urlPattern.FindAllStringIndex(text, -1) {
+		start, end := loc[0], trimURLEnd(text, loc[0], loc[1])
+		// 邮箱中的域名不算链接
+		if start > 0 && text[start-1] == '@' {
+			continue
+		}
+		urls = append(urls, [2]int{start, end})
+	}
+	return urls
+}
+
+// trimURLEnd 去掉链接末尾的标点，与 twitter-text 的链接识别保持一致
+func trimURLEnd(text string, start, end int) int {
+	for end > start && strings.IndexByte(".,;:!?'\")]}>", text[end-1]) >= 0 {
+		end--
+	}
+	return end
+}
+
+// textLength 计算不含链接的文本加权长度
+func textLength(text string) int {
+	runes := []rune(text)
+	length := 0
+	for i := 0; i < len(runes); {
+		if n := emojiSequence(runes[i:]); n > 0 {
+			length += defaultWeight
+			i += n
+			continue
+		}
+		length += weight(runes[i])
+		i++
+	}
+	return length
+}
+
+func weight(r rune) int {
+	for _, rg := range lightRanges {
+		if r >= rg[0] && r <= rg[1] {
+			return 1
+		}
+	}
+	return defaultWeight
+}
+
+const (
+	zwj               = '\u200d'
+	variationSelector = '\ufe0f'
+	keycap            = '\u20e3'
+)
+
+// emojiSequence 返回从开头开始的 emoji 序列包含的码点数，不是 emoji 时返回 0
+func emojiSequence(runes []rune) int {
+	if len(runes) == 0 {
+		return 0
+	}
+
+	// 键帽：数字、# 或 * + FE0F + 20E3
+	if isKeycapBase(runes[0]) {
+		if len(runes) >= 3 && runes[1] == variationSelector && runes[2] == keycap {
+			return 3
+		}
+		if len(runes) >= 2 && runes[1] == keycap {
+			return 2
+		}
+		return 0
+	}
+
+	// 国旗：两个区域指示符
+	if isRegionalIndicator(runes[0]) {
+		if len(runes) >= 2 && isRegionalIndicator(runes[1]) {
+			return 2
+		}
+		return 1
+	}
+
+	if !isPictographic(runes[0]) {
+		return 0
+	}
+	// ©、® 等计 1 的符号只有带 FE0F 时才按 emoji 显示
+	if weight(runes[0]) == 1 && (len(runes) < 2 || runes[1] != variationSelector) {
+		return 0
+	}
+
+	i := 1
+	for i < len(runes) {
+		switch {
+		case runes[i] == variationSelector, isSkinTone(runes[i]), isTag(runes[i]):
+			i++
+		case runes[i] == zwj && i+1 < len(runes) && isPictographic(runes[i+1]):
+			i += 2
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+func isKeycapBase(r rune) bool {
+	return r == '#' || r == '*' || (r >= '0' && r <= '9')
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
+
+func isSkinTone(r rune) bool {
+	return r >= 0x1F3FB && r <= 0x1F3FF
+}
+
+// isTag 旗帜子区域标签（如英格兰、苏格兰旗）
+func isTag(r rune) bool {
+	return r >= 0xE0020 && r <= 0xE007F
+}
+
+// isPictographic 常见 emoji 码点区间
+func isPictographic(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF,
+		r >= 0x2600 && r <= 0x27BF,
+		r >= 0x2300 && r <= 0x23FF,
+		r >= 0x2B00 && r <= 0x2BFF,
+		r == 0x00A9, r == 0x00AE, r == 0x203C, r == 0x2049, r == 0x2122, r == 0x2139,
+		r >= 0x2194 && r <= 0x21AA,
+		r == 0x3030, r == 0x303D, r == 0x3297, r == 0x3299:
+		return true
+	}
+	return false
+}
+
+// EditDistance 按字符（rune）计算两段文本的 Levenshtein 编辑距离
+func EditDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) < len(rb) {
+		ra, rb = rb, ra
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,149 @@
+package tweettext
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWeightedLength(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want int
+	}{
+		{"empty", "", 0},
+		{"ascii", "hello world", 11},
+		{"latin accents", "café naïve", 10},
+		{"decomposed accent normalized", "cafe\u0301", 4},
+		{"cjk", "黑客松", 6},
+		{"japanese", "ハッカソン", 10},
+		{"korean", "해커톤", 6},
+		{"fullwidth punctuation", "你好，世界！", 12},
+		{"general punctuation", "a—b", 3},
+		{"ellipsis outside light ranges", "…", 2},
+		{"emoji", "🚀", 2},
+		{"emoji with variation selector", "❤️", 2},
+		{"emoji with skin tone", "👍🏽", 2},
+		{"zwj family", "👨‍👩‍👧‍👦", 2},
+		{"zwj profession with skin tone", "👩🏾‍💻", 2},
+		{"flag", "🇯🇵", 2},
+		{"two flags", "🇯🇵🇨🇳", 4},
+		{"keycap", "1️⃣", 2},
+		{"tag sequence flag", "🏴\U000E0067\U000E0062\U000E0073\U000E0063\U000E0074\U000E007F", 2},
+		{"mixed", "Hi 黑客松 🚀", 3 + 6 + 1 + 2},
+		{"url", "https://example.com/a/very/long/path/that/exceeds/twenty/three", 23},
+		{"short url", "http://a.co", 23},
+		{"bare domain", "example.com", 23},
+		{"url in text", "join https://ethglobal.com now", 5 + 23 + 4},
+		{"url before cjk", "https://example.com报名", 23 + 4},
+		{"two urls", "a.io b.dev", 23 + 1 + 23},
+		{"email is not url", "me@example.com", 14},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WeightedLength(tt.text); got != tt.want {
+				t.Errorf("WeightedLength(%q) = %d, want %d", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		remaining int
+		valid     bool
+	}{
+		{"ascii at limit", strings.Repeat("a", 280), 0, true},
+		{"ascii over limit", strings.Repeat("a", 281), -1, false},
+		{"cjk at limit", strings.Repeat("中", 140), 0, true},
+		{"cjk over limit", strings.Repeat("中", 141), -2, false},
+		{"emoji at limit", strings.Repeat("🚀", 140), 0, true},
+		{"url counts 23", strings.Repeat("a", 257) + "https://example.com/" + strings.Repeat("x", 100), 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Parse(tt.text)
+			if result.Remaining != tt.remaining || result.Valid != tt.valid {
+				t.Errorf("Parse() = %+v, want remaining %d valid %v", result, tt.remaining, tt.valid)
+			}
+			err := Validate(tt.text)
+			if tt.valid && err != nil {
+				t.Errorf("Validate() error = %v", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrTooLong) {
+				t.Errorf("Validate() error = %v, want ErrTooLong", err)
+			}
+		})
+	}
+}
+
+func TestFindURLs(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"none", "no links here", nil},
+		{"scheme", "see https://example.com/path?q=1 now", []string{"https://example.com/path?q=1"}},
+		{"uppercase scheme", "HTTPS://Example.COM", []string{"HTTPS://Example.COM"}},
+		{"bare domain", "visit example.com today", []string{"example.com"}},
+		{"bare subdomain with path", "docs at docs.example.io/start.", []string{"docs.example.io/start"}},
+		{"bare domain with port", "localhost.dev:8080/api", []string{"localhost.dev:8080/api"}},
+		{"unknown tld", "file.txt and v1.2", nil},
+		{"trailing period", "Go to https://example.com.", []string{"https://example.com"}},
+		{"trailing comma", "https://a.io, https://b.io", []string{"https://a.io", "https://b.io"}},
+		{"trailing paren", "(https://example.com/x)", []string{"https://example.com/x"}},
+		{"trailing exclamation and question", "example.com!? yes", []string{"example.com"}},
+		{"trailing quote", `"https://example.com/a"`, []string{"https://example.com/a"}},
+		{"stops at cjk", "https://example.com报名链接", []string{"https://example.com"}},
+		{"stops at fullwidth punctuation", "链接：https://example.com，欢迎", []string{"https://example.com"}},
+		{"email skipped", "mail me@example.com or example.org", []string{"example.org"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, loc := range FindURLs(tt.text) {
+				got = append(got, tt.text[loc[0]:loc[1]])
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("FindURLs(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want int
+	}{
+		{"equal", "hello", "hello", 0},
+		{"empty", "", "abc", 3},
+		{"substitution", "kitten", "sitten", 1},
+		{"classic", "kitten", "sitting", 3},
+		{"insertion", "Hi there", "Hi there!", 1},
+		{"cjk substitution", "你好世界", "你好地球", 2},
+		{"cjk insertion", "黑客松", "黑客马拉松", 2},
+		{"cjk vs latin", "你好", "hi", 2},
+		{"emoji substitution", "Go 🚀", "Go 🎉", 1},
+		{"emoji insertion", "Good luck", "Good luck 🍀", 2},
+		{"emoji with variation selector", "❤", "❤️", 1},
+		{"zwj sequence", "👨‍👩‍👧", "👨‍👩‍👦", 1},
+		{"flag", "🇯🇵", "🇨🇳", 2},
+		{"mixed", "报名 🚀 now", "报名 🎉 now!", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EditDistance(tt.a, tt.b); got != tt.want {
+				t.Errorf("EditDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+			if got := EditDistance(tt.b, tt.a); got != tt.want {
+				t.Errorf("EditDistance(%q, %q) = %d, want %d", tt.b, tt.a, got, tt.want)
+			}
+		})
+	}
+}